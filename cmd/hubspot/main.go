@@ -56,6 +56,7 @@ func run() error {
 	}
 
 	s := store.New(db)
+	s.AllowUnknownProperties = cfg.LenientProperties
 
 	mux := http.NewServeMux()
 
@@ -71,7 +72,7 @@ func run() error {
 	lists.RegisterRoutes(mux, s)
 
 	// Admin API
-	admin.RegisterRoutes(mux, s.DB)
+	admin.RegisterRoutes(mux, s.DB, cfg.ArchiveRetention)
 
 	// Web UI
 	ui.RegisterRoutes(mux)
@@ -85,10 +86,17 @@ func run() error {
 		))
 	})
 
+	// RequestLog wraps Recovery so that requests which panic are logged with
+	// their 500 response.
 	handler := api.Chain(mux,
-		api.Recovery(),
 		api.RequestID(),
+		api.RequestLog(s.RequestLog, api.RequestLogConfig{
+			MaxBodyBytes: cfg.RequestLogMaxBody,
+			Exclude:      cfg.RequestLogExclude,
+		}),
+		api.Recovery(),
 		api.Auth(cfg.AuthToken),
+		api.ChangeSource(),
 		api.JSONContentType(),
 		api.Logging(),
 	)
@@ -0,0 +1,441 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/johnwards/hubspot/internal/api"
+	"github.com/johnwards/hubspot/internal/store"
+)
+
+// maxNearMisses caps the near misses reported for an unmet expectation.
+const maxNearMisses = 5
+
+// CreateExpectation registers an expectation to check with VerifyExpectations.
+func (h *Handler) CreateExpectation(w http.ResponseWriter, r *http.Request) {
+	corrID := api.CorrelationID(r.Context())
+
+	var exp store.Expectation
+	if err := json.NewDecoder(r.Body).Decode(&exp); err != nil {
+		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("Invalid input JSON", corrID, nil))
+		return
+	}
+
+	exp.Method = strings.ToUpper(exp.Method)
+	if exp.Path != "" && !strings.HasPrefix(exp.Path, "/") {
+		api.WriteError(w, http.StatusBadRequest, api.NewValidationError(
+			fmt.Sprintf("path %q must start with /", exp.Path), corrID, nil))
+		return
+	}
+	for _, p := range exp.Body {
+		if _, err := parseJSONPath(p.Path); err != nil {
+			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+			return
+		}
+	}
+	if err := normalizeTimes(&exp.Times); err != nil {
+		api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+		return
+	}
+
+	if err := h.expectations.Create(r.Context(), &exp); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{
+			Status:        "error",
+			Message:       fmt.Sprintf("create expectation: %s", err),
+			CorrelationID: corrID,
+			Category:      "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	api.WriteJSON(w, http.StatusCreated, exp)
+}
+
+// ListExpectations returns all registered expectations.
+func (h *Handler) ListExpectations(w http.ResponseWriter, r *http.Request) {
+	exps, err := h.expectations.List(r.Context())
+	if err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{
+			Status:        "error",
+			Message:       fmt.Sprintf("list expectations: %s", err),
+			CorrelationID: api.CorrelationID(r.Context()),
+			Category:      "INTERNAL_ERROR",
+		})
+		return
+	}
+	if exps == nil {
+		exps = []*store.Expectation{}
+	}
+	api.WriteJSON(w, http.StatusOK, map[string]any{"results": exps})
+}
+
+// DeleteExpectations removes all registered expectations.
+func (h *Handler) DeleteExpectations(w http.ResponseWriter, r *http.Request) {
+	if err := h.expectations.DeleteAll(r.Context()); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{
+			Status:        "error",
+			Message:       fmt.Sprintf("delete expectations: %s", err),
+			CorrelationID: api.CorrelationID(r.Context()),
+			Category:      "INTERNAL_ERROR",
+		})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type requestSummary struct {
+	ID            int64  `json:"id"`
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+	StatusCode    int    `json:"statusCode"`
+	CorrelationID string `json:"correlationId,omitempty"`
+	CreatedAt     string `json:"createdAt"`
+}
+
+type mismatch struct {
+	Field    string `json:"field"`
+	Expected any    `json:"expected"`
+	Actual   any    `json:"actual"`
+}
+
+type nearMiss struct {
+	Request    requestSummary `json:"request"`
+	Mismatches []mismatch     `json:"mismatches"`
+}
+
+type verifyDiff struct {
+	Expected   string           `json:"expected"`
+	Actual     string           `json:"actual"`
+	Matched    []requestSummary `json:"matched,omitempty"`
+	NearMisses []nearMiss       `json:"nearMisses,omitempty"`
+}
+
+type verifyResult struct {
+	Expectation *store.Expectation `json:"expectation"`
+	Met         bool               `json:"met"`
+	Count       int                `json:"count"`
+	Diff        *verifyDiff        `json:"diff,omitempty"`
+}
+
+// VerifyExpectations checks every registered expectation against the request
+// log recorded since the last reset.
+func (h *Handler) VerifyExpectations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	corrID := api.CorrelationID(ctx)
+
+	exps, err := h.expectations.List(ctx)
+	if err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{
+			Status:        "error",
+			Message:       fmt.Sprintf("list expectations: %s", err),
+			CorrelationID: corrID,
+			Category:      "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	checks := make([]*expectationCheck, len(exps))
+	for i, exp := range exps {
+		checks[i] = newExpectationCheck(exp)
+	}
+
+	// The log is read a page at a time, so memory stays bounded by the page
+	// size rather than the length of the log.
+	filter := store.RequestLogFilter{Limit: 1000}
+	for {
+		page, hasMore, err := h.requestLog.List(ctx, filter)
+		if err != nil {
+			api.WriteError(w, http.StatusInternalServerError, &api.Error{
+				Status:        "error",
+				Message:       fmt.Sprintf("query request log: %s", err),
+				CorrelationID: corrID,
+				Category:      "INTERNAL_ERROR",
+			})
+			return
+		}
+
+		bodies := make(map[int64]any, len(page))
+		for _, e := range page {
+			var doc any
+			if err := json.Unmarshal([]byte(e.RequestBody), &doc); err == nil {
+				bodies[e.ID] = doc
+			}
+		}
+		for _, c := range checks {
+			c.add(page, bodies)
+		}
+
+		if !hasMore {
+			break
+		}
+		filter.After = page[len(page)-1].ID
+	}
+
+	allMet := true
+	results := make([]verifyResult, 0, len(checks))
+	for _, c := range checks {
+		res := c.result()
+		allMet = allMet && res.Met
+		results = append(results, res)
+	}
+
+	api.WriteJSON(w, http.StatusOK, map[string]any{
+		"met":     allMet,
+		"results": results,
+	})
+}
+
+// expectationCheck accumulates the requests matching an expectation, and its
+// closest near misses, as the request log is read page by page.
+type expectationCheck struct {
+	exp      *store.Expectation
+	pathRe   *regexp.Regexp
+	criteria int
+	matched  []requestSummary
+	misses   []nearMiss
+}
+
+func newExpectationCheck(exp *store.Expectation) *expectationCheck {
+	criteria := len(exp.Body)
+	if exp.Method != "" {
+		criteria++
+	}
+	if exp.Path != "" {
+		criteria++
+	}
+	return &expectationCheck{exp: exp, pathRe: globToRegexp(exp.Path), criteria: criteria}
+}
+
+// add checks a page of entries against the expectation. Only the closest
+// near misses seen so far are kept.
+func (c *expectationCheck) add(entries []*store.RequestLogEntry, bodies map[int64]any) {
+	for _, e := range entries {
+		mm := entryMismatches(c.exp, c.pathRe, e, bodies)
+		if len(mm) == 0 {
+			c.matched = append(c.matched, summarize(e))
+		} else if len(mm) < c.criteria {
+			c.misses = append(c.misses, nearMiss{Request: summarize(e), Mismatches: mm})
+		}
+	}
+	// Fewest mismatches first, then oldest first.
+	sort.Slice(c.misses, func(i, j int) bool {
+		if len(c.misses[i].Mismatches) != len(c.misses[j].Mismatches) {
+			return len(c.misses[i].Mismatches) < len(c.misses[j].Mismatches)
+		}
+		return c.misses[i].Request.ID < c.misses[j].Request.ID
+	})
+	if len(c.misses) > maxNearMisses {
+		c.misses = c.misses[:maxNearMisses]
+	}
+}
+
+// result reports whether the expectation was met and, when the count is out
+// of bounds, explains why.
+func (c *expectationCheck) result() verifyResult {
+	// Oldest first, matching the order the client made the calls.
+	sort.Slice(c.matched, func(i, j int) bool { return c.matched[i].ID < c.matched[j].ID })
+
+	res := verifyResult{
+		Expectation: c.exp,
+		Count:       len(c.matched),
+		Met:         timesSatisfied(c.exp.Times, len(c.matched)),
+	}
+	if res.Met {
+		return res
+	}
+
+	res.Diff = &verifyDiff{
+		Expected: describeTimes(c.exp.Times),
+		Actual:   fmt.Sprintf("%d matching request(s)", len(c.matched)),
+	}
+	if minimum := minTimes(c.exp.Times); len(c.matched) < minimum {
+		res.Diff.NearMisses = c.misses
+	} else {
+		res.Diff.Matched = c.matched
+	}
+	return res
+}
+
+// entryMismatches lists every criterion of exp that e does not satisfy.
+func entryMismatches(exp *store.Expectation, pathRe *regexp.Regexp, e *store.RequestLogEntry, bodies map[int64]any) []mismatch {
+	var mm []mismatch
+	if exp.Method != "" && exp.Method != e.Method {
+		mm = append(mm, mismatch{Field: "method", Expected: exp.Method, Actual: e.Method})
+	}
+	if pathRe != nil && !pathRe.MatchString(e.Path) {
+		mm = append(mm, mismatch{Field: "path", Expected: exp.Path, Actual: e.Path})
+	}
+
+	doc, hasBody := bodies[e.ID]
+	for _, p := range exp.Body {
+		steps, _ := parseJSONPath(p.Path)
+		var values []any
+		if hasBody {
+			values = selectJSONPath(doc, steps)
+		}
+		if ok, expected := predicateHolds(p, values); !ok {
+			mm = append(mm, mismatch{Field: "body " + p.Path, Expected: expected, Actual: actualValue(values)})
+		}
+	}
+	return mm
+}
+
+// predicateHolds evaluates p against the selected values and returns a
+// description of what was expected for use in diffs.
+func predicateHolds(p store.BodyPredicate, values []any) (bool, any) {
+	if p.Exists != nil {
+		if (len(values) > 0) != *p.Exists {
+			if *p.Exists {
+				return false, "to exist"
+			}
+			return false, "to be absent"
+		}
+	}
+	if len(p.Equals) > 0 {
+		var want any
+		dec := json.NewDecoder(bytes.NewReader(p.Equals))
+		if err := dec.Decode(&want); err != nil {
+			return false, string(p.Equals)
+		}
+		found := false
+		for _, v := range values {
+			if reflect.DeepEqual(v, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, want
+		}
+	}
+	if p.Contains != "" {
+		found := false
+		for _, v := range values {
+			if s, ok := v.(string); ok && strings.Contains(s, p.Contains) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, fmt.Sprintf("to contain %q", p.Contains)
+		}
+	}
+	if p.Exists == nil && len(p.Equals) == 0 && p.Contains == "" && len(values) == 0 {
+		return false, "to exist"
+	}
+	return true, nil
+}
+
+// actualValue renders the selected values for a diff.
+func actualValue(values []any) any {
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	default:
+		return values
+	}
+}
+
+func summarize(e *store.RequestLogEntry) requestSummary {
+	return requestSummary{
+		ID:            e.ID,
+		Method:        e.Method,
+		Path:          e.Path,
+		StatusCode:    e.StatusCode,
+		CorrelationID: e.CorrelationID,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+// globToRegexp compiles a path pattern where * matches any run of characters
+// (including /) and ? matches one character. An empty pattern matches
+// everything and returns nil.
+func globToRegexp(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// normalizeTimes validates t and defaults it to "at least once".
+func normalizeTimes(t *store.ExpectationTimes) error {
+	set := 0
+	if t.Exactly != nil {
+		set++
+		if *t.Exactly < 0 {
+			return fmt.Errorf("times.exactly must not be negative")
+		}
+	}
+	if t.AtLeast != nil {
+		set++
+		if *t.AtLeast < 0 {
+			return fmt.Errorf("times.atLeast must not be negative")
+		}
+	}
+	if t.Never {
+		set++
+	}
+	if set > 1 {
+		return fmt.Errorf("times accepts only one of exactly, atLeast or never")
+	}
+	if set == 0 {
+		one := 1
+		t.AtLeast = &one
+	}
+	return nil
+}
+
+func timesSatisfied(t store.ExpectationTimes, n int) bool {
+	switch {
+	case t.Never:
+		return n == 0
+	case t.Exactly != nil:
+		return n == *t.Exactly
+	default:
+		return n >= minTimes(t)
+	}
+}
+
+// minTimes is the fewest matches that can satisfy t.
+func minTimes(t store.ExpectationTimes) int {
+	switch {
+	case t.Exactly != nil:
+		return *t.Exactly
+	case t.AtLeast != nil:
+		return *t.AtLeast
+	default:
+		return 0
+	}
+}
+
+func describeTimes(t store.ExpectationTimes) string {
+	switch {
+	case t.Never:
+		return "no matching requests"
+	case t.Exactly != nil:
+		return fmt.Sprintf("exactly %d matching request(s)", *t.Exactly)
+	default:
+		return fmt.Sprintf("at least %d matching request(s)", minTimes(t))
+	}
+}
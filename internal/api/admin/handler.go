@@ -3,18 +3,30 @@ package admin
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/johnwards/hubspot/internal/api"
 	"github.com/johnwards/hubspot/internal/seed"
+	"github.com/johnwards/hubspot/internal/store"
 )
 
 // Handler serves the admin API at /_notspot/.
 type Handler struct {
-	db *sql.DB
+	db           *sql.DB
+	requestLog   store.RequestLogStore
+	expectations store.ExpectationStore
+	objects      store.ObjectStore
+
+	// archiveRetention is how long archived objects are kept before Purge
+	// deletes them.
+	archiveRetention time.Duration
 }
 
 // dataTableNames lists all data tables in foreign-key-safe deletion order.
@@ -22,9 +34,12 @@ var dataTableNames = []string{
 	"list_memberships",
 	"associations",
 	"property_value_history",
+	"property_values_fts",
 	"property_values",
 	"import_errors",
 	"request_log",
+	"expectations",
+	"gdpr_deleted_emails",
 	"pipeline_stages",
 	"objects",
 	"imports",
@@ -85,49 +100,50 @@ func (h *Handler) SeedData(w http.ResponseWriter, r *http.Request) {
 	api.WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
-type requestLogEntry struct {
-	ID            int64  `json:"id"`
-	Method        string `json:"method"`
-	Path          string `json:"path"`
-	StatusCode    int    `json:"statusCode"`
-	RequestBody   string `json:"requestBody,omitempty"`
-	ResponseBody  string `json:"responseBody,omitempty"`
-	DurationMs    int64  `json:"durationMs"`
-	CorrelationID string `json:"correlationId,omitempty"`
-	CreatedAt     string `json:"createdAt"`
-}
+// Purge permanently deletes objects that have been archived for longer than
+// the retention period. The olderThan query parameter, a Go duration such as
+// "24h" or "0s", overrides the configured retention.
+func (h *Handler) Purge(w http.ResponseWriter, r *http.Request) {
+	corrID := api.CorrelationID(r.Context())
 
-// Requests returns request log entries, newest first, with cursor-based pagination.
-func (h *Handler) Requests(w http.ResponseWriter, r *http.Request) {
-	limit := 100
-	if v := r.URL.Query().Get("limit"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 1000 {
-			limit = n
+	retention := h.archiveRetention
+	if v := r.URL.Query().Get("olderThan"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(
+				fmt.Sprintf("invalid olderThan %q: must be a non-negative duration such as 720h", v), corrID, nil))
+			return
 		}
+		retention = d
 	}
 
-	var afterID int64
-	if v := r.URL.Query().Get("after"); v != "" {
-		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
-			afterID = n
-		}
+	purged, err := h.objects.PurgeArchived(r.Context(), time.Now().Add(-retention))
+	if err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{
+			Status:        "error",
+			Message:       fmt.Sprintf("purge archived objects: %s", err),
+			CorrelationID: corrID,
+			Category:      "INTERNAL_ERROR",
+		})
+		return
 	}
 
-	query := `SELECT id, method, path, status_code, COALESCE(request_body,''), COALESCE(response_body,''),
-			  COALESCE(duration_ms,0), COALESCE(correlation_id,''), created_at
-			  FROM request_log`
-	args := []any{}
+	api.WriteJSON(w, http.StatusOK, map[string]int{"purged": purged})
+}
+
+// Requests returns request log entries, newest first, with cursor-based
+// pagination. See parseRequestLogFilter for the supported filters.
+func (h *Handler) Requests(w http.ResponseWriter, r *http.Request) {
+	corrID := api.CorrelationID(r.Context())
 
-	if afterID > 0 {
-		query += " WHERE id < ?"
-		args = append(args, afterID)
+	filter, err := parseRequestLogFilter(r.URL.Query(), 100, 1000)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+		return
 	}
-	query += " ORDER BY id DESC LIMIT ?"
-	args = append(args, limit+1)
 
-	rows, err := h.db.QueryContext(r.Context(), query, args...)
+	entries, hasMore, err := h.requestLog.List(r.Context(), filter)
 	if err != nil {
-		corrID := api.CorrelationID(r.Context())
 		api.WriteError(w, http.StatusInternalServerError, &api.Error{
 			Status:        "error",
 			Message:       fmt.Sprintf("query request log: %s", err),
@@ -136,35 +152,12 @@ func (h *Handler) Requests(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	defer func() { _ = rows.Close() }()
-
-	entries := make([]requestLogEntry, 0, limit)
-	for rows.Next() {
-		var e requestLogEntry
-		if err := rows.Scan(&e.ID, &e.Method, &e.Path, &e.StatusCode,
-			&e.RequestBody, &e.ResponseBody, &e.DurationMs,
-			&e.CorrelationID, &e.CreatedAt); err != nil {
-			continue
-		}
-		entries = append(entries, e)
-	}
-
-	results := make([]json.RawMessage, 0, len(entries))
-	hasMore := len(entries) > limit
-	if hasMore {
-		entries = entries[:limit]
-	}
-
-	for i := range entries {
-		b, _ := json.Marshal(entries[i])
-		results = append(results, b)
-	}
 
 	resp := struct {
-		Results []json.RawMessage `json:"results"`
-		Paging  *api.Paging       `json:"paging,omitempty"`
+		Results []*store.RequestLogEntry `json:"results"`
+		Paging  *api.Paging              `json:"paging,omitempty"`
 	}{
-		Results: results,
+		Results: entries,
 	}
 
 	if hasMore {
@@ -179,6 +172,192 @@ func (h *Handler) Requests(w http.ResponseWriter, r *http.Request) {
 	api.WriteJSON(w, http.StatusOK, resp)
 }
 
+// Request returns a single request log entry.
+func (h *Handler) Request(w http.ResponseWriter, r *http.Request) {
+	corrID := api.CorrelationID(r.Context())
+	idStr := r.PathValue("id")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		api.WriteError(w, http.StatusNotFound, api.NewNotFoundError(
+			fmt.Sprintf("Request log entry %s not found", idStr), corrID))
+		return
+	}
+
+	entry, err := h.requestLog.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError(
+				fmt.Sprintf("Request log entry %s not found", idStr), corrID))
+			return
+		}
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{
+			Status:        "error",
+			Message:       fmt.Sprintf("get request log entry: %s", err),
+			CorrelationID: corrID,
+			Category:      "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, entry)
+}
+
+// RequestsHAR exports the matching request log entries, oldest first, as a
+// HAR 1.2 document. It accepts the same filters as Requests. Every matching
+// entry is exported unless limit is given; when limit leaves entries out, the
+// log's comment says so and gives the cursor of the rest.
+func (h *Handler) RequestsHAR(w http.ResponseWriter, r *http.Request) {
+	corrID := api.CorrelationID(r.Context())
+
+	q := r.URL.Query()
+	filter, err := parseRequestLogFilter(q, 1000, 10000)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+		return
+	}
+
+	var entries []*store.RequestLogEntry
+	truncated := false
+	for {
+		page, hasMore, err := h.requestLog.List(r.Context(), filter)
+		if err != nil {
+			api.WriteError(w, http.StatusInternalServerError, &api.Error{
+				Status:        "error",
+				Message:       fmt.Sprintf("query request log: %s", err),
+				CorrelationID: corrID,
+				Category:      "INTERNAL_ERROR",
+			})
+			return
+		}
+		entries = append(entries, page...)
+		if !hasMore {
+			break
+		}
+		if q.Has("limit") {
+			truncated = true
+			break
+		}
+		filter.After = page[len(page)-1].ID
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	var comment string
+	if truncated {
+		comment = fmt.Sprintf("Export truncated to the newest %d matching entries; export older entries with after=%d.",
+			len(entries), entries[len(entries)-1].ID)
+	}
+	slices.Reverse(entries)
+
+	doc := newHAR(entries, scheme+"://"+r.Host)
+	doc.Log.Comment = comment
+	w.Header().Set("Content-Disposition", `attachment; filename="notspot-requests.har"`)
+	api.WriteJSON(w, http.StatusOK, doc)
+}
+
+// parseRequestLogFilter builds a request log filter from query parameters:
+//
+//	method         comma-separated HTTP methods
+//	path           path prefix, or a glob when it contains *, ? or [
+//	status         comma-separated status classes ("4xx") or exact codes ("404")
+//	correlationId  exact correlation ID
+//	since, until   time range as ISO 8601 or epoch milliseconds (until is exclusive)
+//	body           substring of the request or response body
+//	limit, after   page size, from 1 to maxLimit, and cursor
+func parseRequestLogFilter(q url.Values, defaultLimit, maxLimit int) (store.RequestLogFilter, error) {
+	filter := store.RequestLogFilter{
+		Limit:         defaultLimit,
+		CorrelationID: q.Get("correlationId"),
+		BodyContains:  q.Get("body"),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLimit {
+			return filter, fmt.Errorf("invalid limit %q: expected an integer from 1 to %d", v, maxLimit)
+		}
+		filter.Limit = n
+	}
+	if v := q.Get("after"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid after %q: expected a request log entry ID", v)
+		}
+		filter.After = n
+	}
+
+	if v := q.Get("method"); v != "" {
+		for _, m := range strings.Split(v, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				filter.Methods = append(filter.Methods, strings.ToUpper(m))
+			}
+		}
+	}
+
+	if v := q.Get("path"); v != "" {
+		if strings.ContainsAny(v, "*?[") {
+			filter.PathGlob = v
+		} else {
+			filter.PathPrefix = v
+		}
+	}
+
+	if v := q.Get("status"); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			rng, err := parseStatusRange(strings.TrimSpace(part))
+			if err != nil {
+				return filter, err
+			}
+			filter.Statuses = append(filter.Statuses, rng)
+		}
+	}
+
+	if v := q.Get("since"); v != "" {
+		ts, err := parseTimestamp(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since %q: expected ISO 8601 or epoch milliseconds", v)
+		}
+		filter.Since = ts
+	}
+	if v := q.Get("until"); v != "" {
+		ts, err := parseTimestamp(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid until %q: expected ISO 8601 or epoch milliseconds", v)
+		}
+		filter.Until = ts
+	}
+
+	return filter, nil
+}
+
+// parseStatusRange parses a status class such as "2xx" or an exact code.
+func parseStatusRange(s string) (store.StatusRange, error) {
+	if len(s) == 3 && strings.EqualFold(s[1:], "xx") && s[0] >= '1' && s[0] <= '5' {
+		base := int(s[0]-'0') * 100
+		return store.StatusRange{Min: base, Max: base + 99}, nil
+	}
+	code, err := strconv.Atoi(s)
+	if err != nil || code < 100 || code > 599 {
+		return store.StatusRange{}, fmt.Errorf("invalid status %q: expected a class like 4xx or a code like 404", s)
+	}
+	return store.StatusRange{Min: code, Max: code}, nil
+}
+
+// parseTimestamp converts an ISO 8601 timestamp or epoch milliseconds into the
+// format stored in the request log.
+func parseTimestamp(s string) (string, error) {
+	var t time.Time
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		t = time.UnixMilli(ms)
+	} else if t, err = time.Parse(time.RFC3339Nano, s); err != nil {
+		return "", err
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.000Z"), nil
+}
+
 // ResetData clears all data tables within a transaction and re-seeds.
 // Exported for reuse by tests or other callers.
 func ResetData(ctx context.Context, db *sql.DB) error {
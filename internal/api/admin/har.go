@@ -0,0 +1,193 @@
+package admin
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/johnwards/hubspot/internal/store"
+)
+
+// HAR 1.2 document types. Only the fields notspot can populate are included;
+// see http://www.softwareishard.com/blog/har-12-spec/.
+
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+	Comment string     `json:"comment,omitempty"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            int64       `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	CorrelationID   string      `json:"_correlationId,omitempty"`
+	LogID           int64       `json:"_id"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    int64 `json:"send"`
+	Wait    int64 `json:"wait"`
+	Receive int64 `json:"receive"`
+}
+
+// newHAR converts request log entries into a HAR document. baseURL is the
+// scheme and host used to build absolute request URLs.
+func newHAR(entries []*store.RequestLogEntry, baseURL string) harDocument {
+	doc := harDocument{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "notspot", Version: "1.0"},
+		Entries: make([]harEntry, 0, len(entries)),
+	}}
+
+	for _, e := range entries {
+		reqURL := baseURL + e.Path
+		if e.Query != "" {
+			reqURL += "?" + e.Query
+		}
+
+		req := harRequest{
+			Method:      e.Method,
+			URL:         reqURL,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(e.RequestHeaders),
+			QueryString: harQueryString(e.Query),
+			HeadersSize: -1,
+			BodySize:    len(e.RequestBody),
+		}
+		if e.RequestBody != "" {
+			req.PostData = &harPostData{
+				MimeType: headerValue(e.RequestHeaders, "Content-Type"),
+				Text:     e.RequestBody,
+			}
+		}
+
+		doc.Log.Entries = append(doc.Log.Entries, harEntry{
+			StartedDateTime: e.CreatedAt,
+			Time:            e.DurationMs,
+			Request:         req,
+			Response: harResponse{
+				Status:      e.StatusCode,
+				StatusText:  http.StatusText(e.StatusCode),
+				HTTPVersion: "HTTP/1.1",
+				Cookies:     []harNameValue{},
+				Headers:     harHeaders(e.ResponseHeaders),
+				Content: harContent{
+					Size:     len(e.ResponseBody),
+					MimeType: headerValue(e.ResponseHeaders, "Content-Type"),
+					Text:     e.ResponseBody,
+				},
+				HeadersSize: -1,
+				BodySize:    len(e.ResponseBody),
+			},
+			Timings:       harTimings{Wait: e.DurationMs},
+			CorrelationID: e.CorrelationID,
+			LogID:         e.ID,
+		})
+	}
+
+	return doc
+}
+
+// harHeaders flattens headers into name/value pairs sorted by name.
+func harHeaders(h map[string][]string) []harNameValue {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]harNameValue, 0, len(h))
+	for _, name := range names {
+		for _, v := range h[name] {
+			out = append(out, harNameValue{Name: name, Value: v})
+		}
+	}
+	return out
+}
+
+// harQueryString splits a raw query string into decoded name/value pairs,
+// preserving their order.
+func harQueryString(raw string) []harNameValue {
+	out := []harNameValue{}
+	if raw == "" {
+		return out
+	}
+	for _, part := range strings.Split(raw, "&") {
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		out = append(out, harNameValue{Name: name, Value: value})
+	}
+	return out
+}
+
+// headerValue returns the first value of a header, or an empty string.
+func headerValue(h map[string][]string, name string) string {
+	if v := h[http.CanonicalHeaderKey(name)]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep is one segment of a parsed JSON path.
+type jsonPathStep struct {
+	key      string // object member name
+	index    int    // array index, used when isIndex is set
+	isIndex  bool
+	wildcard bool // every member or element
+}
+
+// parseJSONPath parses the subset of JSONPath used by body predicates:
+// $.a.b, $['a'], $.items[0], $.items[*] and $.*.
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSON path %q must start with $", path)
+	}
+
+	var steps []jsonPathStep
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("JSON path %q has an empty member name", path)
+			}
+			if name == "*" {
+				steps = append(steps, jsonPathStep{wildcard: true})
+			} else {
+				steps = append(steps, jsonPathStep{key: name})
+			}
+			rest = rest[end:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSON path %q has an unclosed bracket", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+
+			switch {
+			case inner == "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, jsonPathStep{key: inner[1 : len(inner)-1]})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("JSON path %q has an invalid index %q", path, inner)
+				}
+				steps = append(steps, jsonPathStep{index: n, isIndex: true})
+			}
+
+		default:
+			return nil, fmt.Errorf("JSON path %q has unexpected character %q", path, rest[0])
+		}
+	}
+	return steps, nil
+}
+
+// selectJSONPath returns every value the steps select from a decoded JSON
+// document.
+func selectJSONPath(doc any, steps []jsonPathStep) []any {
+	current := []any{doc}
+	for _, step := range steps {
+		var next []any
+		for _, v := range current {
+			switch node := v.(type) {
+			case map[string]any:
+				if step.wildcard {
+					for _, child := range node {
+						next = append(next, child)
+					}
+				} else if child, ok := node[step.key]; ok && !step.isIndex {
+					next = append(next, child)
+				}
+			case []any:
+				if step.wildcard {
+					next = append(next, node...)
+				} else if step.isIndex && step.index < len(node) {
+					next = append(next, node[step.index])
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
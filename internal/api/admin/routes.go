@@ -3,13 +3,32 @@ package admin
 import (
 	"database/sql"
 	"net/http"
+	"time"
+
+	"github.com/johnwards/hubspot/internal/store"
 )
 
-// RegisterRoutes registers all admin API endpoints on the mux.
-func RegisterRoutes(mux *http.ServeMux, db *sql.DB) {
-	h := &Handler{db: db}
+// RegisterRoutes registers all admin API endpoints on the mux. The purge
+// endpoint deletes objects archived longer than archiveRetention unless the
+// request overrides it.
+func RegisterRoutes(mux *http.ServeMux, db *sql.DB, archiveRetention time.Duration) {
+	h := &Handler{
+		db:               db,
+		requestLog:       store.NewSQLiteRequestLogStore(db),
+		expectations:     store.NewSQLiteExpectationStore(db),
+		objects:          store.NewSQLiteObjectStore(db),
+		archiveRetention: archiveRetention,
+	}
 
 	mux.HandleFunc("POST /_notspot/reset", h.Reset)
 	mux.HandleFunc("GET /_notspot/requests", h.Requests)
+	mux.HandleFunc("GET /_notspot/requests.har", h.RequestsHAR)
+	mux.HandleFunc("GET /_notspot/requests/{id}", h.Request)
 	mux.HandleFunc("POST /_notspot/seed", h.SeedData)
+	mux.HandleFunc("POST /_notspot/purge", h.Purge)
+
+	mux.HandleFunc("POST /_notspot/expectations", h.CreateExpectation)
+	mux.HandleFunc("GET /_notspot/expectations", h.ListExpectations)
+	mux.HandleFunc("DELETE /_notspot/expectations", h.DeleteExpectations)
+	mux.HandleFunc("GET /_notspot/expectations/verify", h.VerifyExpectations)
 }
@@ -259,13 +259,13 @@ func (h *Handler) BatchAssociateDefault(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	results, err := h.store.BatchAssociateDefault(r.Context(), fromType, toType, body.Inputs)
+	results, errs, err := h.store.BatchAssociateDefault(r.Context(), fromType, toType, body.Inputs)
 	if err != nil {
 		writeStoreError(w, corrID, err)
 		return
 	}
 
-	writeBatchDefaultResults(w, results)
+	writeBatchDefaultResults(w, results, errs)
 }
 
 // BatchCreate handles creating labeled associations for multiple pairs.
@@ -286,13 +286,13 @@ func (h *Handler) BatchCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results, err := h.store.BatchCreate(r.Context(), fromType, toType, body.Inputs)
+	results, errs, err := h.store.BatchCreate(r.Context(), fromType, toType, body.Inputs)
 	if err != nil {
 		writeStoreError(w, corrID, err)
 		return
 	}
 
-	writeBatchCreateResults(w, results)
+	writeBatchCreateResults(w, results, errs)
 }
 
 // BatchRead handles reading associations for multiple objects.
@@ -313,13 +313,13 @@ func (h *Handler) BatchRead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results, err := h.store.BatchRead(r.Context(), fromType, toType, body.Inputs)
+	results, errs, err := h.store.BatchRead(r.Context(), fromType, toType, body.Inputs)
 	if err != nil {
 		writeStoreError(w, corrID, err)
 		return
 	}
 
-	writeBatchReadResults(w, results)
+	writeBatchReadResults(w, results, errs)
 }
 
 // BatchArchive handles removing all associations for multiple pairs.
@@ -382,7 +382,7 @@ func writeStoreError(w http.ResponseWriter, corrID string, err error) {
 	})
 }
 
-func writeBatchDefaultResults(w http.ResponseWriter, results []store.BatchDefaultAssocResult) {
+func writeBatchDefaultResults(w http.ResponseWriter, results []store.BatchDefaultAssocResult, errs []domain.BatchError) {
 	ts := store.Now()
 	out := make([]any, len(results))
 	for i, r := range results {
@@ -402,13 +402,10 @@ func writeBatchDefaultResults(w http.ResponseWriter, results []store.BatchDefaul
 			},
 		}
 	}
-	api.WriteJSON(w, http.StatusOK, map[string]any{
-		"status": "COMPLETE", "startedAt": ts, "completedAt": ts,
-		"results": out, "numErrors": 0,
-	})
+	writeBatchResponse(w, ts, out, errs)
 }
 
-func writeBatchCreateResults(w http.ResponseWriter, results []store.BatchCreateResult) {
+func writeBatchCreateResults(w http.ResponseWriter, results []store.BatchCreateResult, errs []domain.BatchError) {
 	ts := store.Now()
 	out := make([]any, len(results))
 	for i, r := range results {
@@ -430,13 +427,10 @@ func writeBatchCreateResults(w http.ResponseWriter, results []store.BatchCreateR
 			},
 		}
 	}
-	api.WriteJSON(w, http.StatusOK, map[string]any{
-		"status": "COMPLETE", "startedAt": ts, "completedAt": ts,
-		"results": out, "numErrors": 0,
-	})
+	writeBatchResponse(w, ts, out, errs)
 }
 
-func writeBatchReadResults(w http.ResponseWriter, results []store.BatchAssocResult) {
+func writeBatchReadResults(w http.ResponseWriter, results []store.BatchAssocResult, errs []domain.BatchError) {
 	ts := store.Now()
 	out := make([]any, len(results))
 	for i, r := range results {
@@ -452,10 +446,20 @@ func writeBatchReadResults(w http.ResponseWriter, results []store.BatchAssocResu
 			"to":   toResults,
 		}
 	}
-	api.WriteJSON(w, http.StatusOK, map[string]any{
+	writeBatchResponse(w, ts, out, errs)
+}
+
+// writeBatchResponse writes a v4 batch response, with 207 Multi-Status and
+// the errors when some inputs failed.
+func writeBatchResponse(w http.ResponseWriter, ts string, results []any, errs []domain.BatchError) {
+	body := map[string]any{
 		"status": "COMPLETE", "startedAt": ts, "completedAt": ts,
-		"results": out, "numErrors": 0,
-	})
+		"results": results, "numErrors": len(errs),
+	}
+	if len(errs) > 0 {
+		body["errors"] = errs
+	}
+	api.WriteJSON(w, api.BatchStatus(http.StatusOK, len(errs)), body)
 }
 
 func toAnySlice(results []domain.AssociationResult) []any {
@@ -43,7 +43,7 @@ func setupTestServer(t *testing.T) *httptest.Server {
 
 func createObject(t *testing.T, serverURL, objectType string) string {
 	t.Helper()
-	body := map[string]any{"properties": map[string]string{"test": "val"}}
+	body := map[string]any{"properties": map[string]string{}}
 	b, _ := json.Marshal(body)
 	resp, err := http.Post(fmt.Sprintf("%s/crm/v3/objects/%s", serverURL, objectType), "application/json", bytes.NewReader(b))
 	if err != nil {
@@ -1,6 +1,10 @@
 package api
 
-import "net/http"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
 
 // Standard HubSpot error categories.
 const (
@@ -12,12 +16,13 @@ const (
 
 // Error represents a HubSpot-compatible error response.
 type Error struct {
-	Status        string        `json:"status"`
-	Message       string        `json:"message"`
-	CorrelationID string        `json:"correlationId"`
-	Category      string        `json:"category"`
-	SubCategory   string        `json:"subCategory,omitempty"`
-	Errors        []ErrorDetail `json:"errors,omitempty"`
+	Status        string              `json:"status"`
+	Message       string              `json:"message"`
+	CorrelationID string              `json:"correlationId"`
+	Category      string              `json:"category"`
+	SubCategory   string              `json:"subCategory,omitempty"`
+	Context       map[string][]string `json:"context,omitempty"`
+	Errors        []ErrorDetail       `json:"errors,omitempty"`
 }
 
 // ErrorDetail represents a single error within an Error.
@@ -60,6 +65,55 @@ func NewConflictError(message, correlationID string) *Error {
 	}
 }
 
+// CodePropertyDoesntExist is the error code for writes to a property that has
+// no definition.
+const CodePropertyDoesntExist = "PROPERTY_DOESNT_EXIST"
+
+// CodeReadOnlyValue is the error code for writes to a property whose value
+// cannot be set, such as a calculated property.
+const CodeReadOnlyValue = "READ_ONLY_VALUE"
+
+// PropertyError describes one invalid property value in a write.
+type PropertyError struct {
+	Name    string // property name
+	Code    string // HubSpot error code, e.g. INVALID_OPTION
+	Message string
+}
+
+// UnknownPropertyError returns the PropertyError for a property that has no
+// definition.
+func UnknownPropertyError(name string) PropertyError {
+	return PropertyError{
+		Name:    name,
+		Code:    CodePropertyDoesntExist,
+		Message: fmt.Sprintf("Property %q does not exist", name),
+	}
+}
+
+// NewPropertyValidationError creates HubSpot's 400 error for invalid property
+// values. The message embeds the per-property results as JSON, and each value
+// is also listed in errors with its code and property name.
+func NewPropertyValidationError(errs []PropertyError, correlationID string) *Error {
+	type result struct {
+		IsValid bool   `json:"isValid"`
+		Message string `json:"message"`
+		Error   string `json:"error"`
+		Name    string `json:"name"`
+	}
+	results := make([]result, len(errs))
+	details := make([]ErrorDetail, len(errs))
+	for i, e := range errs {
+		results[i] = result{Message: e.Message, Error: e.Code, Name: e.Name}
+		details[i] = ErrorDetail{
+			Message: e.Message,
+			Code:    e.Code,
+			Context: map[string][]string{"propertyName": {e.Name}},
+		}
+	}
+	b, _ := json.Marshal(results)
+	return NewValidationError("Property values were not valid: "+string(b), correlationID, details)
+}
+
 // WriteError writes an Error as a JSON response with the given HTTP status code.
 func WriteError(w http.ResponseWriter, statusCode int, apiErr *Error) {
 	WriteJSON(w, statusCode, apiErr)
@@ -1,14 +1,18 @@
 package imports
 
 import (
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"io"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/johnwards/hubspot/internal/api"
+	"github.com/johnwards/hubspot/internal/domain"
 	"github.com/johnwards/hubspot/internal/store"
 )
 
@@ -130,6 +134,15 @@ func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
 		colMap[i] = cm.PropertyName
 	}
 
+	// Attribute every object write to this import.
+	src := store.ChangeSourceFrom(r.Context())
+	ctx := store.WithChangeSource(r.Context(), domain.ChangeSource{
+		Type:   domain.SourceImport,
+		ID:     imp.ID,
+		Label:  req.Name,
+		UserID: src.UserID,
+	})
+
 	reader := csv.NewReader(file)
 	lineNumber := 0
 	imported := 0
@@ -153,6 +166,18 @@ func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Columns mapped to undefined properties fail every row, as the
+	// equivalent API write would.
+	var unknown []string
+	if !h.store.AllowUnknownProperties {
+		unknown, err = h.unknownProperties(r.Context(), objectTypeID, colMap)
+		if err != nil {
+			_ = h.store.Imports.UpdateState(r.Context(), imp.ID, "FAILED", nil)
+			api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+			return
+		}
+	}
+
 	for {
 		record, err := reader.Read()
 		if errors.Is(err, io.EOF) {
@@ -173,14 +198,23 @@ func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		if len(unknown) > 0 {
+			failed++
+			for _, name := range unknown {
+				perr := api.UnknownPropertyError(name)
+				_ = h.store.Imports.AddError(r.Context(), imp.ID, perr.Code, perr.Message, name, objectTypeID, lineNumber)
+			}
+			continue
+		}
+
 		switch opType {
 		case "CREATE":
-			_, err = h.store.Objects.Create(r.Context(), objectTypeID, props)
+			_, err = h.store.Objects.Create(ctx, objectTypeID, props)
 		case "UPDATE":
 			// For updates, we need an ID property in the mapping.
 			if id, ok := props["hs_object_id"]; ok {
 				delete(props, "hs_object_id")
-				_, err = h.store.Objects.Update(r.Context(), objectTypeID, id, props)
+				_, err = h.store.Objects.Update(ctx, objectTypeID, id, props)
 			} else {
 				err = errors.New("hs_object_id required for UPDATE operation")
 			}
@@ -194,21 +228,26 @@ func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
 			if lookupValue == "" {
 				err = errors.New("lookup property " + idProp + " is empty")
 			} else {
-				existing, getErr := h.store.Objects.GetByProperty(r.Context(), objectTypeID, idProp, lookupValue, nil)
+				existing, getErr := h.store.Objects.GetByProperty(ctx, objectTypeID, idProp, lookupValue, nil)
 				if getErr != nil {
-					_, err = h.store.Objects.Create(r.Context(), objectTypeID, props)
+					_, err = h.store.Objects.Create(ctx, objectTypeID, props)
 				} else {
-					_, err = h.store.Objects.Update(r.Context(), objectTypeID, existing.ID, props)
+					_, err = h.store.Objects.Update(ctx, objectTypeID, existing.ID, props)
 				}
 			}
 		default:
-			_, err = h.store.Objects.Create(r.Context(), objectTypeID, props)
+			_, err = h.store.Objects.Create(ctx, objectTypeID, props)
 		}
 
-		if err != nil {
+		var uv *store.UniqueValueError
+		switch {
+		case errors.As(err, &uv):
+			failed++
+			_ = h.store.Imports.AddError(r.Context(), imp.ID, "DUPLICATE_UNIQUE_PROPERTY_VALUE", err.Error(), uv.Value, objectTypeID, lineNumber)
+		case err != nil:
 			failed++
 			_ = h.store.Imports.AddError(r.Context(), imp.ID, "OBJECT_CREATE_ERROR", err.Error(), "", objectTypeID, lineNumber)
-		} else {
+		default:
 			imported++
 		}
 	}
@@ -246,6 +285,32 @@ func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// unknownProperties returns the mapped property names that have no
+// definition on the object type, in column order. It returns an error only
+// if the definitions cannot be read.
+func (h *Handler) unknownProperties(ctx context.Context, objectTypeID string, colMap map[int]string) ([]string, error) {
+	defs, err := h.store.Properties.List(ctx, objectTypeID)
+	if errors.Is(err, store.ErrNotFound) {
+		// Rows of an unknown object type fail on their own writes.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defined := make(map[string]bool, len(defs))
+	for _, p := range defs {
+		defined[p.Name] = true
+	}
+
+	var unknown []string
+	for _, i := range slices.Sorted(maps.Keys(colMap)) {
+		if name := colMap[i]; name != "" && !defined[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown, nil
+}
+
 // List handles GET /crm/v3/imports.
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	corrID := api.CorrelationID(r.Context())
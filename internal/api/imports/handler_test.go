@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -12,12 +13,13 @@ import (
 	"github.com/johnwards/hubspot/internal/api"
 	"github.com/johnwards/hubspot/internal/api/imports"
 	"github.com/johnwards/hubspot/internal/database"
+	"github.com/johnwards/hubspot/internal/domain"
 	"github.com/johnwards/hubspot/internal/seed"
 	"github.com/johnwards/hubspot/internal/store"
 	"github.com/johnwards/hubspot/internal/testhelpers"
 )
 
-func setupServer(t *testing.T) *httptest.Server {
+func setupServer(t *testing.T, opts ...func(*store.Store)) *httptest.Server {
 	t.Helper()
 	db := testhelpers.NewTestDB(t)
 	ctx := context.Background()
@@ -30,6 +32,9 @@ func setupServer(t *testing.T) *httptest.Server {
 	}
 
 	s := store.New(db)
+	for _, opt := range opts {
+		opt(s)
+	}
 	mux := http.NewServeMux()
 	imports.RegisterRoutes(mux, s)
 
@@ -113,6 +118,57 @@ func TestStartImport(t *testing.T) {
 	}
 }
 
+func TestStartImportPropertyLookupFails(t *testing.T) {
+	srv := setupServer(t, func(s *store.Store) { s.Properties = failingPropertyStore{s.Properties} })
+	defer srv.Close()
+
+	importReq := `{
+		"name": "Lookup Failure",
+		"importOperations": {"0-1": {"objectTypeId": "0-1", "importOperationType": "CREATE"}},
+		"files": [{
+			"fileName": "contacts.csv",
+			"fileFormat": "CSV",
+			"fileImportPage": {
+				"hasHeader": true,
+				"columnMappings": [
+					{"columnObjectTypeId": "0-1", "columnName": "Email", "propertyName": "email"}
+				]
+			}
+		}]
+	}`
+
+	resp := createImport(t, srv, importReq, "Email\nalice@example.com\n")
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", resp.StatusCode)
+	}
+
+	listResp, err := http.Get(srv.URL + "/crm/v3/imports")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	defer func() { _ = listResp.Body.Close() }()
+	var list struct {
+		Results []store.ImportResponse `json:"results"`
+	}
+	if err := json.NewDecoder(listResp.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list.Results) != 1 || list.Results[0].State != "FAILED" {
+		t.Errorf("expected one FAILED import, got %+v", list.Results)
+	}
+}
+
+// failingPropertyStore is a PropertyStore whose List always fails.
+type failingPropertyStore struct {
+	store.PropertyStore
+}
+
+func (failingPropertyStore) List(context.Context, string) ([]domain.Property, error) {
+	return nil, errors.New("database is locked")
+}
+
 func TestListImports(t *testing.T) {
 	srv := setupServer(t)
 	defer srv.Close()
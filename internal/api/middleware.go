@@ -3,11 +3,17 @@ package api
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/johnwards/hubspot/internal/domain"
+	"github.com/johnwards/hubspot/internal/store"
 )
 
 type contextKey int
@@ -93,6 +99,34 @@ func Auth(authToken string) func(http.Handler) http.Handler {
 	}
 }
 
+// ChangeSource returns middleware that attributes property writes made by the
+// request. API calls are recorded as "API" with a sourceId derived from the
+// bearer token, so each token behaves like a distinct app. The web UI sends
+// X-Notspot-Source: CRM_UI, and X-Notspot-User-Id sets the acting user.
+func ChangeSource() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			src := domain.ChangeSource{Type: domain.SourceAPI}
+			if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); token != "" {
+				src.ID = tokenSourceID(token)
+			}
+			if strings.EqualFold(r.Header.Get("X-Notspot-Source"), domain.SourceCRMUI) {
+				src = domain.ChangeSource{Type: domain.SourceCRMUI}
+			}
+			if userID, err := strconv.Atoi(r.Header.Get("X-Notspot-User-Id")); err == nil && userID > 0 {
+				src.UserID = userID
+			}
+			next.ServeHTTP(w, r.WithContext(store.WithChangeSource(r.Context(), src)))
+		})
+	}
+}
+
+// tokenSourceID derives a stable, non-secret identifier for an access token.
+func tokenSourceID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:6])
+}
+
 // JSONContentType returns middleware that sets the Content-Type header to
 // application/json on all responses.
 func JSONContentType() func(http.Handler) http.Handler {
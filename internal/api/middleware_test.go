@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/johnwards/hubspot/internal/api"
+	"github.com/johnwards/hubspot/internal/domain"
+	"github.com/johnwards/hubspot/internal/store"
 )
 
 func TestRecoveryMiddleware(t *testing.T) {
@@ -206,3 +208,37 @@ func TestChainOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestChangeSourceMiddleware(t *testing.T) {
+	var captured domain.ChangeSource
+	handler := api.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			captured = store.ChangeSourceFrom(r.Context())
+			w.WriteHeader(http.StatusOK)
+		}),
+		api.ChangeSource(),
+	)
+
+	req := httptest.NewRequest(http.MethodPatch, "/crm/v3/objects/contacts/1", http.NoBody)
+	req.Header.Set("Authorization", "Bearer token-a")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if captured.Type != domain.SourceAPI || captured.ID == "" {
+		t.Errorf("expected API source with token ID, got %+v", captured)
+	}
+	tokenA := captured.ID
+
+	req = httptest.NewRequest(http.MethodPatch, "/crm/v3/objects/contacts/1", http.NoBody)
+	req.Header.Set("Authorization", "Bearer token-b")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if captured.ID == tokenA {
+		t.Error("expected different tokens to produce different source IDs")
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/crm/v3/objects/contacts/1", http.NoBody)
+	req.Header.Set("X-Notspot-Source", "CRM_UI")
+	req.Header.Set("X-Notspot-User-Id", "1001")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if captured.Type != domain.SourceCRMUI || captured.UserID != 1001 {
+		t.Errorf("expected CRM_UI source for user 1001, got %+v", captured)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -21,14 +20,16 @@ type Handler struct {
 
 const maxBatchSize = 100
 
+// maxInlineAssociations caps the associated records returned per type on
+// object reads.
+const maxInlineAssociations = 500
+
 // Create handles POST /crm/v3/objects/{objectType}.
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	objectType := r.PathValue("objectType")
 	corrID := api.CorrelationID(r.Context())
 
-	var body struct {
-		Properties map[string]string `json:"properties"`
-	}
+	var body domain.CreateInput
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("Invalid input JSON", corrID, nil))
 		return
@@ -39,18 +40,37 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate property values against property definitions.
-	if err := h.validatePropertyValues(r.Context(), objectType, body.Properties); err != nil {
-		api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+	errs, err := h.normalizeProperties(r.Context(), objectType, body.Properties)
+	if err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+	if len(errs) > 0 {
+		api.WriteError(w, http.StatusBadRequest, api.NewPropertyValidationError(errs, corrID))
 		return
 	}
 
-	obj, err := h.store.Objects.Create(r.Context(), objectType, body.Properties)
+	obj, err := h.store.Objects.CreateWithAssociations(r.Context(), objectType, body)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError("Object type not found", corrID))
 			return
 		}
+		if errors.Is(err, store.ErrInvalidAssociation) || errors.Is(err, store.ErrGDPRDeleted) {
+			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+			return
+		}
+		var uv *store.UniqueValueError
+		if errors.As(err, &uv) {
+			apiErr := api.NewConflictError(uv.Error(), corrID)
+			apiErr.Context = uv.Context()
+			api.WriteError(w, http.StatusConflict, apiErr)
+			return
+		}
+		if apiErr := stageValidationError(err, corrID); apiErr != nil {
+			api.WriteError(w, http.StatusBadRequest, apiErr)
+			return
+		}
 		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
 		return
 	}
@@ -64,7 +84,7 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 	objectID := r.PathValue("objectId")
 	corrID := api.CorrelationID(r.Context())
 
-	props := parsePropertiesParam(r)
+	props := parseListParam(r, "properties")
 	idProperty := r.URL.Query().Get("idProperty")
 
 	var obj *domain.Object
@@ -84,6 +104,17 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.attachHistory(r.Context(), []*domain.Object{obj}, parseListParam(r, "propertiesWithHistory")); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+
+	archived := r.URL.Query().Get("archived") == "true"
+	if err := h.attachAssociations(r.Context(), objectType, []*domain.Object{obj}, parseListParam(r, "associations"), archived); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+
 	api.WriteJSON(w, http.StatusOK, obj)
 }
 
@@ -107,7 +138,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	opts := domain.ListOpts{
 		Limit:      limit,
 		After:      r.URL.Query().Get("after"),
-		Properties: parsePropertiesParam(r),
+		Properties: parseListParam(r, "properties"),
 		Archived:   archived,
 	}
 
@@ -121,6 +152,16 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.attachHistory(r.Context(), page.Results, parseListParam(r, "propertiesWithHistory")); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+
+	if err := h.attachAssociations(r.Context(), objectType, page.Results, parseListParam(r, "associations"), archived); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+
 	results := make([]any, len(page.Results))
 	for i, obj := range page.Results {
 		results[i] = obj
@@ -143,19 +184,44 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	corrID := api.CorrelationID(r.Context())
 
 	var body struct {
-		Properties map[string]string `json:"properties"`
+		Properties domain.PropertyValues `json:"properties"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("Invalid input JSON", corrID, nil))
 		return
 	}
 
+	errs, err := h.normalizeProperties(r.Context(), objectType, body.Properties)
+	if err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+	if len(errs) > 0 {
+		api.WriteError(w, http.StatusBadRequest, api.NewPropertyValidationError(errs, corrID))
+		return
+	}
+
 	obj, err := h.store.Objects.Update(r.Context(), objectType, objectID, body.Properties)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError("Object not found", corrID))
 			return
 		}
+		var uv *store.UniqueValueError
+		if errors.As(err, &uv) {
+			apiErr := api.NewConflictError(uv.Error(), corrID)
+			apiErr.Context = uv.Context()
+			api.WriteError(w, http.StatusConflict, apiErr)
+			return
+		}
+		if apiErr := stageValidationError(err, corrID); apiErr != nil {
+			api.WriteError(w, http.StatusBadRequest, apiErr)
+			return
+		}
+		if errors.Is(err, store.ErrGDPRDeleted) {
+			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+			return
+		}
 		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
 		return
 	}
@@ -207,17 +273,39 @@ func (h *Handler) BatchCreate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	props := make([]map[string]string, len(body.Inputs))
+	for i, input := range body.Inputs {
+		props[i] = input.Properties
+	}
+	errs, err := h.normalizeProperties(r.Context(), objectType, props...)
+	if err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+	if len(errs) > 0 {
+		api.WriteError(w, http.StatusBadRequest, api.NewPropertyValidationError(errs, corrID))
+		return
+	}
+
 	result, err := h.store.Objects.BatchCreate(r.Context(), objectType, body.Inputs)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError("Object type not found", corrID))
 			return
 		}
+		if errors.Is(err, store.ErrInvalidAssociation) {
+			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+			return
+		}
+		if apiErr := stageValidationError(err, corrID); apiErr != nil {
+			api.WriteError(w, http.StatusBadRequest, apiErr)
+			return
+		}
 		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
 		return
 	}
 
-	api.WriteJSON(w, http.StatusCreated, result)
+	api.WriteJSON(w, api.BatchStatus(http.StatusCreated, result.NumErrors), result)
 }
 
 // BatchRead handles POST /crm/v3/objects/{objectType}/batch/read.
@@ -229,8 +317,10 @@ func (h *Handler) BatchRead(w http.ResponseWriter, r *http.Request) {
 		Inputs []struct {
 			ID string `json:"id"`
 		} `json:"inputs"`
-		Properties []string `json:"properties"`
-		IDProperty string   `json:"idProperty"`
+		Properties            []string `json:"properties"`
+		PropertiesWithHistory []string `json:"propertiesWithHistory"`
+		Associations          []string `json:"associations"`
+		IDProperty            string   `json:"idProperty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("Invalid input JSON", corrID, nil))
@@ -257,7 +347,18 @@ func (h *Handler) BatchRead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	api.WriteJSON(w, http.StatusOK, result)
+	if err := h.attachHistory(r.Context(), result.Results, body.PropertiesWithHistory); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+
+	archived := r.URL.Query().Get("archived") == "true"
+	if err := h.attachAssociations(r.Context(), objectType, result.Results, body.Associations, archived); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+
+	api.WriteJSON(w, api.BatchStatus(http.StatusOK, result.NumErrors), result)
 }
 
 // BatchUpdate handles POST /crm/v3/objects/{objectType}/batch/update.
@@ -278,17 +379,35 @@ func (h *Handler) BatchUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	props := make([]map[string]string, len(body.Inputs))
+	for i, input := range body.Inputs {
+		props[i] = input.Properties
+	}
+	errs, err := h.normalizeProperties(r.Context(), objectType, props...)
+	if err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+	if len(errs) > 0 {
+		api.WriteError(w, http.StatusBadRequest, api.NewPropertyValidationError(errs, corrID))
+		return
+	}
+
 	result, err := h.store.Objects.BatchUpdate(r.Context(), objectType, body.Inputs)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
-			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError("Object not found", corrID))
+			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError("Object type not found", corrID))
+			return
+		}
+		if apiErr := stageValidationError(err, corrID); apiErr != nil {
+			api.WriteError(w, http.StatusBadRequest, apiErr)
 			return
 		}
 		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
 		return
 	}
 
-	api.WriteJSON(w, http.StatusOK, result)
+	api.WriteJSON(w, api.BatchStatus(http.StatusOK, result.NumErrors), result)
 }
 
 // BatchUpsert handles POST /crm/v3/objects/{objectType}/batch/upsert.
@@ -297,7 +416,8 @@ func (h *Handler) BatchUpsert(w http.ResponseWriter, r *http.Request) {
 	corrID := api.CorrelationID(r.Context())
 
 	var body struct {
-		Inputs []domain.UpsertInput `json:"inputs"`
+		IDProperty string               `json:"idProperty"`
+		Inputs     []domain.UpsertInput `json:"inputs"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("Invalid input JSON", corrID, nil))
@@ -309,10 +429,28 @@ func (h *Handler) BatchUpsert(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Default idProperty for contacts is email.
-	idProperty := "hs_object_id"
-	if objectType == "contacts" || objectType == "0-1" {
-		idProperty = "email"
+	props := make([]map[string]string, len(body.Inputs))
+	for i, input := range body.Inputs {
+		props[i] = input.Properties
+	}
+	errs, err := h.normalizeProperties(r.Context(), objectType, props...)
+	if err != nil {
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+	if len(errs) > 0 {
+		api.WriteError(w, http.StatusBadRequest, api.NewPropertyValidationError(errs, corrID))
+		return
+	}
+
+	// Inputs without their own idProperty use the request's, which defaults
+	// to email for contacts.
+	idProperty := body.IDProperty
+	if idProperty == "" {
+		idProperty = "hs_object_id"
+		if objectType == "contacts" || objectType == "0-1" {
+			idProperty = "email"
+		}
 	}
 
 	result, err := h.store.Objects.BatchUpsert(r.Context(), objectType, body.Inputs, idProperty)
@@ -321,11 +459,19 @@ func (h *Handler) BatchUpsert(w http.ResponseWriter, r *http.Request) {
 			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError("Object type not found", corrID))
 			return
 		}
+		if errors.Is(err, store.ErrInvalidIDProperty) {
+			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+			return
+		}
+		if apiErr := stageValidationError(err, corrID); apiErr != nil {
+			api.WriteError(w, http.StatusBadRequest, apiErr)
+			return
+		}
 		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
 		return
 	}
 
-	api.WriteJSON(w, http.StatusOK, result)
+	api.WriteJSON(w, api.BatchStatus(http.StatusOK, result.NumErrors), result)
 }
 
 // BatchArchive handles POST /crm/v3/objects/{objectType}/batch/archive.
@@ -353,16 +499,20 @@ func (h *Handler) BatchArchive(w http.ResponseWriter, r *http.Request) {
 		ids[i] = input.ID
 	}
 
-	err := h.store.Objects.BatchArchive(r.Context(), objectType, ids)
+	result, err := h.store.Objects.BatchArchive(r.Context(), objectType, ids)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
-			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError("Object not found", corrID))
+			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError("Object type not found", corrID))
 			return
 		}
 		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
 		return
 	}
 
+	if result.NumErrors > 0 {
+		api.WriteJSON(w, http.StatusMultiStatus, result)
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -384,6 +534,10 @@ func (h *Handler) Merge(w http.ResponseWriter, r *http.Request) {
 		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("primaryObjectId and objectIdToMerge are required", corrID, nil))
 		return
 	}
+	if body.PrimaryObjectID == body.ObjectIDToMerge {
+		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("Cannot merge an object with itself", corrID, nil))
+		return
+	}
 
 	obj, err := h.store.Objects.Merge(r.Context(), objectType, body.PrimaryObjectID, body.ObjectIDToMerge)
 	if err != nil {
@@ -398,44 +552,145 @@ func (h *Handler) Merge(w http.ResponseWriter, r *http.Request) {
 	api.WriteJSON(w, http.StatusOK, obj)
 }
 
-func (h *Handler) validatePropertyValues(ctx context.Context, objectType string, properties map[string]string) error {
-	if len(properties) == 0 {
-		return nil
+// GDPRDelete handles POST /crm/v3/objects/contacts/gdpr-delete.
+func (h *Handler) GDPRDelete(w http.ResponseWriter, r *http.Request) {
+	corrID := api.CorrelationID(r.Context())
+
+	var body struct {
+		ObjectID   string `json:"objectId"`
+		IDProperty string `json:"idProperty"`
 	}
-	rows, err := h.store.DB.QueryContext(ctx,
-		`SELECT pd.name, pd.type FROM property_definitions pd
-		 JOIN object_types ot ON pd.object_type_id = ot.id
-		 WHERE (ot.name = ? OR ot.id = ?)`,
-		objectType, objectType,
-	)
-	if err != nil {
-		return nil
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("Invalid input JSON", corrID, nil))
+		return
+	}
+	if body.ObjectID == "" {
+		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("objectId is required", corrID, nil))
+		return
+	}
+	idProperty := body.IDProperty
+	switch idProperty {
+	case "", "hs_object_id":
+		idProperty = ""
+	case "email":
+	default:
+		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("idProperty must be email or hs_object_id", corrID, nil))
+		return
 	}
-	defer func() { _ = rows.Close() }()
 
-	propTypes := make(map[string]string)
-	for rows.Next() {
-		var name, typ string
-		if err := rows.Scan(&name, &typ); err != nil {
-			continue
+	if err := h.store.Objects.GDPRDelete(r.Context(), body.ObjectID, idProperty); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError("Object not found", corrID))
+			return
+		}
+		api.WriteError(w, http.StatusInternalServerError, &api.Error{Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// attachHistory fills PropertiesWithHistory on each object for the named
+// properties. It is a no-op when no properties are requested.
+func (h *Handler) attachHistory(ctx context.Context, objs []*domain.Object, props []string) error {
+	if len(props) == 0 {
+		return nil
+	}
+	for _, obj := range objs {
+		history, err := h.store.Objects.GetPropertyHistory(ctx, obj.ID, props)
+		if err != nil {
+			return err
 		}
-		propTypes[name] = typ
+		obj.PropertiesWithHistory = history
 	}
+	return nil
+}
 
-	for propName, propValue := range properties {
-		if typ, ok := propTypes[propName]; ok {
-			if typ == "number" {
-				if _, err := strconv.ParseFloat(propValue, 64); err != nil {
-					return fmt.Errorf("Property value %q is not valid for type %s", propValue, typ)
+// attachAssociations fills Associations on each object for the named target
+// object types, keyed by their plural names. Unknown types are skipped, as
+// HubSpot does. Associations to archived objects are included only when
+// archived is set. At most maxInlineAssociations records are returned per
+// type; paging.next then points at the rest on the v4 associations endpoint.
+func (h *Handler) attachAssociations(ctx context.Context, objectType string, objs []*domain.Object, toTypes []string, archived bool) error {
+	if len(toTypes) == 0 {
+		return nil
+	}
+	for _, toType := range toTypes {
+		name, err := store.ObjectTypeName(ctx, h.store.DB, toType)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		for _, obj := range objs {
+			assocs, err := h.store.Associations.ListAssociatedObjects(ctx, objectType, obj.ID, name, archived)
+			if err != nil {
+				if errors.Is(err, store.ErrNotFound) {
+					continue
 				}
+				return err
+			}
+			if len(assocs) == 0 {
+				continue
+			}
+			page, err := h.inlineAssociationPage(ctx, objectType, obj.ID, name, assocs)
+			if err != nil {
+				return err
 			}
+			if obj.Associations == nil {
+				obj.Associations = make(map[string]*domain.ObjectAssociations)
+			}
+			obj.Associations[name] = page
 		}
 	}
 	return nil
 }
 
-func parsePropertiesParam(r *http.Request) []string {
-	v := r.URL.Query().Get("properties")
+// inlineAssociationPage returns the first maxInlineAssociations records of
+// assocs, which holds one entry per record and association type. A record's
+// entries are never split across pages. When records remain, the page's
+// cursor is their offset in the v4 associations listing of id.
+func (h *Handler) inlineAssociationPage(ctx context.Context, objectType, id, toType string, assocs []domain.AssociatedObject) (*domain.ObjectAssociations, error) {
+	page := &domain.ObjectAssociations{Results: assocs}
+	records, cut := 0, len(assocs)
+	for i, a := range assocs {
+		if i > 0 && a.ID == assocs[i-1].ID {
+			continue
+		}
+		if records == maxInlineAssociations {
+			cut = i
+			break
+		}
+		records++
+	}
+	if cut == len(assocs) {
+		return page, nil
+	}
+	page.Results = assocs[:cut]
+
+	all, err := h.store.Associations.GetAssociations(ctx, objectType, id, toType)
+	if err != nil {
+		return nil, err
+	}
+	after := strconv.Itoa(records)
+	for i, r := range all {
+		if r.ToObjectID == assocs[cut].ID {
+			after = strconv.Itoa(i)
+			break
+		}
+	}
+	page.Paging = &domain.AssociationPaging{Next: domain.AssociationPagingNext{
+		After: after,
+		Link:  "/crm/v4/objects/" + objectType + "/" + id + "/associations/" + toType + "?after=" + after,
+	}}
+	return page, nil
+}
+
+// parseListParam splits a comma-separated query parameter such as
+// "properties" into trimmed, non-empty names.
+func parseListParam(r *http.Request, name string) []string {
+	v := r.URL.Query().Get(name)
 	if v == "" {
 		return nil
 	}
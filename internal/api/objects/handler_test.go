@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -17,7 +18,7 @@ import (
 	"github.com/johnwards/hubspot/internal/testhelpers"
 )
 
-func setupServer(t *testing.T) *httptest.Server {
+func setupServer(t *testing.T, opts ...func(*store.Store)) *httptest.Server {
 	t.Helper()
 	db := testhelpers.NewTestDB(t)
 	ctx := context.Background()
@@ -30,6 +31,9 @@ func setupServer(t *testing.T) *httptest.Server {
 	}
 
 	s := store.New(db)
+	for _, opt := range opts {
+		opt(s)
+	}
 	mux := http.NewServeMux()
 	objects.RegisterRoutes(mux, s)
 
@@ -129,6 +133,43 @@ func TestGetWithProperties(t *testing.T) {
 	}
 }
 
+func TestGetWithPropertiesWithHistory(t *testing.T) {
+	srv := setupServer(t)
+	defer srv.Close()
+
+	created := createContact(t, srv, `{"email":"history@example.com","firstname":"Before"}`)
+
+	req, _ := http.NewRequest(http.MethodPatch, srv.URL+"/crm/v3/objects/contacts/"+created.ID,
+		bytes.NewBufferString(`{"properties":{"firstname":"After"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	patchResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	_ = patchResp.Body.Close()
+
+	resp, err := http.Get(srv.URL + "/crm/v3/objects/contacts/" + created.ID + "?propertiesWithHistory=firstname")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var obj domain.Object
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	versions := obj.PropertiesWithHistory["firstname"]
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 firstname versions, got %d", len(versions))
+	}
+	if versions[0].Value != "After" || versions[1].Value != "Before" {
+		t.Errorf("expected [After Before], got [%s %s]", versions[0].Value, versions[1].Value)
+	}
+	if _, ok := obj.PropertiesWithHistory["email"]; ok {
+		t.Error("expected only requested properties in propertiesWithHistory")
+	}
+}
+
 func TestListEndpoint(t *testing.T) {
 	srv := setupServer(t)
 	defer srv.Close()
@@ -299,3 +340,229 @@ func TestCreateWithObjectTypeID(t *testing.T) {
 		t.Fatalf("expected 201, got %d", resp.StatusCode)
 	}
 }
+
+func TestPropertyValueNormalization(t *testing.T) {
+	srv := setupServer(t)
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		objectType string
+		prop       string
+		value      string
+		want       string
+	}{
+		{"number", "deals", "amount", "1e3", "1000"},
+		{"large integer", "deals", "amount", "12345678901234567890", "12345678901234567890"},
+		{"date from ISO midnight", "deals", "closedate", "2024-03-01T00:00:00Z", "2024-03-01"},
+		{"date from epoch ms", "deals", "closedate", "1709251200000", "2024-03-01"},
+		{"datetime from epoch ms", "meetings", "hs_meeting_start_time", "1709294400000", "2024-03-01T12:00:00.000Z"},
+		{"datetime from offset", "meetings", "hs_meeting_start_time", "2024-03-01T13:00:00+01:00", "2024-03-01T12:00:00.000Z"},
+		{"email", "contacts", "email", "Mixed.Case@Example.com", "mixed.case@example.com"},
+		{"phone", "contacts", "phone", " +1 (555) 010-9999 ext 12 ", "+1 (555) 010-9999 ext 12"},
+		{"enumeration", "contacts", "lifecyclestage", "customer", "customer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]any{"properties": map[string]string{tt.prop: tt.value}})
+			resp, err := http.Post(srv.URL+"/crm/v3/objects/"+tt.objectType, "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("post: %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != http.StatusCreated {
+				t.Fatalf("expected 201, got %d", resp.StatusCode)
+			}
+			var obj domain.Object
+			if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got := obj.Properties[tt.prop]; got != tt.want {
+				t.Errorf("expected %s=%q, got %q", tt.prop, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPropertyValueValidation(t *testing.T) {
+	srv := setupServer(t)
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		objectType string
+		prop       string
+		value      string
+		code       string
+	}{
+		{"number", "deals", "amount", "lots", "INVALID_NUMBER"},
+		{"number NaN", "deals", "amount", "NaN", "INVALID_NUMBER"},
+		{"number infinity", "deals", "amount", "infinity", "INVALID_NUMBER"},
+		{"date not midnight", "deals", "closedate", "2024-03-01T10:00:00Z", "INVALID_DATE"},
+		{"date unparseable", "deals", "closedate", "next tuesday", "INVALID_DATE"},
+		{"datetime unparseable", "meetings", "hs_meeting_start_time", "noon", "INVALID_DATE"},
+		{"enumeration option", "contacts", "lifecyclestage", "prospect", "INVALID_OPTION"},
+		{"email", "contacts", "email", "not-an-email", "INVALID_EMAIL"},
+		{"phone", "contacts", "phone", "call me", "INVALID_PHONE_NUMBER"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]any{"properties": map[string]string{tt.prop: tt.value}})
+			resp, err := http.Post(srv.URL+"/crm/v3/objects/"+tt.objectType, "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("post: %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", resp.StatusCode)
+			}
+			var apiErr api.Error
+			if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(apiErr.Errors) != 1 || apiErr.Errors[0].Code != tt.code {
+				t.Fatalf("expected one %s error, got %+v", tt.code, apiErr.Errors)
+			}
+			if got := apiErr.Errors[0].Context["propertyName"]; len(got) != 1 || got[0] != tt.prop {
+				t.Errorf("expected context.propertyName [%s], got %v", tt.prop, got)
+			}
+		})
+	}
+}
+
+func TestUnknownProperties(t *testing.T) {
+	body := []byte(`{"properties":{"firstname":"Ann","shoe_size":"9"}}`)
+
+	t.Run("rejected by default", func(t *testing.T) {
+		srv := setupServer(t)
+		defer srv.Close()
+
+		resp, err := http.Post(srv.URL+"/crm/v3/objects/contacts", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", resp.StatusCode)
+		}
+		var apiErr api.Error
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(apiErr.Errors) != 1 || apiErr.Errors[0].Code != api.CodePropertyDoesntExist {
+			t.Fatalf("expected one %s error, got %+v", api.CodePropertyDoesntExist, apiErr.Errors)
+		}
+	})
+
+	t.Run("accepted when lenient", func(t *testing.T) {
+		srv := setupServer(t, func(s *store.Store) { s.AllowUnknownProperties = true })
+		defer srv.Close()
+
+		resp, err := http.Post(srv.URL+"/crm/v3/objects/contacts", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201, got %d", resp.StatusCode)
+		}
+		var obj domain.Object
+		if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if obj.Properties["shoe_size"] != "9" {
+			t.Errorf("expected shoe_size 9, got %q", obj.Properties["shoe_size"])
+		}
+	})
+
+	t.Run("internal error when definitions cannot be read", func(t *testing.T) {
+		srv := setupServer(t, func(s *store.Store) { s.Properties = failingPropertyStore{s.Properties} })
+		defer srv.Close()
+
+		resp, err := http.Post(srv.URL+"/crm/v3/objects/contacts", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("expected 500, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// failingPropertyStore is a PropertyStore whose List always fails.
+type failingPropertyStore struct {
+	store.PropertyStore
+}
+
+func (failingPropertyStore) List(context.Context, string) ([]domain.Property, error) {
+	return nil, errors.New("database is locked")
+}
+
+func TestTypedPropertyInputs(t *testing.T) {
+	srv := setupServer(t)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/crm/v3/objects/deals", "application/json",
+		bytes.NewBufferString(`{"properties":{"dealname":"Typed","amount":1500,"closedate":null}}`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	var created domain.Object
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.Properties["amount"] != "1500" {
+		t.Errorf("expected amount 1500, got %q", created.Properties["amount"])
+	}
+
+	req, _ := http.NewRequest(http.MethodPatch, srv.URL+"/crm/v3/objects/deals/"+created.ID,
+		bytes.NewBufferString(`{"properties":{"amount":null}}`))
+	req.Header.Set("Content-Type", "application/json")
+	patchResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	_ = patchResp.Body.Close()
+	if patchResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", patchResp.StatusCode)
+	}
+
+	getResp, err := http.Get(srv.URL + "/crm/v3/objects/deals/" + created.ID + "?propertiesWithHistory=amount")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer func() { _ = getResp.Body.Close() }()
+	var obj domain.Object
+	if err := json.NewDecoder(getResp.Body).Decode(&obj); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	versions := obj.PropertiesWithHistory["amount"]
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 amount versions, got %d", len(versions))
+	}
+	if versions[0].Value != "" || versions[1].Value != "1500" {
+		t.Errorf("expected [\"\" 1500], got [%q %q]", versions[0].Value, versions[1].Value)
+	}
+}
+
+func TestTypedPropertyInputsRejectsObjects(t *testing.T) {
+	srv := setupServer(t)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/crm/v3/objects/deals", "application/json",
+		bytes.NewBufferString(`{"properties":{"dealname":{"text":"Nested"}}}`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
@@ -22,4 +22,5 @@ func RegisterRoutes(mux *http.ServeMux, s *store.Store) {
 	mux.HandleFunc("POST /crm/v3/objects/{objectType}/batch/upsert", h.BatchUpsert)
 	mux.HandleFunc("POST /crm/v3/objects/{objectType}/batch/archive", h.BatchArchive)
 	mux.HandleFunc("POST /crm/v3/objects/{objectType}/merge", h.Merge)
+	mux.HandleFunc("POST /crm/v3/objects/contacts/gdpr-delete", h.GDPRDelete)
 }
@@ -0,0 +1,243 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johnwards/hubspot/internal/api"
+	"github.com/johnwards/hubspot/internal/domain"
+	"github.com/johnwards/hubspot/internal/store"
+)
+
+// HubSpot error codes for invalid property values.
+const (
+	codeInvalidOption  = "INVALID_OPTION"
+	codeInvalidDate    = "INVALID_DATE"
+	codeInvalidNumber  = "INVALID_NUMBER"
+	codeInvalidBoolean = "INVALID_BOOLEAN"
+	codeInvalidEmail   = "INVALID_EMAIL"
+	codeInvalidPhone   = "INVALID_PHONE_NUMBER"
+)
+
+// timestampLayout is the canonical form of datetime values, matching the
+// timestamps the store writes.
+const timestampLayout = "2006-01-02T15:04:05.000Z"
+
+// normalizeProperties validates each map of property values against the
+// object type's definitions and rewrites valid values in place in the
+// canonical form HubSpot returns. Properties without a definition are
+// rejected unless the store allows unknown properties, calculated properties
+// are rejected as read-only, and empty values are left alone. It returns every
+// invalid value found, and an error only if the definitions cannot be read.
+func (h *Handler) normalizeProperties(ctx context.Context, objectType string, inputs ...map[string]string) ([]api.PropertyError, error) {
+	defs, err := h.store.Properties.List(ctx, objectType)
+	if errors.Is(err, store.ErrNotFound) {
+		// Unknown object types are reported by the store call that follows.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]domain.Property, len(defs))
+	for _, p := range defs {
+		byName[p.Name] = p
+	}
+
+	var errs []api.PropertyError
+	for _, props := range inputs {
+		for _, name := range slices.Sorted(maps.Keys(props)) {
+			value := props[name]
+			def, ok := byName[name]
+			if !ok {
+				if !h.store.AllowUnknownProperties {
+					errs = append(errs, api.UnknownPropertyError(name))
+				}
+				continue
+			}
+			if def.Calculated {
+				errs = append(errs, api.PropertyError{
+					Name:    name,
+					Code:    api.CodeReadOnlyValue,
+					Message: fmt.Sprintf("%q is a read only property; its value cannot be set.", name),
+				})
+				continue
+			}
+			if value == "" {
+				continue
+			}
+			normalized, perr := normalizeValue(def, value)
+			if perr != nil {
+				errs = append(errs, *perr)
+				continue
+			}
+			props[name] = normalized
+		}
+	}
+	return errs, nil
+}
+
+// stageValidationError returns HubSpot's 400 error for a write that set a
+// pipeline or stage that is not allowed, or nil if err is not a
+// *store.StageError.
+func stageValidationError(err error, correlationID string) *api.Error {
+	var se *store.StageError
+	if !errors.As(err, &se) {
+		return nil
+	}
+	return api.NewPropertyValidationError([]api.PropertyError{{
+		Name:    se.Property,
+		Code:    codeInvalidOption,
+		Message: se.Error(),
+	}}, correlationID)
+}
+
+// decimalLiteral matches a number written as plain decimal digits.
+var decimalLiteral = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// normalizeValue checks value against a property definition and returns it
+// in canonical form.
+func normalizeValue(def domain.Property, value string) (string, *api.PropertyError) {
+	invalid := func(code, format string, args ...any) (string, *api.PropertyError) {
+		return "", &api.PropertyError{Name: def.Name, Code: code, Message: fmt.Sprintf(format, args...)}
+	}
+
+	switch def.Type {
+	case "number":
+		// Plain decimals are kept as given, so large IDs and amounts are not
+		// rounded to float64 precision.
+		trimmed := strings.TrimSpace(value)
+		if decimalLiteral.MatchString(trimmed) {
+			return trimmed, nil
+		}
+		f, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return invalid(codeInvalidNumber, "%s was not a valid number.", value)
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+
+	case "bool":
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "true":
+			return "true", nil
+		case "false":
+			return "false", nil
+		}
+		return invalid(codeInvalidBoolean, "%s was not a valid boolean (true or false).", value)
+
+	case "enumeration":
+		// Enumerations without options, such as pipeline-driven stages,
+		// accept any value.
+		if len(def.Options) == 0 {
+			return value, nil
+		}
+		allowed := make(map[string]bool, len(def.Options))
+		values := make([]string, len(def.Options))
+		for i, o := range def.Options {
+			allowed[o.Value] = true
+			values[i] = o.Value
+		}
+		parts := []string{value}
+		if def.FieldType == "checkbox" {
+			parts = strings.Split(value, ";")
+		}
+		kept := make([]string, 0, len(parts))
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			if !allowed[part] {
+				return invalid(codeInvalidOption, "%s was not one of the allowed options: [%s]", part, strings.Join(values, ", "))
+			}
+			kept = append(kept, part)
+		}
+		return strings.Join(kept, ";"), nil
+
+	case "date":
+		t, ok := parseTimeValue(value)
+		if !ok {
+			return invalid(codeInvalidDate, "%s is not a valid date.", value)
+		}
+		if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 || t.Nanosecond() != 0 {
+			return invalid(codeInvalidDate, "%s is at %d:%d:%d.%d UTC, not midnight!", value, t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/int(time.Millisecond))
+		}
+		return t.Format(time.DateOnly), nil
+
+	case "datetime":
+		t, ok := parseTimeValue(value)
+		if !ok {
+			return invalid(codeInvalidDate, "%s is not a valid datetime.", value)
+		}
+		return t.Format(timestampLayout), nil
+
+	case "phone_number":
+		return normalizePhone(def, value)
+	}
+
+	switch {
+	case def.FieldType == "phonenumber":
+		return normalizePhone(def, value)
+	case def.FieldType == "email" || def.Name == "email":
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != strings.TrimSpace(value) {
+			return invalid(codeInvalidEmail, "Email address %s is invalid", value)
+		}
+		return strings.ToLower(addr.Address), nil
+	}
+	return value, nil
+}
+
+// normalizePhone accepts digits with the usual separators, a leading "+"
+// and an optional "ext"/"x" extension, and returns the trimmed value.
+func normalizePhone(def domain.Property, value string) (string, *api.PropertyError) {
+	v := strings.TrimSpace(value)
+	invalid := &api.PropertyError{Name: def.Name, Code: codeInvalidPhone, Message: fmt.Sprintf("%s is not a valid phone number.", value)}
+
+	number := v
+	if i := strings.IndexByte(strings.ToLower(v), 'x'); i >= 0 {
+		number = strings.TrimRight(v[:i], "eE")
+		ext := strings.Trim(v[i+1:], "t. ")
+		if ext == "" || strings.Trim(ext, "0123456789") != "" {
+			return "", invalid
+		}
+	}
+
+	digits := 0
+	for i, c := range number {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '+' && i == 0, c == ' ', c == '-', c == '(', c == ')', c == '.':
+		default:
+			return "", invalid
+		}
+	}
+	if digits < 3 {
+		return "", invalid
+	}
+	return v, nil
+}
+
+// parseTimeValue parses an ISO 8601 date or timestamp, or epoch
+// milliseconds, and returns it in UTC.
+func parseTimeValue(value string) (time.Time, bool) {
+	v := strings.TrimSpace(value)
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), true
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", time.DateOnly} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
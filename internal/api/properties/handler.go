@@ -2,7 +2,9 @@ package properties
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -69,6 +71,10 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	created, err := h.store.Create(r.Context(), objectType, &p)
 	if err != nil {
+		if errors.Is(err, store.ErrInvalidFormula) || errors.Is(err, store.ErrInvalidRollup) {
+			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+			return
+		}
 		if isNotFound(err) {
 			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
 			return
@@ -125,11 +131,23 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The body is decoded twice so an explicit null or "" can be told apart
+	// from an omitted field where it clears a definition.
+	var raw map[string]json.RawMessage
 	var patch domain.Property
-	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &raw)
+	}
+	if err == nil {
+		err = json.Unmarshal(body, &patch)
+	}
+	if err != nil {
 		api.WriteError(w, http.StatusBadRequest, api.NewValidationError("Invalid input JSON", corrID, nil))
 		return
 	}
+	_, setFormula := raw["calculationFormula"]
+	_, setRollup := raw["rollup"]
 
 	if patch.Label != "" {
 		existing.Label = patch.Label
@@ -146,9 +164,19 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	existing.DisplayOrder = patch.DisplayOrder
 	existing.Hidden = patch.Hidden
 	existing.FormField = patch.FormField
+	if setFormula {
+		existing.CalculationFormula = patch.CalculationFormula
+	}
+	if setRollup {
+		existing.Rollup = patch.Rollup
+	}
 
 	updated, err := h.store.Update(r.Context(), objectType, name, existing)
 	if err != nil {
+		if errors.Is(err, store.ErrInvalidFormula) || errors.Is(err, store.ErrInvalidRollup) {
+			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+			return
+		}
 		if isNotFound(err) {
 			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError(err.Error(), corrID))
 			return
@@ -197,7 +225,7 @@ func (h *Handler) BatchCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	created, err := h.store.BatchCreate(r.Context(), objectType, input.Inputs)
+	created, errs, err := h.store.BatchCreate(r.Context(), objectType, input.Inputs)
 	if err != nil {
 		if isNotFound(err) {
 			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
@@ -209,11 +237,7 @@ func (h *Handler) BatchCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results := make([]any, len(created))
-	for i := range created {
-		results[i] = created[i]
-	}
-	api.WriteJSON(w, http.StatusCreated, api.CollectionResponse{Results: results})
+	writeBatchResponse(w, http.StatusCreated, created, errs)
 }
 
 type batchReadInput struct {
@@ -238,7 +262,7 @@ func (h *Handler) BatchRead(w http.ResponseWriter, r *http.Request) {
 		names[i] = inp.Name
 	}
 
-	props, err := h.store.BatchRead(r.Context(), objectType, names)
+	props, errs, err := h.store.BatchRead(r.Context(), objectType, names)
 	if err != nil {
 		if isNotFound(err) {
 			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError(err.Error(), corrID))
@@ -250,11 +274,7 @@ func (h *Handler) BatchRead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results := make([]any, len(props))
-	for i := range props {
-		results[i] = props[i]
-	}
-	api.WriteJSON(w, http.StatusOK, api.CollectionResponse{Results: results})
+	writeBatchResponse(w, http.StatusOK, props, errs)
 }
 
 type batchArchiveInput struct {
@@ -279,7 +299,8 @@ func (h *Handler) BatchArchive(w http.ResponseWriter, r *http.Request) {
 		names[i] = inp.Name
 	}
 
-	if err := h.store.BatchArchive(r.Context(), objectType, names); err != nil {
+	errs, err := h.store.BatchArchive(r.Context(), objectType, names)
+	if err != nil {
 		if isNotFound(err) {
 			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError(err.Error(), corrID))
 			return
@@ -290,6 +311,10 @@ func (h *Handler) BatchArchive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(errs) > 0 {
+		writeBatchResponse(w, http.StatusNoContent, []domain.Property{}, errs)
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -438,6 +463,30 @@ func (h *Handler) ArchiveGroup(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// batchResponse is HubSpot's response to a property batch operation.
+type batchResponse struct {
+	Status      string              `json:"status"`
+	Results     []domain.Property   `json:"results"`
+	StartedAt   string              `json:"startedAt"`
+	CompletedAt string              `json:"completedAt"`
+	NumErrors   int                 `json:"numErrors,omitempty"`
+	Errors      []domain.BatchError `json:"errors,omitempty"`
+}
+
+// writeBatchResponse writes a batch response with the given status, or 207
+// Multi-Status with the errors when some inputs failed.
+func writeBatchResponse(w http.ResponseWriter, status int, results []domain.Property, errs []domain.BatchError) {
+	ts := store.Now()
+	api.WriteJSON(w, api.BatchStatus(status, len(errs)), batchResponse{
+		Status:      "COMPLETE",
+		Results:     results,
+		StartedAt:   ts,
+		CompletedAt: ts,
+		NumErrors:   len(errs),
+		Errors:      errs,
+	})
+}
+
 func isNotFound(err error) bool {
 	return strings.Contains(err.Error(), "not found")
 }
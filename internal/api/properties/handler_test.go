@@ -173,6 +173,58 @@ func TestUpdateProperty(t *testing.T) {
 	}
 }
 
+func TestUpdatePropertyRollup(t *testing.T) {
+	srv := setupTestServer(t)
+	defer srv.Close()
+
+	input := map[string]any{
+		"name": "num_contacts", "label": "Contacts", "type": "number",
+		"fieldType": "number", "groupName": "contactinformation",
+	}
+	resp := postJSON(t, srv.URL+"/crm/v3/properties/contacts", input)
+	_ = resp.Body.Close()
+
+	resp = doRequest(t, http.MethodPatch, srv.URL+"/crm/v3/properties/contacts/num_contacts",
+		map[string]any{"rollup": map[string]any{"function": "count", "objectType": "contacts"}})
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		t.Fatalf("update status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var updated map[string]any
+	decodeJSON(t, resp, &updated)
+	rollup, _ := updated["rollup"].(map[string]any)
+	if rollup["function"] != "COUNT" || updated["calculated"] != true {
+		t.Errorf("rollup = %v, calculated = %v; want a calculated COUNT rollup", updated["rollup"], updated["calculated"])
+	}
+
+	resp = doRequest(t, http.MethodPatch, srv.URL+"/crm/v3/properties/contacts/num_contacts",
+		map[string]any{"rollup": map[string]any{"function": "AVG", "objectType": "contacts"}})
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid rollup status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	// A PATCH that omits rollup keeps it; an explicit null removes it.
+	resp = doRequest(t, http.MethodPatch, srv.URL+"/crm/v3/properties/contacts/num_contacts",
+		map[string]any{"label": "Contact count"})
+	updated = nil
+	decodeJSON(t, resp, &updated)
+	if updated["rollup"] == nil {
+		t.Error("expected a PATCH without rollup to keep it")
+	}
+	resp = doRequest(t, http.MethodPatch, srv.URL+"/crm/v3/properties/contacts/num_contacts",
+		map[string]any{"rollup": nil})
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		t.Fatalf("remove status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	updated = nil
+	decodeJSON(t, resp, &updated)
+	if updated["rollup"] != nil || updated["calculated"] != false {
+		t.Errorf("rollup = %v, calculated = %v; want the rollup removed", updated["rollup"], updated["calculated"])
+	}
+}
+
 func TestArchiveProperty(t *testing.T) {
 	srv := setupTestServer(t)
 	defer srv.Close()
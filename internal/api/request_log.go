@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/johnwards/hubspot/internal/store"
+)
+
+// redactedValue replaces secrets in recorded headers and query strings.
+const redactedValue = "[REDACTED]"
+
+// redactedHeaders lists headers whose values carry credentials.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// redactedQueryParams lists query parameters that carry credentials.
+var redactedQueryParams = []string{"hapikey", "access_token"}
+
+// RequestRecorder persists request log entries. store.RequestLogStore
+// satisfies it.
+type RequestRecorder interface {
+	Insert(ctx context.Context, entry *store.RequestLogEntry) error
+}
+
+// RequestLogConfig controls what the RequestLog middleware records.
+type RequestLogConfig struct {
+	// MaxBodyBytes truncates recorded request and response bodies. Zero
+	// disables body capture entirely.
+	MaxBodyBytes int
+	// Exclude lists path prefixes that are not recorded, e.g. "/_ui/".
+	Exclude []string
+}
+
+// excluded reports whether path matches one of the configured prefixes. A
+// prefix ending in "/" also matches the path without the trailing slash.
+func (c RequestLogConfig) excluded(path string) bool {
+	for _, prefix := range c.Exclude {
+		if prefix == "" {
+			continue
+		}
+		if strings.HasPrefix(path, prefix) || path == strings.TrimSuffix(prefix, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// captureWriter wraps http.ResponseWriter to capture the status code and a
+// bounded copy of the response body.
+type captureWriter struct {
+	http.ResponseWriter
+	code    int
+	body    bytes.Buffer
+	maxBody int
+}
+
+// WriteHeader captures the status code and delegates to the wrapped writer.
+func (cw *captureWriter) WriteHeader(code int) {
+	cw.code = code
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+// Write copies up to maxBody bytes of the response and delegates to the
+// wrapped writer.
+func (cw *captureWriter) Write(b []byte) (int, error) {
+	if remaining := cw.maxBody - cw.body.Len(); remaining > 0 {
+		cw.body.Write(b[:min(len(b), remaining)])
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// RequestLog returns middleware that persists every request and its response
+// to the request log. It must run inside RequestID so the correlation ID is
+// available, and outside Recovery so requests that panic are logged with the
+// 500 response Recovery writes. A request body that cannot be read is
+// answered with a 400 rather than passed on truncated.
+func RequestLog(rl RequestRecorder, cfg RequestLogConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.excluded(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+
+			var reqBody []byte
+			var readErr error
+			if cfg.MaxBodyBytes > 0 && r.Body != nil && r.Body != http.NoBody {
+				reqBody, readErr = io.ReadAll(r.Body)
+				_ = r.Body.Close()
+				r.Body = io.NopCloser(bytes.NewReader(reqBody))
+			}
+
+			cw := &captureWriter{ResponseWriter: w, code: http.StatusOK, maxBody: cfg.MaxBodyBytes}
+			if readErr != nil {
+				slog.Warn("read request body for request log", "error", readErr)
+				WriteError(cw, http.StatusBadRequest, NewValidationError(
+					"Could not read request body", CorrelationID(r.Context()), nil))
+			} else {
+				next.ServeHTTP(cw, r)
+			}
+
+			entry := &store.RequestLogEntry{
+				Method:          r.Method,
+				Path:            r.URL.Path,
+				Query:           redactQuery(r.URL.RawQuery),
+				StatusCode:      cw.code,
+				RequestHeaders:  redactHeaders(r.Header),
+				RequestBody:     truncateBody(reqBody, cfg.MaxBodyBytes),
+				ResponseHeaders: redactHeaders(w.Header()),
+				ResponseBody:    cw.body.String(),
+				DurationMs:      time.Since(start).Milliseconds(),
+				CorrelationID:   CorrelationID(r.Context()),
+				CreatedAt:       start.UTC().Format("2006-01-02T15:04:05.000Z"),
+			}
+
+			// The response has been sent; a cancelled client must not lose the entry.
+			if err := rl.Insert(context.WithoutCancel(r.Context()), entry); err != nil {
+				slog.Error("record request log", "error", err, "method", r.Method, "path", r.URL.Path)
+			}
+		})
+	}
+}
+
+// truncateBody returns at most maxBytes of b as a string.
+func truncateBody(b []byte, maxBytes int) string {
+	if len(b) > maxBytes {
+		b = b[:maxBytes]
+	}
+	return string(b)
+}
+
+// redactHeaders returns a copy of h with credential header values masked.
+// The auth scheme of an Authorization header, such as "Bearer", is kept so
+// the log still shows how the client authenticated.
+func redactHeaders(h http.Header) map[string][]string {
+	out := make(map[string][]string, len(h))
+	for k, v := range h {
+		out[k] = append([]string(nil), v...)
+	}
+	for _, name := range redactedHeaders {
+		key := http.CanonicalHeaderKey(name)
+		for i, v := range out[key] {
+			scheme, _, found := strings.Cut(v, " ")
+			if found && strings.HasSuffix(key, "Authorization") {
+				out[key][i] = scheme + " " + redactedValue
+			} else {
+				out[key][i] = redactedValue
+			}
+		}
+	}
+	return out
+}
+
+// redactQuery masks credential query parameters in a raw query string,
+// leaving the order and encoding of the other parameters untouched.
+func redactQuery(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	parts := strings.Split(raw, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(key); err == nil && slices.Contains(redactedQueryParams, name) {
+			parts[i] = key + "=" + redactedValue
+		}
+	}
+	return strings.Join(parts, "&")
+}
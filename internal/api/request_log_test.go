@@ -0,0 +1,159 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johnwards/hubspot/internal/api"
+	"github.com/johnwards/hubspot/internal/store"
+)
+
+// memRequestLog collects entries in memory.
+type memRequestLog struct {
+	entries []*store.RequestLogEntry
+}
+
+func (m *memRequestLog) Insert(_ context.Context, e *store.RequestLogEntry) error {
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func serveLogged(t *testing.T, cfg api.RequestLogConfig, req *http.Request) (*memRequestLog, *httptest.ResponseRecorder) {
+	t.Helper()
+	rl := &memRequestLog{}
+	handler := api.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"echo":` + string(b) + `}`))
+		}),
+		api.RequestID(),
+		api.RequestLog(rl, cfg),
+	)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rl, rec
+}
+
+func TestRequestLogRecordsRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/crm/v3/objects/contacts?hapikey=abc&limit=10", strings.NewReader(`{"a":1}`))
+	req.Header.Set("Authorization", "Bearer secret-token")
+
+	rl, rec := serveLogged(t, api.RequestLogConfig{MaxBodyBytes: 1024}, req)
+
+	if rec.Body.String() != `{"echo":{"a":1}}` {
+		t.Errorf("handler did not see the request body, got %q", rec.Body.String())
+	}
+	if len(rl.entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(rl.entries))
+	}
+	e := rl.entries[0]
+	if e.Method != http.MethodPost || e.Path != "/crm/v3/objects/contacts" {
+		t.Errorf("method/path = %s %s", e.Method, e.Path)
+	}
+	if e.Query != "hapikey=[REDACTED]&limit=10" {
+		t.Errorf("Query = %q", e.Query)
+	}
+	if e.StatusCode != http.StatusCreated {
+		t.Errorf("StatusCode = %d, want %d", e.StatusCode, http.StatusCreated)
+	}
+	if e.RequestBody != `{"a":1}` {
+		t.Errorf("RequestBody = %q", e.RequestBody)
+	}
+	if e.ResponseBody != `{"echo":{"a":1}}` {
+		t.Errorf("ResponseBody = %q", e.ResponseBody)
+	}
+	if got := e.RequestHeaders["Authorization"]; len(got) != 1 || got[0] != "Bearer [REDACTED]" {
+		t.Errorf("Authorization = %v, want redacted", got)
+	}
+	if e.CorrelationID == "" || e.CorrelationID != rec.Header().Get("X-Correlation-Id") {
+		t.Errorf("CorrelationID = %q, header = %q", e.CorrelationID, rec.Header().Get("X-Correlation-Id"))
+	}
+	if e.CreatedAt == "" {
+		t.Error("CreatedAt is empty")
+	}
+}
+
+func TestRequestLogTruncatesBodies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/crm/v3/objects/contacts", strings.NewReader(`"0123456789"`))
+
+	rl, rec := serveLogged(t, api.RequestLogConfig{MaxBodyBytes: 5}, req)
+
+	if rec.Body.String() != `{"echo":"0123456789"}` {
+		t.Errorf("response was truncated for the client: %q", rec.Body.String())
+	}
+	e := rl.entries[0]
+	if e.RequestBody != `"0123` {
+		t.Errorf("RequestBody = %q, want %q", e.RequestBody, `"0123`)
+	}
+	if e.ResponseBody != `{"ech` {
+		t.Errorf("ResponseBody = %q, want %q", e.ResponseBody, `{"ech`)
+	}
+}
+
+func TestRequestLogExcludedPaths(t *testing.T) {
+	cfg := api.RequestLogConfig{MaxBodyBytes: 1024, Exclude: []string{"/_ui/", "/_notspot/"}}
+
+	for _, path := range []string{"/_ui", "/_ui/index.html", "/_notspot/requests"} {
+		rl, _ := serveLogged(t, cfg, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		if len(rl.entries) != 0 {
+			t.Errorf("%s: expected no entries, got %d", path, len(rl.entries))
+		}
+	}
+
+	rl, _ := serveLogged(t, cfg, httptest.NewRequest(http.MethodGet, "/crm/v3/owners", http.NoBody))
+	if len(rl.entries) != 1 {
+		t.Errorf("expected /crm/v3/owners to be logged, got %d entries", len(rl.entries))
+	}
+}
+
+func TestRequestLogRecordsPanics(t *testing.T) {
+	rl := &memRequestLog{}
+	handler := api.Chain(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") }),
+		api.RequestID(),
+		api.RequestLog(rl, api.RequestLogConfig{MaxBodyBytes: 1024}),
+		api.Recovery(),
+	)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/crm/v3/owners", http.NoBody))
+
+	if len(rl.entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(rl.entries))
+	}
+	if e := rl.entries[0]; e.StatusCode != http.StatusInternalServerError || !strings.Contains(e.ResponseBody, "INTERNAL_ERROR") {
+		t.Errorf("entry = %d %q, want the recovered 500", e.StatusCode, e.ResponseBody)
+	}
+}
+
+// failingReader returns some bytes and then an error.
+type failingReader struct{ sent bool }
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	if f.sent {
+		return 0, errors.New("connection reset")
+	}
+	f.sent = true
+	return copy(p, `{"par`), nil
+}
+
+func TestRequestLogUnreadableBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/crm/v3/objects/contacts", &failingReader{})
+
+	rl, rec := serveLogged(t, api.RequestLogConfig{MaxBodyBytes: 1024}, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if strings.Contains(rec.Body.String(), "echo") {
+		t.Errorf("handler was called with a truncated body: %q", rec.Body.String())
+	}
+	if len(rl.entries) != 1 || rl.entries[0].StatusCode != http.StatusBadRequest {
+		t.Errorf("expected the 400 to be logged, got %d entries", len(rl.entries))
+	}
+}
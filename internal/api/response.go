@@ -31,3 +31,12 @@ type CollectionResponse struct {
 	Results []any   `json:"results"`
 	Paging  *Paging `json:"paging,omitempty"`
 }
+
+// BatchStatus returns the status code for a batch response: status when
+// every input succeeded, or 207 Multi-Status when numErrors inputs failed.
+func BatchStatus(status, numErrors int) int {
+	if numErrors > 0 {
+		return http.StatusMultiStatus
+	}
+	return status
+}
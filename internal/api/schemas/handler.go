@@ -2,6 +2,7 @@ package schemas
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -61,6 +62,10 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 			api.WriteError(w, http.StatusConflict, api.NewConflictError(err.Error(), corrID))
 			return
 		}
+		if isValidation(err) {
+			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+			return
+		}
 		api.WriteError(w, http.StatusInternalServerError, &api.Error{
 			Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR",
 		})
@@ -107,6 +112,10 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 			api.WriteError(w, http.StatusNotFound, api.NewNotFoundError(err.Error(), corrID))
 			return
 		}
+		if isValidation(err) {
+			api.WriteError(w, http.StatusBadRequest, api.NewValidationError(err.Error(), corrID, nil))
+			return
+		}
 		api.WriteError(w, http.StatusInternalServerError, &api.Error{
 			Status: "error", Message: err.Error(), CorrelationID: corrID, Category: "INTERNAL_ERROR",
 		})
@@ -199,3 +208,8 @@ func isDuplicate(err error) bool {
 	return strings.Contains(err.Error(), "UNIQUE constraint failed") ||
 		strings.Contains(err.Error(), "already exists")
 }
+
+func isValidation(err error) bool {
+	var validationErr *store.ValidationError
+	return errors.As(err, &validationErr)
+}
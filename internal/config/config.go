@@ -1,12 +1,24 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Config holds application configuration loaded from environment variables.
 type Config struct {
 	Addr      string // NOTSPOT_ADDR, default ":8080"
 	DBPath    string // NOTSPOT_DB, default "notspot.db"
 	AuthToken string // NOTSPOT_AUTH_TOKEN, optional
+
+	RequestLogMaxBody int      // NOTSPOT_REQUEST_LOG_MAX_BODY, default 65536; 0 disables body capture
+	RequestLogExclude []string // NOTSPOT_REQUEST_LOG_EXCLUDE, comma-separated path prefixes, default "/_ui/,/_notspot/"
+
+	LenientProperties bool // NOTSPOT_LENIENT_PROPERTIES, accept writes to undefined properties, default false
+
+	ArchiveRetention time.Duration // NOTSPOT_ARCHIVE_RETENTION, how long archived objects survive a purge, default 90 days
 }
 
 // Load reads configuration from environment variables with sensible defaults.
@@ -15,6 +27,13 @@ func Load() Config {
 		Addr:      envOr("NOTSPOT_ADDR", ":8080"),
 		DBPath:    envOr("NOTSPOT_DB", "notspot.db"),
 		AuthToken: os.Getenv("NOTSPOT_AUTH_TOKEN"),
+
+		RequestLogMaxBody: envInt("NOTSPOT_REQUEST_LOG_MAX_BODY", 64*1024),
+		RequestLogExclude: splitList(envOr("NOTSPOT_REQUEST_LOG_EXCLUDE", "/_ui/,/_notspot/")),
+
+		LenientProperties: envBool("NOTSPOT_LENIENT_PROPERTIES", false),
+
+		ArchiveRetention: envDuration("NOTSPOT_ARCHIVE_RETENTION", 90*24*time.Hour),
 	}
 }
 
@@ -24,3 +43,34 @@ func envOr(key, fallback string) string {
 	}
 	return fallback
 }
+
+func envInt(key string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n >= 0 {
+		return n
+	}
+	return fallback
+}
+
+func envBool(key string, fallback bool) bool {
+	if b, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return b
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d >= 0 {
+		return d
+	}
+	return fallback
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
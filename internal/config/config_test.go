@@ -1,7 +1,9 @@
 package config_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/johnwards/hubspot/internal/config"
 )
@@ -11,6 +13,10 @@ func TestLoadDefaults(t *testing.T) {
 	t.Setenv("NOTSPOT_ADDR", "")
 	t.Setenv("NOTSPOT_DB", "")
 	t.Setenv("NOTSPOT_AUTH_TOKEN", "")
+	t.Setenv("NOTSPOT_REQUEST_LOG_MAX_BODY", "")
+	t.Setenv("NOTSPOT_REQUEST_LOG_EXCLUDE", "")
+	t.Setenv("NOTSPOT_LENIENT_PROPERTIES", "")
+	t.Setenv("NOTSPOT_ARCHIVE_RETENTION", "")
 
 	cfg := config.Load()
 
@@ -23,12 +29,28 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.AuthToken != "" {
 		t.Errorf("AuthToken = %q, want empty", cfg.AuthToken)
 	}
+	if cfg.RequestLogMaxBody != 65536 {
+		t.Errorf("RequestLogMaxBody = %d, want 65536", cfg.RequestLogMaxBody)
+	}
+	if got := strings.Join(cfg.RequestLogExclude, ","); got != "/_ui/,/_notspot/" {
+		t.Errorf("RequestLogExclude = %q, want %q", got, "/_ui/,/_notspot/")
+	}
+	if cfg.LenientProperties {
+		t.Error("LenientProperties = true, want false")
+	}
+	if cfg.ArchiveRetention != 90*24*time.Hour {
+		t.Errorf("ArchiveRetention = %v, want %v", cfg.ArchiveRetention, 90*24*time.Hour)
+	}
 }
 
 func TestLoadFromEnv(t *testing.T) {
 	t.Setenv("NOTSPOT_ADDR", ":9090")
 	t.Setenv("NOTSPOT_DB", "/tmp/test.db")
 	t.Setenv("NOTSPOT_AUTH_TOKEN", "secret-token")
+	t.Setenv("NOTSPOT_REQUEST_LOG_MAX_BODY", "0")
+	t.Setenv("NOTSPOT_REQUEST_LOG_EXCLUDE", " /_ui/ ,,/health")
+	t.Setenv("NOTSPOT_LENIENT_PROPERTIES", "true")
+	t.Setenv("NOTSPOT_ARCHIVE_RETENTION", "36h")
 
 	cfg := config.Load()
 
@@ -41,4 +63,16 @@ func TestLoadFromEnv(t *testing.T) {
 	if cfg.AuthToken != "secret-token" {
 		t.Errorf("AuthToken = %q, want %q", cfg.AuthToken, "secret-token")
 	}
+	if cfg.RequestLogMaxBody != 0 {
+		t.Errorf("RequestLogMaxBody = %d, want 0", cfg.RequestLogMaxBody)
+	}
+	if got := strings.Join(cfg.RequestLogExclude, ","); got != "/_ui/,/health" {
+		t.Errorf("RequestLogExclude = %q, want %q", got, "/_ui/,/health")
+	}
+	if !cfg.LenientProperties {
+		t.Error("LenientProperties = false, want true")
+	}
+	if cfg.ArchiveRetention != 36*time.Hour {
+		t.Errorf("ArchiveRetention = %v, want %v", cfg.ArchiveRetention, 36*time.Hour)
+	}
 }
@@ -219,4 +219,61 @@ var migrations = [][]string{
 		)`,
 		`CREATE INDEX idx_request_log_time ON request_log(created_at)`,
 	},
+
+	// Migration 2: request log query string and headers
+	{
+		`ALTER TABLE request_log ADD COLUMN query TEXT`,
+		`ALTER TABLE request_log ADD COLUMN request_headers TEXT`,
+		`ALTER TABLE request_log ADD COLUMN response_headers TEXT`,
+		`CREATE INDEX idx_request_log_correlation ON request_log(correlation_id)`,
+	},
+
+	// Migration 3: request expectations
+	{
+		`CREATE TABLE expectations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			spec TEXT NOT NULL,
+			created_at TEXT NOT NULL
+		)`,
+	},
+
+	// Migration 4: change source label and user on property writes
+	{
+		`ALTER TABLE property_values ADD COLUMN source_label TEXT`,
+		`ALTER TABLE property_values ADD COLUMN updated_by_user_id INTEGER`,
+		`ALTER TABLE property_value_history ADD COLUMN source_label TEXT`,
+		`ALTER TABLE property_value_history ADD COLUMN updated_by_user_id INTEGER`,
+	},
+
+	// Migration 5: emails of GDPR-deleted contacts
+	{
+		`CREATE TABLE gdpr_deleted_emails (
+			email TEXT PRIMARY KEY,
+			deleted_at TEXT NOT NULL
+		)`,
+	},
+
+	// Migration 6: rollup property definitions
+	{
+		`ALTER TABLE property_definitions ADD COLUMN rollup TEXT`,
+	},
+
+	// Migration 7: full-text index of property values, keyed by the rowid of
+	// the property_values row
+	{
+		`CREATE VIRTUAL TABLE property_values_fts USING fts5(
+			value,
+			property_name UNINDEXED,
+			object_id UNINDEXED,
+			tokenize = 'unicode61 remove_diacritics 2'
+		)`,
+		`INSERT INTO property_values_fts (rowid, value, property_name, object_id)
+		 SELECT rowid, value, property_name, object_id FROM property_values WHERE value != ''`,
+	},
+
+	// Migration 8: properties searched by the query field of each object
+	// type, as a JSON array
+	{
+		`ALTER TABLE object_types ADD COLUMN searchable_properties TEXT`,
+	},
 }
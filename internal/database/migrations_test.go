@@ -35,6 +35,9 @@ func TestMigrationsCreateAllTables(t *testing.T) {
 		"exports",
 		"owners",
 		"request_log",
+		"expectations",
+		"gdpr_deleted_emails",
+		"property_values_fts",
 	}
 
 	for _, table := range tables {
@@ -62,8 +65,8 @@ func TestMigrationsIdempotent(t *testing.T) {
 	if err != nil {
 		t.Fatalf("query version: %v", err)
 	}
-	if version != 1 {
-		t.Errorf("version = %d, want 1", version)
+	if version != 8 {
+		t.Errorf("version = %d, want 8", version)
 	}
 }
 
@@ -83,6 +86,7 @@ func TestMigrationsIndexes(t *testing.T) {
 		"idx_assoc_from",
 		"idx_assoc_to",
 		"idx_request_log_time",
+		"idx_request_log_correlation",
 	}
 
 	for _, idx := range indexes {
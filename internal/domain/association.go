@@ -19,3 +19,46 @@ type AssociationLabel struct {
 	TypeID   int    `json:"typeId"`
 	Label    string `json:"label"`
 }
+
+// AssociatedObject is one entry in an object's inline associations block. An
+// object associated under several types appears once per type.
+type AssociatedObject struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// ObjectAssociations lists an object's associated records of one type.
+type ObjectAssociations struct {
+	Results []AssociatedObject `json:"results"`
+	Paging  *AssociationPaging `json:"paging,omitempty"`
+}
+
+// AssociationPaging holds pagination info for inline associations.
+type AssociationPaging struct {
+	Next AssociationPagingNext `json:"next"`
+}
+
+// AssociationPagingNext points at the rest of an object's associations of
+// one type, listed by the v4 associations endpoint.
+type AssociationPagingNext struct {
+	After string `json:"after"`
+	Link  string `json:"link"`
+}
+
+// ObjectAssociationInput associates a new object with an existing one in a
+// create or batch/create request.
+type ObjectAssociationInput struct {
+	To    AssociationTarget      `json:"to"`
+	Types []AssociationTypeInput `json:"types"`
+}
+
+// AssociationTarget identifies the existing object to associate with.
+type AssociationTarget struct {
+	ID string `json:"id"`
+}
+
+// AssociationTypeInput names an association type in a request.
+type AssociationTypeInput struct {
+	AssociationCategory string `json:"associationCategory"`
+	AssociationTypeID   int    `json:"associationTypeId"`
+}
@@ -1,31 +1,95 @@
 package domain
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
 // Object represents a CRM object (contact, company, deal, etc.).
 type Object struct {
-	ID         string            `json:"id"`
-	Properties map[string]string `json:"properties"`
-	CreatedAt  string            `json:"createdAt"`
-	UpdatedAt  string            `json:"updatedAt"`
-	Archived   bool              `json:"archived"`
-	ArchivedAt string            `json:"archivedAt,omitempty"`
+	ID                    string                         `json:"id"`
+	Properties            map[string]string              `json:"properties"`
+	PropertiesWithHistory map[string][]PropertyHistory   `json:"propertiesWithHistory,omitempty"`
+	Associations          map[string]*ObjectAssociations `json:"associations,omitempty"`
+	CreatedAt             string                         `json:"createdAt"`
+	UpdatedAt             string                         `json:"updatedAt"`
+	Archived              bool                           `json:"archived"`
+	ArchivedAt            string                         `json:"archivedAt,omitempty"`
+	// New is set on batch upsert results and reports whether the upsert
+	// created the object rather than updating an existing one.
+	New *bool `json:"new,omitempty"`
+}
+
+// PropertyHistory is one historical value of a property, newest first in
+// responses.
+type PropertyHistory struct {
+	Value           string `json:"value"`
+	Timestamp       string `json:"timestamp"`
+	SourceType      string `json:"sourceType"`
+	SourceID        string `json:"sourceId,omitempty"`
+	SourceLabel     string `json:"sourceLabel,omitempty"`
+	UpdatedByUserID int    `json:"updatedByUserId,omitempty"`
+}
+
+// PropertyValues is a set of property values from a request body. HubSpot
+// clients send values as JSON strings, numbers or booleans; they are stored in
+// their string form. A null value clears the property, like the empty string.
+type PropertyValues map[string]string
+
+// UnmarshalJSON decodes an object of loosely typed property values.
+func (p *PropertyValues) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*p = nil
+		return nil
+	}
+	values := make(PropertyValues, len(raw))
+	for name, v := range raw {
+		v = bytes.TrimSpace(v)
+		switch {
+		case bytes.Equal(v, []byte("null")):
+			values[name] = ""
+		case len(v) > 0 && v[0] == '"':
+			var s string
+			if err := json.Unmarshal(v, &s); err != nil {
+				return err
+			}
+			values[name] = s
+		case bytes.Equal(v, []byte("true")), bytes.Equal(v, []byte("false")):
+			values[name] = string(v)
+		default:
+			var n json.Number
+			if err := json.Unmarshal(v, &n); err != nil {
+				return fmt.Errorf("property %q: value must be a string, number, boolean or null", name)
+			}
+			values[name] = n.String()
+		}
+	}
+	*p = values
+	return nil
 }
 
 // CreateInput holds the data needed to create a new object.
 type CreateInput struct {
-	Properties map[string]string `json:"properties"`
+	Properties   PropertyValues           `json:"properties"`
+	Associations []ObjectAssociationInput `json:"associations,omitempty"`
 }
 
 // UpdateInput holds the data needed to update an existing object.
 type UpdateInput struct {
-	ID         string            `json:"id"`
-	Properties map[string]string `json:"properties"`
+	ID         string         `json:"id"`
+	Properties PropertyValues `json:"properties"`
 }
 
 // UpsertInput holds the data for an upsert operation.
 type UpsertInput struct {
-	ID         string            `json:"id,omitempty"`
-	IDProperty string            `json:"idProperty,omitempty"`
-	Properties map[string]string `json:"properties"`
+	ID         string         `json:"id,omitempty"`
+	IDProperty string         `json:"idProperty,omitempty"`
+	Properties PropertyValues `json:"properties"`
 }
 
 // ListOpts holds the parameters for listing objects.
@@ -45,9 +109,18 @@ type ObjectPage struct {
 
 // BatchResult wraps the result of a batch operation.
 type BatchResult struct {
-	Status      string    `json:"status"`
-	Results     []*Object `json:"results"`
-	StartedAt   string    `json:"startedAt"`
-	CompletedAt string    `json:"completedAt"`
-	NumErrors   int       `json:"numErrors,omitempty"`
+	Status      string       `json:"status"`
+	Results     []*Object    `json:"results"`
+	StartedAt   string       `json:"startedAt"`
+	CompletedAt string       `json:"completedAt"`
+	NumErrors   int          `json:"numErrors,omitempty"`
+	Errors      []BatchError `json:"errors,omitempty"`
+}
+
+// BatchError describes one input that failed in a batch operation.
+type BatchError struct {
+	Status   string              `json:"status"`
+	Category string              `json:"category"`
+	Message  string              `json:"message"`
+	Context  map[string][]string `json:"context,omitempty"`
 }
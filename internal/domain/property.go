@@ -14,6 +14,8 @@ type Property struct {
 	Hidden               bool                  `json:"hidden"`
 	FormField            bool                  `json:"formField"`
 	Calculated           bool                  `json:"calculated"`
+	CalculationFormula   string                `json:"calculationFormula,omitempty"`
+	Rollup               *Rollup               `json:"rollup,omitempty"`
 	ExternalOptions      bool                  `json:"externalOptions"`
 	Archived             bool                  `json:"archived"`
 	HubspotDefined       bool                  `json:"hubspotDefined"`
@@ -23,6 +25,24 @@ type Property struct {
 	ModificationMetadata *ModificationMetadata `json:"modificationMetadata,omitempty"`
 }
 
+// Rollup defines a property whose value is aggregated from a property of the
+// associated objects of one type.
+type Rollup struct {
+	Function         string         `json:"function"`                   // COUNT, SUM, MIN, MAX or LATEST
+	ObjectType       string         `json:"objectType"`                 // associated object type name or ID
+	PropertyName     string         `json:"propertyName,omitempty"`     // aggregated property; unused by COUNT
+	SortPropertyName string         `json:"sortPropertyName,omitempty"` // LATEST takes the object with the greatest value
+	Filters          []RollupFilter `json:"filters,omitempty"`          // associated objects must match every filter
+}
+
+// RollupFilter restricts the associated objects a rollup aggregates.
+type RollupFilter struct {
+	PropertyName string   `json:"propertyName"`
+	Operator     string   `json:"operator"` // EQ, NEQ, IN, NOT_IN, HAS_PROPERTY or NOT_HAS_PROPERTY
+	Value        string   `json:"value,omitempty"`
+	Values       []string `json:"values,omitempty"`
+}
+
 // Option represents a selectable option for enumeration properties.
 type Option struct {
 	Label        string `json:"label"`
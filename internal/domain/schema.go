@@ -6,6 +6,7 @@ type ObjectSchema struct {
 	Name                   string              `json:"name"`
 	Labels                 SchemaLabels        `json:"labels"`
 	PrimaryDisplayProperty string              `json:"primaryDisplayProperty"`
+	SearchableProperties   []string            `json:"searchableProperties"`
 	Properties             []Property          `json:"properties"`
 	Associations           []SchemaAssociation `json:"associations"`
 	AssociatedObjects      []string            `json:"associatedObjects,omitempty"`
@@ -0,0 +1,20 @@
+package domain
+
+// Change source types recorded against property writes.
+const (
+	SourceAPI        = "API"
+	SourceImport     = "IMPORT"
+	SourceMerge      = "MERGE"
+	SourceCRMUI      = "CRM_UI"
+	SourceCalculated = "CALCULATED"
+)
+
+// ChangeSource identifies who or what made a property change. It is recorded
+// with every property value and surfaces as sourceType, sourceId, sourceLabel
+// and updatedByUserId in propertiesWithHistory.
+type ChangeSource struct {
+	Type   string
+	ID     string
+	Label  string
+	UserID int
+}
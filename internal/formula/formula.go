@@ -0,0 +1,445 @@
+// Package formula parses and evaluates the formulas of HubSpot calculation
+// properties.
+//
+// A formula is an expression over other properties of the same object:
+//
+//	amount * (1 - discount / 100)
+//	if is_present(closedate) then time_between(createdate, closedate) else 0 endif
+//	concatenate(firstname, " ", lastname)
+//
+// Values are nil (an unset property), float64, string or bool. Dates and
+// datetimes are milliseconds since the Unix epoch, so date math is ordinary
+// arithmetic.
+package formula
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Env resolves a property name referenced by a formula to its value.
+type Env func(name string) any
+
+// Expr is a parsed formula.
+type Expr struct {
+	root node
+}
+
+// Parse parses a formula. It reports syntax errors and calls to unknown
+// functions or with the wrong number of arguments.
+func Parse(src string) (*Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	return &Expr{root: root}, nil
+}
+
+// Eval evaluates the formula against env. A nil result means the value is
+// unset, for example because an operand was empty or a division was by zero.
+func (e *Expr) Eval(env Env) (any, error) {
+	return e.root.eval(env)
+}
+
+type node interface {
+	eval(env Env) (any, error)
+}
+
+type literal struct{ v any }
+
+func (n literal) eval(Env) (any, error) { return n.v, nil }
+
+type ident struct{ name string }
+
+func (n ident) eval(env Env) (any, error) { return env(n.name), nil }
+
+type unary struct {
+	op string
+	x  node
+}
+
+func (n unary) eval(env Env) (any, error) {
+	v, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "not" {
+		return !truthy(v), nil
+	}
+	if v == nil {
+		return nil, nil
+	}
+	f, err := number(v)
+	if err != nil {
+		return nil, err
+	}
+	return -f, nil
+}
+
+type binary struct {
+	op   string
+	l, r node
+}
+
+func (n binary) eval(env Env) (any, error) {
+	l, err := n.l.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	// and/or short-circuit.
+	switch n.op {
+	case "and":
+		if !truthy(l) {
+			return false, nil
+		}
+		r, err := n.r.eval(env)
+		return truthy(r), err
+	case "or":
+		if truthy(l) {
+			return true, nil
+		}
+		r, err := n.r.eval(env)
+		return truthy(r), err
+	}
+
+	r, err := n.r.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "=":
+		return equal(l, r), nil
+	case "!=":
+		return !equal(l, r), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, l, r)
+	}
+
+	if n.op == "+" {
+		_, ls := l.(string)
+		_, rs := r.(string)
+		if ls || rs {
+			return String(l) + String(r), nil
+		}
+	}
+	if l == nil || r == nil {
+		return nil, nil
+	}
+	a, err := number(l)
+	if err != nil {
+		return nil, err
+	}
+	b, err := number(r)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, nil
+		}
+		return a / b, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", n.op)
+}
+
+// branch is one "if"/"elseif" arm of a conditional.
+type branch struct {
+	cond, then node
+}
+
+type conditional struct {
+	branches  []branch
+	otherwise node // nil when there is no else
+}
+
+func (n conditional) eval(env Env) (any, error) {
+	for _, b := range n.branches {
+		c, err := b.cond.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(c) {
+			return b.then.eval(env)
+		}
+	}
+	if n.otherwise == nil {
+		return nil, nil
+	}
+	return n.otherwise.eval(env)
+}
+
+type call struct {
+	fn   function
+	args []node
+}
+
+func (n call) eval(env Env) (any, error) {
+	args := make([]any, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return n.fn.impl(args)
+}
+
+// function is a built-in formula function. max is -1 for variadic functions.
+type function struct {
+	min, max int
+	impl     func(args []any) (any, error)
+}
+
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"concatenate":      {1, -1, concatenate},
+		"concat":           {1, -1, concatenate},
+		"round":            {1, 2, rounder(math.Round)},
+		"round_nearest":    {1, 2, rounder(math.Round)},
+		"round_up":         {1, 2, rounder(math.Ceil)},
+		"round_down":       {1, 2, rounder(math.Floor)},
+		"abs":              {1, 1, numeric(math.Abs)},
+		"sqrt":             {1, 1, numeric(math.Sqrt)},
+		"power":            {2, 2, power},
+		"max":              {1, -1, extreme(math.Max)},
+		"min":              {1, -1, extreme(math.Min)},
+		"is_present":       {1, 1, isPresent},
+		"contains":         {2, 2, contains},
+		"number_to_string": {1, 1, numberToString},
+		"string_to_number": {1, 1, stringToNumber},
+		"now":              {0, 0, nowMillis},
+		"time_between":     {2, 2, timeBetween},
+	}
+}
+
+func concatenate(args []any) (any, error) {
+	var sb strings.Builder
+	for _, a := range args {
+		sb.WriteString(String(a))
+	}
+	return sb.String(), nil
+}
+
+// rounder returns a function rounding its first argument to the number of
+// decimal places given by the optional second argument.
+func rounder(round func(float64) float64) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		x, err := number(args[0])
+		if err != nil {
+			return nil, err
+		}
+		places := 0.0
+		if len(args) == 2 && args[1] != nil {
+			if places, err = number(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		scale := math.Pow(10, math.Trunc(places))
+		return round(x*scale) / scale, nil
+	}
+}
+
+func numeric(fn func(float64) float64) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		x, err := number(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return fn(x), nil
+	}
+}
+
+func power(args []any) (any, error) {
+	if args[0] == nil || args[1] == nil {
+		return nil, nil
+	}
+	x, err := number(args[0])
+	if err != nil {
+		return nil, err
+	}
+	y, err := number(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return math.Pow(x, y), nil
+}
+
+// extreme returns a function folding its non-empty arguments with pick.
+func extreme(pick func(a, b float64) float64) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		var result any
+		for _, a := range args {
+			if a == nil {
+				continue
+			}
+			x, err := number(a)
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				result = x
+			} else {
+				result = pick(result.(float64), x)
+			}
+		}
+		return result, nil
+	}
+}
+
+func isPresent(args []any) (any, error) {
+	return args[0] != nil && args[0] != "", nil
+}
+
+func contains(args []any) (any, error) {
+	return strings.Contains(String(args[0]), String(args[1])), nil
+}
+
+func numberToString(args []any) (any, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	return String(args[0]), nil
+}
+
+func stringToNumber(args []any) (any, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(String(args[0])), 64)
+	if err != nil {
+		return nil, nil
+	}
+	return f, nil
+}
+
+func nowMillis([]any) (any, error) {
+	return float64(time.Now().UnixMilli()), nil
+}
+
+// timeBetween returns the milliseconds from its first to its second argument.
+func timeBetween(args []any) (any, error) {
+	if args[0] == nil || args[1] == nil {
+		return nil, nil
+	}
+	from, err := number(args[0])
+	if err != nil {
+		return nil, err
+	}
+	to, err := number(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return to - from, nil
+}
+
+// truthy reports whether v counts as true in a condition. Unset values,
+// zero and empty strings are false.
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	}
+	return false
+}
+
+func number(v any) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case string:
+		return 0, fmt.Errorf("%q is not a number", v)
+	}
+	return 0, fmt.Errorf("%v is not a number", v)
+}
+
+// String formats a value as text. Unset values are empty.
+func String(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+func equal(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a == b
+}
+
+// compare orders two numbers or two strings. Comparisons with an unset value
+// are false.
+func compare(op string, a, b any) (any, error) {
+	if a == nil || b == nil {
+		return false, nil
+	}
+	var c int
+	switch a := a.(type) {
+	case float64:
+		b, err := number(b)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case a < b:
+			c = -1
+		case a > b:
+			c = 1
+		}
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %q with a non-string", a)
+		}
+		c = strings.Compare(a, b)
+	default:
+		return nil, fmt.Errorf("cannot order %v", a)
+	}
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
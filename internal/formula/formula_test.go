@@ -0,0 +1,94 @@
+package formula_test
+
+import (
+	"testing"
+
+	"github.com/johnwards/hubspot/internal/formula"
+)
+
+func TestEval(t *testing.T) {
+	env := formula.Env(func(name string) any {
+		return map[string]any{
+			"amount":     1000.0,
+			"discount":   10.0,
+			"firstname":  "Ada",
+			"lastname":   "Lovelace",
+			"createdate": 1704067200000.0, // 2024-01-01
+			"closedate":  1704931200000.0, // 2024-01-11
+			"won":        true,
+		}[name]
+	})
+
+	tests := []struct {
+		formula string
+		want    any
+	}{
+		{"amount * (1 - discount / 100)", 900.0},
+		{"-amount + 2 * 3", -994.0},
+		{"amount / 0", nil},
+		{"missing * 2", nil},
+		{"concatenate(firstname, ' ', lastname)", "Ada Lovelace"},
+		{"concat(firstname, missing)", "Ada"},
+		{`firstname + " " + lastname`, "Ada Lovelace"},
+		{"round(10 / 3, 2)", 3.33},
+		{"round_up(2.1)", 3.0},
+		{"round_down(2.9)", 2.0},
+		{"max(1, missing, 7, 3)", 7.0},
+		{"is_present(firstname)", true},
+		{"is_present(missing)", false},
+		{"not is_present(missing) and won", true},
+		{"time_between(createdate, closedate) / 86400000", 10.0},
+		{"closedate - createdate > 0", true},
+		{"if amount > 500 then 'large' elseif amount > 100 then 'medium' else 'small' endif", "large"},
+		{"IF (discount >= 10) AND won THEN 1 ELSE 0 ENDIF", 1.0},
+		{"if(amount = 1000, 'yes', 'no')", "yes"},
+		{"if(missing, 'yes')", nil},
+		{"[amount] <> 5", true},
+		{"contains(lastname, 'Love')", true},
+		{"string_to_number('42') + 1", 43.0},
+		{"number_to_string(amount)", "1000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.formula, func(t *testing.T) {
+			expr, err := formula.Parse(tt.formula)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got, err := expr.Eval(env)
+			if err != nil {
+				t.Fatalf("eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalTypeError(t *testing.T) {
+	expr, err := formula.Parse("firstname * 2")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := expr.Eval(func(string) any { return "Ada" }); err == nil {
+		t.Error("expected an error multiplying a string")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"amount *",
+		"(amount",
+		"'unterminated",
+		"unknown_fn(1)",
+		"round()",
+		"if amount then 1",
+		"amount $ 2",
+		"then",
+	} {
+		if _, err := formula.Parse(src); err == nil {
+			t.Errorf("Parse(%q): expected an error", src)
+		}
+	}
+}
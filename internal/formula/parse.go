@@ -0,0 +1,410 @@
+package formula
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// keywords are matched case-insensitively and cannot name properties.
+var keywords = map[string]bool{
+	"if": true, "then": true, "elseif": true, "else": true, "endif": true,
+	"and": true, "or": true, "not": true, "true": true, "false": true,
+}
+
+// lex splits src into tokens. Property names may be written bare or in
+// square brackets, e.g. [deal amount].
+func lex(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			toks = append(toks, token{tokNumber, src[start:i], start})
+
+		case c == '"' || c == '\'':
+			start := i
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			toks = append(toks, token{tokString, src[i+1 : i+1+end], start})
+			i += end + 2
+
+		case c == '[':
+			start := i
+			end := strings.IndexByte(src[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated property name at position %d", start)
+			}
+			toks = append(toks, token{tokIdent, strings.TrimSpace(src[i+1 : i+end]), start})
+			i += end + 1
+
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] == '.' || src[i] >= 'a' && src[i] <= 'z' ||
+				src[i] >= 'A' && src[i] <= 'Z' || src[i] >= '0' && src[i] <= '9') {
+				i++
+			}
+			toks = append(toks, token{tokIdent, src[start:i], start})
+
+		case c == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case c == ',':
+			toks = append(toks, token{tokComma, ",", i})
+			i++
+
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<>", "<=", ">=", "&&", "||", "+", "-", "*", "/", "<", ">", "=", "!"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			toks = append(toks, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{tokEOF, "end of formula", len(src)}), nil
+}
+
+// parser is a recursive descent parser over the tokens of one formula. In
+// order of increasing precedence the grammar is:
+//
+//	expr    = and { ("or" | "||") and }
+//	and     = not { ("and" | "&&") not }
+//	not     = ("not" | "!") not | compare
+//	compare = sum [ ("=" | "==" | "!=" | "<>" | "<" | "<=" | ">" | ">=") sum ]
+//	sum     = product { ("+" | "-") product }
+//	product = unary { ("*" | "/") unary }
+//	unary   = "-" unary | primary
+//	primary = number | string | "true" | "false" | name | name "(" args ")"
+//	        | "(" expr ")" | "if" expr "then" expr { "elseif" expr "then" expr } [ "else" expr ] "endif"
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword reports whether the next token is the keyword kw.
+func (p *parser) keyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && strings.EqualFold(t.text, kw)
+}
+
+// op consumes the next token if it is one of ops and returns its canonical
+// spelling.
+func (p *parser) op(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp && t.kind != tokIdent {
+		return "", false
+	}
+	for _, op := range ops {
+		if strings.EqualFold(t.text, op) {
+			p.next()
+			return canonicalOps[strings.ToLower(op)], true
+		}
+	}
+	return "", false
+}
+
+var canonicalOps = map[string]string{
+	"or": "or", "||": "or", "and": "and", "&&": "and", "not": "not", "!": "not",
+	"=": "=", "==": "=", "!=": "!=", "<>": "!=", "<": "<", "<=": "<=", ">": ">", ">=": ">=",
+	"+": "+", "-": "-", "*": "*", "/": "/",
+}
+
+func (p *parser) expect(kw string) error {
+	if !p.keyword(kw) {
+		t := p.peek()
+		return fmt.Errorf("expected %q but found %q at position %d", kw, t.text, t.pos)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) parseExpr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.op("or", "||")
+		if !ok {
+			return l, nil
+		}
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op, l, r}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.op("and", "&&")
+		if !ok {
+			return l, nil
+		}
+		r, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op, l, r}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if op, ok := p.op("not", "!"); ok {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return unary{op, x}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error) {
+	l, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.op("==", "!=", "<>", "<=", ">=", "=", "<", ">")
+	if !ok {
+		return l, nil
+	}
+	r, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	return binary{op, l, r}, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	l, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.op("+", "-")
+		if !ok {
+			return l, nil
+		}
+		r, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op, l, r}
+	}
+}
+
+func (p *parser) parseProduct() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.op("*", "/")
+		if !ok {
+			return l, nil
+		}
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op, l, r}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.op("-"); ok {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unary{op, x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return literal{f}, nil
+
+	case tokString:
+		return literal{t.text}, nil
+
+	case tokLParen:
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, fmt.Errorf("expected \")\" at position %d", p.peek().pos)
+		}
+		p.next()
+		return x, nil
+
+	case tokIdent:
+		name := strings.ToLower(t.text)
+		switch {
+		case name == "true" || name == "false":
+			return literal{name == "true"}, nil
+		case name == "if":
+			return p.parseIf()
+		case keywords[name]:
+			return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+		case p.peek().kind == tokLParen:
+			return p.parseCall(t)
+		}
+		return ident{t.text}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+// parseArgs parses a parenthesised, comma-separated argument list.
+func (p *parser) parseArgs() ([]node, error) {
+	if p.peek().kind != tokLParen {
+		return nil, fmt.Errorf("expected \"(\" at position %d", p.peek().pos)
+	}
+	p.next()
+	var args []node
+	if p.peek().kind == tokRParen {
+		p.next()
+		return args, nil
+	}
+	for {
+		a, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+		switch t := p.next(); t.kind {
+		case tokComma:
+		case tokRParen:
+			return args, nil
+		default:
+			return nil, fmt.Errorf("expected \",\" or \")\" but found %q at position %d", t.text, t.pos)
+		}
+	}
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[strings.ToLower(name.text)]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+	}
+	args, err := p.parseArgs()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < fn.min || fn.max >= 0 && len(args) > fn.max {
+		return nil, fmt.Errorf("wrong number of arguments to %s at position %d", name.text, name.pos)
+	}
+	return call{fn, args}, nil
+}
+
+// parseIf parses a conditional after its "if": either the function form
+// if(cond, then[, else]) or the keyword form ending in "endif".
+func (p *parser) parseIf() (node, error) {
+	if p.peek().kind == tokLParen {
+		start := p.pos
+		args, err := p.parseArgs()
+		if err == nil && (len(args) == 2 || len(args) == 3) && !p.keyword("then") {
+			n := conditional{branches: []branch{{args[0], args[1]}}}
+			if len(args) == 3 {
+				n.otherwise = args[2]
+			}
+			return n, nil
+		}
+		// A parenthesised condition of the keyword form.
+		p.pos = start
+	}
+
+	var n conditional
+	for {
+		cond, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("then"); err != nil {
+			return nil, err
+		}
+		then, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		n.branches = append(n.branches, branch{cond, then})
+		if !p.keyword("elseif") {
+			break
+		}
+		p.next()
+	}
+	if p.keyword("else") {
+		p.next()
+		otherwise, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		n.otherwise = otherwise
+	}
+	if err := p.expect("endif"); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
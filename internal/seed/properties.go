@@ -10,23 +10,25 @@ import (
 	"github.com/johnwards/hubspot/internal/domain"
 )
 
-// defaultObjectTypes defines the standard HubSpot object types with their IDs.
+// defaultObjectTypes defines the standard HubSpot object types with their IDs
+// and the properties the query field of a search matches.
 var defaultObjectTypes = []struct {
 	ID            string
 	Name          string
 	LabelSingular string
 	LabelPlural   string
 	PrimaryProp   string
+	Searchable    []string
 }{
-	{"0-1", "contacts", "Contact", "Contacts", "email"},
-	{"0-2", "companies", "Company", "Companies", "name"},
-	{"0-3", "deals", "Deal", "Deals", "dealname"},
-	{"0-5", "tickets", "Ticket", "Tickets", "subject"},
-	{"0-27", "tasks", "Task", "Tasks", "hs_task_subject"},
-	{"0-46", "notes", "Note", "Notes", "hs_note_body"},
-	{"0-47", "meetings", "Meeting", "Meetings", "hs_meeting_title"},
-	{"0-48", "calls", "Call", "Calls", "hs_call_body"},
-	{"0-49", "emails", "Email", "Emails", "hs_email_subject"},
+	{"0-1", "contacts", "Contact", "Contacts", "email", []string{"firstname", "lastname", "email", "phone", "company", "hs_object_id"}},
+	{"0-2", "companies", "Company", "Companies", "name", []string{"name", "domain", "website", "phone", "hs_object_id"}},
+	{"0-3", "deals", "Deal", "Deals", "dealname", []string{"dealname", "hs_object_id"}},
+	{"0-5", "tickets", "Ticket", "Tickets", "subject", []string{"subject", "content", "hs_object_id"}},
+	{"0-27", "tasks", "Task", "Tasks", "hs_task_subject", []string{"hs_task_subject", "hs_task_body", "hs_object_id"}},
+	{"0-46", "notes", "Note", "Notes", "hs_note_body", []string{"hs_note_body", "hs_object_id"}},
+	{"0-47", "meetings", "Meeting", "Meetings", "hs_meeting_title", []string{"hs_meeting_title", "hs_object_id"}},
+	{"0-48", "calls", "Call", "Calls", "hs_call_body", []string{"hs_call_body", "hs_object_id"}},
+	{"0-49", "emails", "Email", "Emails", "hs_email_subject", []string{"hs_email_subject", "hs_email_text", "hs_object_id"}},
 }
 
 type propDef struct {
@@ -36,8 +38,51 @@ type propDef struct {
 	FieldType      string
 	GroupName      string
 	HasUniqueValue bool
+	Options        []domain.Option
+	Rollup         *domain.Rollup
+	Calculated     bool // derived by the store and read-only to clients
 }
 
+// lifecycleStageOptions are HubSpot's default lifecycle stages, shared by
+// contacts and companies.
+var lifecycleStageOptions = []domain.Option{
+	{Label: "Subscriber", Value: "subscriber", DisplayOrder: 0},
+	{Label: "Lead", Value: "lead", DisplayOrder: 1},
+	{Label: "Marketing Qualified Lead", Value: "marketingqualifiedlead", DisplayOrder: 2},
+	{Label: "Sales Qualified Lead", Value: "salesqualifiedlead", DisplayOrder: 3},
+	{Label: "Opportunity", Value: "opportunity", DisplayOrder: 4},
+	{Label: "Customer", Value: "customer", DisplayOrder: 5},
+	{Label: "Evangelist", Value: "evangelist", DisplayOrder: 6},
+	{Label: "Other", Value: "other", DisplayOrder: 7},
+}
+
+// legalBasisOptions are HubSpot's lawful bases for processing a contact's
+// data under GDPR.
+var legalBasisOptions = []domain.Option{
+	{Label: "Legitimate interest – prospect/lead", Value: "Legitimate interest – prospect/lead", DisplayOrder: 0},
+	{Label: "Legitimate interest – existing customer", Value: "Legitimate interest – existing customer", DisplayOrder: 1},
+	{Label: "Legitimate interest - other", Value: "Legitimate interest - other", DisplayOrder: 2},
+	{Label: "Performance of a contract", Value: "Performance of a contract", DisplayOrder: 3},
+	{Label: "Freely given consent from contact", Value: "Freely given consent from contact", DisplayOrder: 4},
+	{Label: "Not applicable", Value: "Not applicable", DisplayOrder: 5},
+}
+
+// Rollups of associated deals and contacts that HubSpot maintains on
+// contacts, companies and deals.
+var (
+	numAssociatedContacts = &domain.Rollup{Function: "COUNT", ObjectType: "contacts"}
+	numAssociatedDeals    = &domain.Rollup{Function: "COUNT", ObjectType: "deals"}
+	numOpenDeals          = &domain.Rollup{Function: "COUNT", ObjectType: "deals", Filters: []domain.RollupFilter{
+		{PropertyName: "hs_is_closed", Operator: "NEQ", Value: "true"},
+	}}
+	totalRevenue = &domain.Rollup{Function: "SUM", ObjectType: "deals", PropertyName: "amount", Filters: []domain.RollupFilter{
+		{PropertyName: "hs_is_closed_won", Operator: "EQ", Value: "true"},
+	}}
+	recentDealAmount = &domain.Rollup{Function: "LATEST", ObjectType: "deals", PropertyName: "amount", SortPropertyName: "closedate", Filters: []domain.RollupFilter{
+		{PropertyName: "hs_is_closed_won", Operator: "EQ", Value: "true"},
+	}}
+)
+
 var commonProps = []propDef{
 	{Name: "hs_object_id", Label: "Object ID", Type: "number", FieldType: "number"},
 	{Name: "hs_createdate", Label: "Create date", Type: "datetime", FieldType: "date"},
@@ -51,15 +96,25 @@ var objectProps = map[string][]propDef{
 		{Name: "lastname", Label: "Last Name", Type: "string", FieldType: "text", GroupName: "contactinformation"},
 		{Name: "phone", Label: "Phone Number", Type: "string", FieldType: "phonenumber", GroupName: "contactinformation"},
 		{Name: "company", Label: "Company Name", Type: "string", FieldType: "text", GroupName: "contactinformation"},
-		{Name: "lifecyclestage", Label: "Lifecycle Stage", Type: "enumeration", FieldType: "radio", GroupName: "contactinformation"},
+		{Name: "annualrevenue", Label: "Annual Revenue", Type: "number", FieldType: "number", GroupName: "contactinformation"},
+		{Name: "lifecyclestage", Label: "Lifecycle Stage", Type: "enumeration", FieldType: "radio", GroupName: "contactinformation", Options: lifecycleStageOptions},
 		{Name: "hubspot_owner_id", Label: "Owner", Type: "string", FieldType: "text", GroupName: "contactinformation"},
+		{Name: "hs_legal_basis", Label: "Legal basis for processing contact's data", Type: "enumeration", FieldType: "checkbox", GroupName: "contactinformation", Options: legalBasisOptions},
+		{Name: "num_associated_deals", Label: "Number of Associated Deals", Type: "number", FieldType: "number", GroupName: "contactinformation", Rollup: numAssociatedDeals},
+		{Name: "total_revenue", Label: "Total Revenue", Type: "number", FieldType: "number", GroupName: "contactinformation", Rollup: totalRevenue},
+		{Name: "recent_deal_amount", Label: "Recent Deal Amount", Type: "number", FieldType: "number", GroupName: "contactinformation", Rollup: recentDealAmount},
 	},
 	"0-2": {
 		{Name: "name", Label: "Name", Type: "string", FieldType: "text", GroupName: "companyinformation"},
 		{Name: "domain", Label: "Company Domain Name", Type: "string", FieldType: "text", GroupName: "companyinformation", HasUniqueValue: true},
 		{Name: "industry", Label: "Industry", Type: "enumeration", FieldType: "select", GroupName: "companyinformation"},
-		{Name: "lifecyclestage", Label: "Lifecycle Stage", Type: "enumeration", FieldType: "radio", GroupName: "companyinformation"},
+		{Name: "lifecyclestage", Label: "Lifecycle Stage", Type: "enumeration", FieldType: "radio", GroupName: "companyinformation", Options: lifecycleStageOptions},
 		{Name: "hubspot_owner_id", Label: "Owner", Type: "string", FieldType: "text", GroupName: "companyinformation"},
+		{Name: "num_associated_contacts", Label: "Number of Associated Contacts", Type: "number", FieldType: "number", GroupName: "companyinformation", Rollup: numAssociatedContacts},
+		{Name: "num_associated_deals", Label: "Number of Associated Deals", Type: "number", FieldType: "number", GroupName: "companyinformation", Rollup: numAssociatedDeals},
+		{Name: "hs_num_open_deals", Label: "Number of open deals", Type: "number", FieldType: "number", GroupName: "companyinformation", Rollup: numOpenDeals},
+		{Name: "total_revenue", Label: "Total Revenue", Type: "number", FieldType: "number", GroupName: "companyinformation", Rollup: totalRevenue},
+		{Name: "recent_deal_amount", Label: "Recent Deal Amount", Type: "number", FieldType: "number", GroupName: "companyinformation", Rollup: recentDealAmount},
 	},
 	"0-3": {
 		{Name: "dealname", Label: "Deal Name", Type: "string", FieldType: "text", GroupName: "dealinformation"},
@@ -68,19 +123,37 @@ var objectProps = map[string][]propDef{
 		{Name: "amount", Label: "Amount", Type: "number", FieldType: "number", GroupName: "dealinformation"},
 		{Name: "closedate", Label: "Close Date", Type: "date", FieldType: "date", GroupName: "dealinformation"},
 		{Name: "hubspot_owner_id", Label: "Owner", Type: "string", FieldType: "text", GroupName: "dealinformation"},
+		{Name: "num_associated_contacts", Label: "Number of Associated Contacts", Type: "number", FieldType: "number", GroupName: "dealinformation", Rollup: numAssociatedContacts},
+		{Name: "hs_is_closed", Label: "Is Deal Closed?", Type: "bool", FieldType: "booleancheckbox", GroupName: "dealinformation", Calculated: true},
+		{Name: "hs_is_closed_won", Label: "Is Closed Won", Type: "bool", FieldType: "booleancheckbox", GroupName: "dealinformation", Calculated: true},
+		{Name: "hs_deal_stage_probability", Label: "Deal probability", Type: "number", FieldType: "number", GroupName: "dealinformation", Calculated: true},
+		{Name: "hs_forecast_amount", Label: "Forecast amount", Type: "number", FieldType: "number", GroupName: "dealinformation", Calculated: true},
 	},
 	"0-5": {
 		{Name: "subject", Label: "Ticket Name", Type: "string", FieldType: "text", GroupName: "ticketinformation"},
 		{Name: "content", Label: "Ticket Description", Type: "string", FieldType: "textarea", GroupName: "ticketinformation"},
 		{Name: "hs_pipeline", Label: "Pipeline", Type: "enumeration", FieldType: "radio", GroupName: "ticketinformation"},
 		{Name: "hs_pipeline_stage", Label: "Ticket Status", Type: "enumeration", FieldType: "radio", GroupName: "ticketinformation"},
-		{Name: "hs_ticket_priority", Label: "Priority", Type: "enumeration", FieldType: "select", GroupName: "ticketinformation"},
+		{Name: "hs_ticket_priority", Label: "Priority", Type: "enumeration", FieldType: "select", GroupName: "ticketinformation", Options: []domain.Option{
+			{Label: "Low", Value: "LOW", DisplayOrder: 0},
+			{Label: "Medium", Value: "MEDIUM", DisplayOrder: 1},
+			{Label: "High", Value: "HIGH", DisplayOrder: 2},
+			{Label: "Urgent", Value: "URGENT", DisplayOrder: 3},
+		}},
 		{Name: "hubspot_owner_id", Label: "Owner", Type: "string", FieldType: "text", GroupName: "ticketinformation"},
+		{Name: "closed_date", Label: "Close date", Type: "datetime", FieldType: "date", GroupName: "ticketinformation"},
+		{Name: "time_to_close", Label: "Time to close", Type: "number", FieldType: "number", GroupName: "ticketinformation", Calculated: true},
 	},
 	"0-27": {
 		{Name: "hs_task_subject", Label: "Task Title", Type: "string", FieldType: "text", GroupName: "engagement_info"},
 		{Name: "hs_task_body", Label: "Task Notes", Type: "string", FieldType: "textarea", GroupName: "engagement_info"},
-		{Name: "hs_task_status", Label: "Task Status", Type: "enumeration", FieldType: "select", GroupName: "engagement_info"},
+		{Name: "hs_task_status", Label: "Task Status", Type: "enumeration", FieldType: "select", GroupName: "engagement_info", Options: []domain.Option{
+			{Label: "Not Started", Value: "NOT_STARTED", DisplayOrder: 0},
+			{Label: "In Progress", Value: "IN_PROGRESS", DisplayOrder: 1},
+			{Label: "Waiting", Value: "WAITING", DisplayOrder: 2},
+			{Label: "Completed", Value: "COMPLETED", DisplayOrder: 3},
+			{Label: "Deferred", Value: "DEFERRED", DisplayOrder: 4},
+		}},
 	},
 	"0-46": {
 		{Name: "hs_note_body", Label: "Note Body", Type: "string", FieldType: "textarea", GroupName: "engagement_info"},
@@ -92,7 +165,10 @@ var objectProps = map[string][]propDef{
 	},
 	"0-48": {
 		{Name: "hs_call_body", Label: "Call Notes", Type: "string", FieldType: "textarea", GroupName: "engagement_info"},
-		{Name: "hs_call_direction", Label: "Call Direction", Type: "enumeration", FieldType: "select", GroupName: "engagement_info"},
+		{Name: "hs_call_direction", Label: "Call Direction", Type: "enumeration", FieldType: "select", GroupName: "engagement_info", Options: []domain.Option{
+			{Label: "Inbound", Value: "INBOUND", DisplayOrder: 0},
+			{Label: "Outbound", Value: "OUTBOUND", DisplayOrder: 1},
+		}},
 		{Name: "hs_call_duration", Label: "Call Duration", Type: "number", FieldType: "number", GroupName: "engagement_info"},
 	},
 	"0-49": {
@@ -130,6 +206,20 @@ func Properties(ctx context.Context, db *sql.DB) error {
 		if err != nil {
 			return fmt.Errorf("seed object type %s: %w", ot.Name, err)
 		}
+
+		// Types seeded before searchable properties were stored get theirs
+		// here; lists already set are left alone.
+		searchable, err := json.Marshal(ot.Searchable)
+		if err != nil {
+			return fmt.Errorf("marshal searchable properties for %s: %w", ot.Name, err)
+		}
+		_, err = db.ExecContext(ctx,
+			`UPDATE object_types SET searchable_properties = ? WHERE id = ? AND searchable_properties IS NULL`,
+			string(searchable), ot.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("seed searchable properties for %s: %w", ot.Name, err)
+		}
 	}
 
 	for typeID, grp := range defaultGroups {
@@ -166,15 +256,31 @@ func Properties(ctx context.Context, db *sql.DB) error {
 		}
 
 		for _, p := range objectProps[ot.ID] {
+			propOpts := optsStr
+			if len(p.Options) > 0 {
+				b, err := json.Marshal(p.Options)
+				if err != nil {
+					return fmt.Errorf("encode options for %s: %w", p.Name, err)
+				}
+				propOpts = string(b)
+			}
+			var rollup any
+			if p.Rollup != nil {
+				b, err := json.Marshal(p.Rollup)
+				if err != nil {
+					return fmt.Errorf("encode rollup for %s: %w", p.Name, err)
+				}
+				rollup = string(b)
+			}
 			_, err := db.ExecContext(ctx,
 				`INSERT OR IGNORE INTO property_definitions (
 					object_type_id, name, label, type, field_type, group_name,
 					description, display_order, has_unique_value, hidden, form_field,
-					calculated, external_options, hubspot_defined, options,
+					calculated, external_options, hubspot_defined, options, rollup,
 					archived, created_at, updated_at
-				) VALUES (?, ?, ?, ?, ?, ?, '', 0, ?, FALSE, FALSE, FALSE, FALSE, TRUE, ?, FALSE, ?, ?)`,
+				) VALUES (?, ?, ?, ?, ?, ?, '', 0, ?, FALSE, FALSE, ?, FALSE, TRUE, ?, ?, FALSE, ?, ?)`,
 				ot.ID, p.Name, p.Label, p.Type, p.FieldType, p.GroupName,
-				p.HasUniqueValue, optsStr, ts, ts,
+				p.HasUniqueValue, p.Calculated || p.Rollup != nil, propOpts, rollup, ts, ts,
 			)
 			if err != nil {
 				return fmt.Errorf("seed property %s for %s: %w", p.Name, ot.Name, err)
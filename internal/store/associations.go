@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/johnwards/hubspot/internal/domain"
 )
@@ -13,14 +15,15 @@ type AssociationStore interface {
 	AssociateDefault(ctx context.Context, fromType, fromID, toType, toID string) (*DefaultAssocResult, error)
 	AssociateWithLabels(ctx context.Context, fromType, fromID, toType, toID string, types []AssociationInput) (*DefaultAssocResult, error)
 	GetAssociations(ctx context.Context, fromType, fromID, toType string) ([]domain.AssociationResult, error)
+	ListAssociatedObjects(ctx context.Context, fromType, fromID, toType string, includeArchived bool) ([]domain.AssociatedObject, error)
 	RemoveAssociations(ctx context.Context, fromType, fromID, toType, toID string) error
 	ListLabels(ctx context.Context, fromType, toType string) ([]domain.AssociationLabel, error)
 	CreateLabel(ctx context.Context, fromType, toType, label, category string) (*domain.AssociationLabel, error)
 	UpdateLabel(ctx context.Context, fromType, toType string, typeID int, label string) (*domain.AssociationLabel, error)
 	DeleteLabel(ctx context.Context, fromType, toType string, typeID int) error
-	BatchAssociateDefault(ctx context.Context, fromType, toType string, inputs []BatchAssocInput) ([]BatchDefaultAssocResult, error)
-	BatchCreate(ctx context.Context, fromType, toType string, inputs []BatchAssocCreateInput) ([]BatchCreateResult, error)
-	BatchRead(ctx context.Context, fromType, toType string, inputs []BatchAssocReadInput) ([]BatchAssocResult, error)
+	BatchAssociateDefault(ctx context.Context, fromType, toType string, inputs []BatchAssocInput) ([]BatchDefaultAssocResult, []domain.BatchError, error)
+	BatchCreate(ctx context.Context, fromType, toType string, inputs []BatchAssocCreateInput) ([]BatchCreateResult, []domain.BatchError, error)
+	BatchRead(ctx context.Context, fromType, toType string, inputs []BatchAssocReadInput) ([]BatchAssocResult, []domain.BatchError, error)
 	BatchArchive(ctx context.Context, fromType, toType string, inputs []BatchArchiveInput) error
 	BatchArchiveLabels(ctx context.Context, fromType, toType string, inputs []BatchArchiveLabelInput) error
 }
@@ -117,9 +120,9 @@ func (s *SQLiteAssociationStore) resolveType(ctx context.Context, objectType str
 	return typeID, nil
 }
 
-func (s *SQLiteAssociationStore) getDefaultTypeID(ctx context.Context, fromType, toType string) (int, error) {
+func getDefaultTypeID(ctx context.Context, ex execer, fromType, toType string) (int, error) {
 	var typeID int
-	err := s.db.QueryRowContext(ctx,
+	err := ex.QueryRowContext(ctx,
 		`SELECT id FROM association_types WHERE from_object_type = ? AND to_object_type = ? AND category = 'HUBSPOT_DEFINED' AND (label IS NULL OR label = '') ORDER BY id ASC LIMIT 1`,
 		fromType, toType,
 	).Scan(&typeID)
@@ -129,20 +132,25 @@ func (s *SQLiteAssociationStore) getDefaultTypeID(ctx context.Context, fromType,
 	return typeID, nil
 }
 
-func (s *SQLiteAssociationStore) createReverseAssociation(ctx context.Context, fromTypeID, fromID, toTypeID, toID, ts string) {
-	reverseTypeID, err := s.getDefaultTypeID(ctx, fromTypeID, toTypeID)
+// createReverseAssociation mirrors an association with the default type in
+// the other direction, when one exists.
+func createReverseAssociation(ctx context.Context, ex execer, fromTypeID, fromID, toTypeID, toID, ts string) error {
+	reverseTypeID, err := getDefaultTypeID(ctx, ex, fromTypeID, toTypeID)
 	if err != nil {
-		return
+		return nil
 	}
-	_, _ = s.db.ExecContext(ctx,
+	if _, err := ex.ExecContext(ctx,
 		`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
 		fromID, toID, reverseTypeID, ts,
-	)
+	); err != nil {
+		return fmt.Errorf("create reverse association: %w", err)
+	}
+	return nil
 }
 
-func (s *SQLiteAssociationStore) objectExists(ctx context.Context, objectID string) bool {
+func objectExists(ctx context.Context, ex execer, objectID string) bool {
 	var exists int
-	err := s.db.QueryRowContext(ctx, `SELECT 1 FROM objects WHERE id = ? AND archived = FALSE`, objectID).Scan(&exists)
+	err := ex.QueryRowContext(ctx, `SELECT 1 FROM objects WHERE id = ? AND archived = FALSE`, objectID).Scan(&exists)
 	return err == nil
 }
 
@@ -156,25 +164,35 @@ func (s *SQLiteAssociationStore) AssociateDefault(ctx context.Context, fromType,
 	if err != nil {
 		return nil, err
 	}
-	if !s.objectExists(ctx, fromID) {
-		return nil, fmt.Errorf("object %s not found: %w", fromID, ErrNotFound)
-	}
-	if !s.objectExists(ctx, toID) {
-		return nil, fmt.Errorf("object %s not found: %w", toID, ErrNotFound)
-	}
-	assocTypeID, err := s.getDefaultTypeID(ctx, fromTypeID, toTypeID)
+
+	var assocTypeID int
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if !objectExists(ctx, tx, fromID) {
+			return fmt.Errorf("object %s not found: %w", fromID, ErrNotFound)
+		}
+		if !objectExists(ctx, tx, toID) {
+			return fmt.Errorf("object %s not found: %w", toID, ErrNotFound)
+		}
+		var err error
+		assocTypeID, err = getDefaultTypeID(ctx, tx, fromTypeID, toTypeID)
+		if err != nil {
+			return err
+		}
+		ts := now()
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
+			fromID, toID, assocTypeID, ts,
+		); err != nil {
+			return fmt.Errorf("create default association: %w", err)
+		}
+		if err := createReverseAssociation(ctx, tx, toTypeID, toID, fromTypeID, fromID, ts); err != nil {
+			return err
+		}
+		return recomputeRollups(ctx, tx, fromID, toID)
+	})
 	if err != nil {
 		return nil, err
 	}
-	ts := now()
-	_, err = s.db.ExecContext(ctx,
-		`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
-		fromID, toID, assocTypeID, ts,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("create default association: %w", err)
-	}
-	s.createReverseAssociation(ctx, toTypeID, toID, fromTypeID, fromID, ts)
 	return &DefaultAssocResult{Category: "HUBSPOT_DEFINED", TypeID: assocTypeID}, nil
 }
 
@@ -188,35 +206,47 @@ func (s *SQLiteAssociationStore) AssociateWithLabels(ctx context.Context, fromTy
 	if err != nil {
 		return nil, err
 	}
-	if !s.objectExists(ctx, fromID) {
-		return nil, fmt.Errorf("object %s not found: %w", fromID, ErrNotFound)
-	}
-	if !s.objectExists(ctx, toID) {
-		return nil, fmt.Errorf("object %s not found: %w", toID, ErrNotFound)
-	}
-	ts := now()
-	defaultTypeID, err := s.getDefaultTypeID(ctx, fromTypeID, toTypeID)
-	if err == nil {
-		_, _ = s.db.ExecContext(ctx,
-			`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
-			fromID, toID, defaultTypeID, ts,
-		)
-	}
-	for _, t := range types {
-		var exists int
-		err := s.db.QueryRowContext(ctx, `SELECT 1 FROM association_types WHERE id = ?`, t.AssociationTypeID).Scan(&exists)
-		if err != nil {
-			return nil, fmt.Errorf("association type %d not found: %w", t.AssociationTypeID, ErrNotFound)
+
+	var defaultTypeID int
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if !objectExists(ctx, tx, fromID) {
+			return fmt.Errorf("object %s not found: %w", fromID, ErrNotFound)
 		}
-		_, err = s.db.ExecContext(ctx,
-			`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
-			fromID, toID, t.AssociationTypeID, ts,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("create labeled association: %w", err)
+		if !objectExists(ctx, tx, toID) {
+			return fmt.Errorf("object %s not found: %w", toID, ErrNotFound)
 		}
+		ts := now()
+		var err error
+		defaultTypeID, err = getDefaultTypeID(ctx, tx, fromTypeID, toTypeID)
+		if err == nil {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
+				fromID, toID, defaultTypeID, ts,
+			); err != nil {
+				return fmt.Errorf("create default association: %w", err)
+			}
+		}
+		for _, t := range types {
+			var exists int
+			err := tx.QueryRowContext(ctx, `SELECT 1 FROM association_types WHERE id = ?`, t.AssociationTypeID).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("association type %d not found: %w", t.AssociationTypeID, ErrNotFound)
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
+				fromID, toID, t.AssociationTypeID, ts,
+			); err != nil {
+				return fmt.Errorf("create labeled association: %w", err)
+			}
+		}
+		if err := createReverseAssociation(ctx, tx, toTypeID, toID, fromTypeID, fromID, ts); err != nil {
+			return err
+		}
+		return recomputeRollups(ctx, tx, fromID, toID)
+	})
+	if err != nil {
+		return nil, err
 	}
-	s.createReverseAssociation(ctx, toTypeID, toID, fromTypeID, fromID, ts)
 	category := "HUBSPOT_DEFINED"
 	typeID := defaultTypeID
 	if len(types) > 0 {
@@ -239,6 +269,67 @@ func (s *SQLiteAssociationStore) GetAssociations(ctx context.Context, fromType,
 	return s.getAssocResults(ctx, fromTypeID, fromID, toTypeID)
 }
 
+// ListAssociatedObjects returns the objects of toType associated with an
+// object, one entry per association type, in the shape used by inline
+// associations on object reads. Archived targets are skipped unless
+// includeArchived is set.
+func (s *SQLiteAssociationStore) ListAssociatedObjects(ctx context.Context, fromType, fromID, toType string, includeArchived bool) ([]domain.AssociatedObject, error) {
+	fromTypeID, err := s.resolveType(ctx, fromType)
+	if err != nil {
+		return nil, err
+	}
+	toTypeID, err := s.resolveType(ctx, toType)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT a.to_object_id, COALESCE(at.label, ''), fot.label_singular, tot.label_singular
+		FROM associations a
+		JOIN association_types at ON at.id = a.association_type_id
+		JOIN objects o ON o.id = a.to_object_id
+		JOIN object_types fot ON fot.id = at.from_object_type
+		JOIN object_types tot ON tot.id = at.to_object_type
+		WHERE a.from_object_id = ? AND at.from_object_type = ? AND at.to_object_type = ?`
+	if !includeArchived {
+		query += ` AND o.archived = FALSE`
+	}
+	query += ` ORDER BY a.to_object_id, at.id`
+
+	rows, err := s.db.QueryContext(ctx, query, fromID, fromTypeID, toTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("list associated objects: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []domain.AssociatedObject{}
+	for rows.Next() {
+		var toID, label, fromLabel, toLabel string
+		if err := rows.Scan(&toID, &label, &fromLabel, &toLabel); err != nil {
+			return nil, fmt.Errorf("scan associated object: %w", err)
+		}
+		results = append(results, domain.AssociatedObject{ID: toID, Type: associationTypeName(fromLabel, toLabel, label)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return results, nil
+}
+
+// associationTypeName builds a HubSpot-style association type name such as
+// "contact_to_company" from the singular object labels, suffixed with the
+// association label when there is one.
+func associationTypeName(fromLabel, toLabel, label string) string {
+	name := snakeCase(fromLabel) + "_to_" + snakeCase(toLabel)
+	if label != "" {
+		name += "_" + snakeCase(label)
+	}
+	return name
+}
+
+func snakeCase(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), "_")
+}
+
 // RemoveAssociations deletes all associations between two specific objects.
 func (s *SQLiteAssociationStore) RemoveAssociations(ctx context.Context, fromType, fromID, toType, toID string) error {
 	fromTypeID, err := s.resolveType(ctx, fromType)
@@ -249,14 +340,15 @@ func (s *SQLiteAssociationStore) RemoveAssociations(ctx context.Context, fromTyp
 	if err != nil {
 		return err
 	}
-	_, err = s.db.ExecContext(ctx,
-		`DELETE FROM associations WHERE from_object_id = ? AND to_object_id = ? AND association_type_id IN (SELECT id FROM association_types WHERE from_object_type = ? AND to_object_type = ?)`,
-		fromID, toID, fromTypeID, toTypeID,
-	)
-	if err != nil {
-		return fmt.Errorf("remove associations: %w", err)
-	}
-	return nil
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM associations WHERE from_object_id = ? AND to_object_id = ? AND association_type_id IN (SELECT id FROM association_types WHERE from_object_type = ? AND to_object_type = ?)`,
+			fromID, toID, fromTypeID, toTypeID,
+		); err != nil {
+			return fmt.Errorf("remove associations: %w", err)
+		}
+		return recomputeRollups(ctx, tx, fromID, toID)
+	})
 }
 
 // ListLabels returns all association type labels between two object types.
@@ -354,121 +446,247 @@ func (s *SQLiteAssociationStore) DeleteLabel(ctx context.Context, fromType, toTy
 	if err != nil {
 		return err
 	}
-	_, err = s.db.ExecContext(ctx, `DELETE FROM associations WHERE association_type_id = ?`, typeID)
-	if err != nil {
-		return fmt.Errorf("remove associations for type: %w", err)
-	}
-	res, err := s.db.ExecContext(ctx, `DELETE FROM association_types WHERE id = ?`, typeID)
-	if err != nil {
-		return fmt.Errorf("delete label: %w", err)
-	}
-	n, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("rows affected: %w", err)
-	}
-	if n == 0 {
-		return fmt.Errorf("association type %d: %w", typeID, ErrNotFound)
-	}
-	return nil
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var affected []string
+		rows, err := tx.QueryContext(ctx,
+			`SELECT from_object_id FROM associations WHERE association_type_id = ?
+			 UNION SELECT to_object_id FROM associations WHERE association_type_id = ?`, typeID, typeID)
+		if err != nil {
+			return fmt.Errorf("find associations for type: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("scan association: %w", err)
+			}
+			affected = append(affected, id)
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("find associations for type: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM associations WHERE association_type_id = ?`, typeID); err != nil {
+			return fmt.Errorf("remove associations for type: %w", err)
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM association_types WHERE id = ?`, typeID)
+		if err != nil {
+			return fmt.Errorf("delete label: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("rows affected: %w", err)
+		}
+		if n == 0 {
+			return fmt.Errorf("association type %d: %w", typeID, ErrNotFound)
+		}
+		return recomputeRollups(ctx, tx, affected...)
+	})
 }
 
-// BatchAssociateDefault creates default associations for multiple object pairs.
-func (s *SQLiteAssociationStore) BatchAssociateDefault(ctx context.Context, fromType, toType string, inputs []BatchAssocInput) ([]BatchDefaultAssocResult, error) {
+// BatchAssociateDefault creates default associations for multiple object
+// pairs in one transaction. Pairs that refer to a missing object are skipped
+// and reported as errors.
+func (s *SQLiteAssociationStore) BatchAssociateDefault(ctx context.Context, fromType, toType string, inputs []BatchAssocInput) ([]BatchDefaultAssocResult, []domain.BatchError, error) {
 	fromTypeID, err := s.resolveType(ctx, fromType)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	toTypeID, err := s.resolveType(ctx, toType)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	assocTypeID, err := s.getDefaultTypeID(ctx, fromTypeID, toTypeID)
+	assocTypeID, err := getDefaultTypeID(ctx, s.db, fromTypeID, toTypeID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	ts := now()
-	var results []BatchDefaultAssocResult
-	for _, input := range inputs {
-		_, err := s.db.ExecContext(ctx,
-			`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
-			input.From.ID, input.To.ID, assocTypeID, ts,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("batch default associate: %w", err)
+	results := []BatchDefaultAssocResult{}
+	missing := newMissingObjects(fromTypeID, toTypeID)
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		ts := now()
+		for _, input := range inputs {
+			if !missing.check(ctx, tx, input.From.ID, input.To.ID) {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
+				input.From.ID, input.To.ID, assocTypeID, ts,
+			); err != nil {
+				return fmt.Errorf("batch default associate: %w", err)
+			}
+			if err := createReverseAssociation(ctx, tx, toTypeID, input.To.ID, fromTypeID, input.From.ID, ts); err != nil {
+				return err
+			}
+			if err := recomputeRollups(ctx, tx, input.From.ID, input.To.ID); err != nil {
+				return err
+			}
+			results = append(results, BatchDefaultAssocResult{
+				FromID: input.From.ID, ToID: input.To.ID, Category: "HUBSPOT_DEFINED", TypeID: assocTypeID,
+			})
 		}
-		s.createReverseAssociation(ctx, toTypeID, input.To.ID, fromTypeID, input.From.ID, ts)
-		results = append(results, BatchDefaultAssocResult{
-			FromID: input.From.ID, ToID: input.To.ID, Category: "HUBSPOT_DEFINED", TypeID: assocTypeID,
-		})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
-	return results, nil
+	return results, missing.errors(ctx, s.db), nil
 }
 
-// BatchCreate creates labeled associations for multiple object pairs.
-func (s *SQLiteAssociationStore) BatchCreate(ctx context.Context, fromType, toType string, inputs []BatchAssocCreateInput) ([]BatchCreateResult, error) {
+// BatchCreate creates labeled associations for multiple object pairs in one
+// transaction. Pairs that refer to a missing object or association type are
+// skipped and reported as errors.
+func (s *SQLiteAssociationStore) BatchCreate(ctx context.Context, fromType, toType string, inputs []BatchAssocCreateInput) ([]BatchCreateResult, []domain.BatchError, error) {
 	fromTypeID, err := s.resolveType(ctx, fromType)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	toTypeID, err := s.resolveType(ctx, toType)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	ts := now()
-	var results []BatchCreateResult
-	for _, input := range inputs {
-		defaultTypeID, defErr := s.getDefaultTypeID(ctx, fromTypeID, toTypeID)
-		if defErr == nil {
-			_, _ = s.db.ExecContext(ctx,
-				`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
-				input.From.ID, input.To.ID, defaultTypeID, ts,
-			)
-		}
-		var labels []domain.AssociationLabel
-		for _, t := range input.Types {
-			_, err := s.db.ExecContext(ctx,
-				`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
-				input.From.ID, input.To.ID, t.AssociationTypeID, ts,
-			)
-			if err != nil {
-				return nil, fmt.Errorf("batch create association: %w", err)
+	results := []BatchCreateResult{}
+	missing := newMissingObjects(fromTypeID, toTypeID)
+	var badTypes []string
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		ts := now()
+		for _, input := range inputs {
+			if !missing.check(ctx, tx, input.From.ID, input.To.ID) {
+				continue
 			}
-			var category string
-			var label sql.NullString
-			_ = s.db.QueryRowContext(ctx, `SELECT category, label FROM association_types WHERE id = ?`, t.AssociationTypeID).Scan(&category, &label)
-			labels = append(labels, domain.AssociationLabel{TypeID: t.AssociationTypeID, Category: category, Label: label.String})
+			labels := make([]domain.AssociationLabel, 0, len(input.Types))
+			for _, t := range input.Types {
+				var category string
+				var label sql.NullString
+				err := tx.QueryRowContext(ctx,
+					`SELECT category, label FROM association_types WHERE id = ? AND from_object_type = ? AND to_object_type = ?`,
+					t.AssociationTypeID, fromTypeID, toTypeID,
+				).Scan(&category, &label)
+				if err != nil {
+					labels = nil
+					badTypes = append(badTypes, strconv.Itoa(t.AssociationTypeID))
+					break
+				}
+				labels = append(labels, domain.AssociationLabel{TypeID: t.AssociationTypeID, Category: category, Label: label.String})
+			}
+			if labels == nil {
+				continue
+			}
+
+			if defaultTypeID, err := getDefaultTypeID(ctx, tx, fromTypeID, toTypeID); err == nil {
+				if _, err := tx.ExecContext(ctx,
+					`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
+					input.From.ID, input.To.ID, defaultTypeID, ts,
+				); err != nil {
+					return fmt.Errorf("batch create association: %w", err)
+				}
+			}
+			for _, l := range labels {
+				if _, err := tx.ExecContext(ctx,
+					`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
+					input.From.ID, input.To.ID, l.TypeID, ts,
+				); err != nil {
+					return fmt.Errorf("batch create association: %w", err)
+				}
+			}
+			if err := createReverseAssociation(ctx, tx, toTypeID, input.To.ID, fromTypeID, input.From.ID, ts); err != nil {
+				return err
+			}
+			if err := recomputeRollups(ctx, tx, input.From.ID, input.To.ID); err != nil {
+				return err
+			}
+			results = append(results, BatchCreateResult{
+				FromObjectID: input.From.ID, FromObjectTypeID: fromTypeID,
+				ToObjectID: input.To.ID, ToObjectTypeID: toTypeID, Labels: labels,
+			})
 		}
-		s.createReverseAssociation(ctx, toTypeID, input.To.ID, fromTypeID, input.From.ID, ts)
-		results = append(results, BatchCreateResult{
-			FromObjectID: input.From.ID, FromObjectTypeID: fromTypeID,
-			ToObjectID: input.To.ID, ToObjectTypeID: toTypeID, Labels: labels,
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	errs := missing.errors(ctx, s.db)
+	if len(badTypes) > 0 {
+		errs = append(errs, domain.BatchError{
+			Status:   "error",
+			Category: "VALIDATION_ERROR",
+			Message:  fmt.Sprintf("Association types %s are not valid for %s to %s associations.", strings.Join(badTypes, ", "), fromType, toType),
+			Context:  map[string][]string{"typeIds": badTypes},
 		})
 	}
-	return results, nil
+	return results, errs, nil
 }
 
 // BatchRead retrieves associations for multiple objects in a single call.
-func (s *SQLiteAssociationStore) BatchRead(ctx context.Context, fromType, toType string, inputs []BatchAssocReadInput) ([]BatchAssocResult, error) {
+// IDs that match no object are reported as errors.
+func (s *SQLiteAssociationStore) BatchRead(ctx context.Context, fromType, toType string, inputs []BatchAssocReadInput) ([]BatchAssocResult, []domain.BatchError, error) {
 	fromTypeID, err := s.resolveType(ctx, fromType)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	toTypeID, err := s.resolveType(ctx, toType)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	var results []BatchAssocResult
+	results := []BatchAssocResult{}
+	var missing []string
 	for _, input := range inputs {
+		if !objectExists(ctx, s.db, input.ID) {
+			missing = append(missing, input.ID)
+			continue
+		}
 		assocs, err := s.getAssocResults(ctx, fromTypeID, input.ID, toTypeID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		results = append(results, BatchAssocResult{From: input.ID, To: assocs})
 	}
-	return results, nil
+	var errs []domain.BatchError
+	if len(missing) > 0 {
+		errs = append(errs, notFoundBatchError(objectTypeLabel(ctx, s.db, fromTypeID), missing))
+	}
+	return results, errs, nil
+}
+
+// missingObjects collects the from and to IDs of a batch that matched no
+// object, so they can be reported as one error per object type.
+type missingObjects struct {
+	fromTypeID, toTypeID string
+	from, to             []string
 }
 
-// BatchArchive removes all associations for multiple object pairs.
+func newMissingObjects(fromTypeID, toTypeID string) *missingObjects {
+	return &missingObjects{fromTypeID: fromTypeID, toTypeID: toTypeID}
+}
+
+// check reports whether both objects exist, recording any that do not.
+func (m *missingObjects) check(ctx context.Context, ex execer, fromID, toID string) bool {
+	ok := true
+	if !objectExists(ctx, ex, fromID) {
+		m.from = append(m.from, fromID)
+		ok = false
+	}
+	if !objectExists(ctx, ex, toID) {
+		m.to = append(m.to, toID)
+		ok = false
+	}
+	return ok
+}
+
+// errors returns the batch errors for the recorded IDs.
+func (m *missingObjects) errors(ctx context.Context, ex execer) []domain.BatchError {
+	var errs []domain.BatchError
+	if len(m.from) > 0 {
+		errs = append(errs, notFoundBatchError(objectTypeLabel(ctx, ex, m.fromTypeID), m.from))
+	}
+	if len(m.to) > 0 {
+		errs = append(errs, notFoundBatchError(objectTypeLabel(ctx, ex, m.toTypeID), m.to))
+	}
+	return errs
+}
+
+// BatchArchive removes all associations for multiple object pairs in one
+// transaction.
 func (s *SQLiteAssociationStore) BatchArchive(ctx context.Context, fromType, toType string, inputs []BatchArchiveInput) error {
 	fromTypeID, err := s.resolveType(ctx, fromType)
 	if err != nil {
@@ -478,19 +696,24 @@ func (s *SQLiteAssociationStore) BatchArchive(ctx context.Context, fromType, toT
 	if err != nil {
 		return err
 	}
-	for _, input := range inputs {
-		_, err := s.db.ExecContext(ctx,
-			`DELETE FROM associations WHERE from_object_id = ? AND to_object_id = ? AND association_type_id IN (SELECT id FROM association_types WHERE from_object_type = ? AND to_object_type = ?)`,
-			input.From.ID, input.To.ID, fromTypeID, toTypeID,
-		)
-		if err != nil {
-			return fmt.Errorf("batch archive association: %w", err)
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, input := range inputs {
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM associations WHERE from_object_id = ? AND to_object_id = ? AND association_type_id IN (SELECT id FROM association_types WHERE from_object_type = ? AND to_object_type = ?)`,
+				input.From.ID, input.To.ID, fromTypeID, toTypeID,
+			); err != nil {
+				return fmt.Errorf("batch archive association: %w", err)
+			}
+			if err := recomputeRollups(ctx, tx, input.From.ID, input.To.ID); err != nil {
+				return err
+			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
-// BatchArchiveLabels removes specific labeled associations for multiple object pairs.
+// BatchArchiveLabels removes specific labeled associations for multiple
+// object pairs in one transaction.
 func (s *SQLiteAssociationStore) BatchArchiveLabels(ctx context.Context, fromType, toType string, inputs []BatchArchiveLabelInput) error {
 	_, err := s.resolveType(ctx, fromType)
 	if err != nil {
@@ -500,18 +723,22 @@ func (s *SQLiteAssociationStore) BatchArchiveLabels(ctx context.Context, fromTyp
 	if err != nil {
 		return err
 	}
-	for _, input := range inputs {
-		for _, t := range input.Types {
-			_, err := s.db.ExecContext(ctx,
-				`DELETE FROM associations WHERE from_object_id = ? AND to_object_id = ? AND association_type_id = ?`,
-				input.From.ID, input.To.ID, t.AssociationTypeID,
-			)
-			if err != nil {
-				return fmt.Errorf("batch archive label: %w", err)
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, input := range inputs {
+			for _, t := range input.Types {
+				if _, err := tx.ExecContext(ctx,
+					`DELETE FROM associations WHERE from_object_id = ? AND to_object_id = ? AND association_type_id = ?`,
+					input.From.ID, input.To.ID, t.AssociationTypeID,
+				); err != nil {
+					return fmt.Errorf("batch archive label: %w", err)
+				}
+			}
+			if err := recomputeRollups(ctx, tx, input.From.ID, input.To.ID); err != nil {
+				return err
 			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 func (s *SQLiteAssociationStore) getAssocResults(ctx context.Context, fromTypeID, fromID, toTypeID string) ([]domain.AssociationResult, error) {
@@ -2,9 +2,11 @@ package store_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/johnwards/hubspot/internal/database"
+	"github.com/johnwards/hubspot/internal/domain"
 	"github.com/johnwards/hubspot/internal/seed"
 	"github.com/johnwards/hubspot/internal/store"
 	"github.com/johnwards/hubspot/internal/testhelpers"
@@ -272,15 +274,22 @@ func TestBatchAssociateDefault(t *testing.T) {
 	inputs := []store.BatchAssocInput{
 		{From: store.ObjectID{ID: c1}, To: store.ObjectID{ID: co1}},
 		{From: store.ObjectID{ID: c2}, To: store.ObjectID{ID: co2}},
+		{From: store.ObjectID{ID: c1}, To: store.ObjectID{ID: "999999"}},
 	}
 
-	results, err := assocStore.BatchAssociateDefault(ctx, "contacts", "companies", inputs)
+	results, errs, err := assocStore.BatchAssociateDefault(ctx, "contacts", "companies", inputs)
 	if err != nil {
 		t.Fatalf("batch default: %v", err)
 	}
 	if len(results) != 2 {
 		t.Fatalf("expected 2 results, got %d", len(results))
 	}
+	if len(errs) != 1 || errs[0].Category != "OBJECT_NOT_FOUND" {
+		t.Fatalf("expected one OBJECT_NOT_FOUND error, got %+v", errs)
+	}
+	if ids := errs[0].Context["ids"]; len(ids) != 1 || ids[0] != "999999" {
+		t.Errorf("expected context ids [999999], got %v", ids)
+	}
 }
 
 func TestAssocBatchRead(t *testing.T) {
@@ -294,10 +303,13 @@ func TestAssocBatchRead(t *testing.T) {
 		t.Fatalf("associate: %v", err)
 	}
 
-	results, err := assocStore.BatchRead(ctx, "contacts", "companies", []store.BatchAssocReadInput{{ID: contactID}})
+	results, errs, err := assocStore.BatchRead(ctx, "contacts", "companies", []store.BatchAssocReadInput{{ID: contactID}, {ID: "999999"}})
 	if err != nil {
 		t.Fatalf("batch read: %v", err)
 	}
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error for the missing ID, got %d", len(errs))
+	}
 	if len(results) != 1 {
 		t.Fatalf("expected 1 result, got %d", len(results))
 	}
@@ -374,3 +386,117 @@ func TestBatchArchiveLabels(t *testing.T) {
 		}
 	}
 }
+
+func TestListAssociatedObjects(t *testing.T) {
+	assocStore, objStore, ctx := setupAssocStore(t)
+
+	contactID := createTestObject(t, objStore, ctx, "contacts")
+	companyID := createTestObject(t, objStore, ctx, "companies")
+
+	types := []store.AssociationInput{
+		{AssociationCategory: "HUBSPOT_DEFINED", AssociationTypeID: 279}, // Primary
+	}
+	if _, err := assocStore.AssociateWithLabels(ctx, "contacts", contactID, "companies", companyID, types); err != nil {
+		t.Fatalf("associate with labels: %v", err)
+	}
+
+	results, err := assocStore.ListAssociatedObjects(ctx, "contacts", contactID, "companies", false)
+	if err != nil {
+		t.Fatalf("list associated objects: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results (default + Primary), got %d", len(results))
+	}
+	if results[0].ID != companyID || results[0].Type != "contact_to_company" {
+		t.Errorf("unexpected first result %+v", results[0])
+	}
+	if results[1].Type != "contact_to_company_primary" {
+		t.Errorf("expected labeled type contact_to_company_primary, got %q", results[1].Type)
+	}
+}
+
+func TestCreateWithAssociations(t *testing.T) {
+	assocStore, objStore, ctx := setupAssocStore(t)
+
+	contactID := createTestObject(t, objStore, ctx, "contacts")
+
+	note, err := objStore.CreateWithAssociations(ctx, "notes", domain.CreateInput{
+		Properties: map[string]string{"hs_note_body": "Called about renewal"},
+		Associations: []domain.ObjectAssociationInput{{
+			To:    domain.AssociationTarget{ID: contactID},
+			Types: []domain.AssociationTypeInput{{AssociationCategory: "HUBSPOT_DEFINED", AssociationTypeID: 202}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("create with associations: %v", err)
+	}
+
+	forward, err := assocStore.GetAssociations(ctx, "notes", note.ID, "contacts")
+	if err != nil {
+		t.Fatalf("get associations: %v", err)
+	}
+	if len(forward) != 1 || forward[0].ToObjectID != contactID {
+		t.Fatalf("expected note associated to contact %s, got %+v", contactID, forward)
+	}
+
+	reverse, err := assocStore.GetAssociations(ctx, "contacts", contactID, "notes")
+	if err != nil {
+		t.Fatalf("get reverse associations: %v", err)
+	}
+	if len(reverse) != 1 || reverse[0].ToObjectID != note.ID {
+		t.Errorf("expected reverse association to note %s, got %+v", note.ID, reverse)
+	}
+}
+
+func TestCreateWithInvalidAssociationRollsBack(t *testing.T) {
+	_, objStore, ctx := setupAssocStore(t)
+
+	contactID := createTestObject(t, objStore, ctx, "contacts")
+
+	tests := []struct {
+		name  string
+		assoc domain.ObjectAssociationInput
+	}{
+		{
+			name: "missing target",
+			assoc: domain.ObjectAssociationInput{
+				To:    domain.AssociationTarget{ID: "999999"},
+				Types: []domain.AssociationTypeInput{{AssociationCategory: "HUBSPOT_DEFINED", AssociationTypeID: 202}},
+			},
+		},
+		{
+			name: "type for other object types",
+			assoc: domain.ObjectAssociationInput{
+				To:    domain.AssociationTarget{ID: contactID},
+				Types: []domain.AssociationTypeInput{{AssociationCategory: "HUBSPOT_DEFINED", AssociationTypeID: 1}},
+			},
+		},
+		{
+			name: "wrong category",
+			assoc: domain.ObjectAssociationInput{
+				To:    domain.AssociationTarget{ID: contactID},
+				Types: []domain.AssociationTypeInput{{AssociationCategory: "USER_DEFINED", AssociationTypeID: 202}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := objStore.CreateWithAssociations(ctx, "notes", domain.CreateInput{
+				Properties:   map[string]string{"hs_note_body": "rolled back"},
+				Associations: []domain.ObjectAssociationInput{tt.assoc},
+			})
+			if !errors.Is(err, store.ErrInvalidAssociation) {
+				t.Fatalf("expected ErrInvalidAssociation, got %v", err)
+			}
+		})
+	}
+
+	page, err := objStore.List(ctx, "notes", domain.ListOpts{Limit: 10})
+	if err != nil {
+		t.Fatalf("list notes: %v", err)
+	}
+	if len(page.Results) != 0 {
+		t.Errorf("expected no notes after rolled back creates, got %d", len(page.Results))
+	}
+}
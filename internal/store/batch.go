@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/johnwards/hubspot/internal/domain"
+)
+
+// notFoundBatchError is the single error HubSpot reports for every input of
+// a batch that referred to a missing object. label names the object type,
+// e.g. "Contact".
+func notFoundBatchError(label string, ids []string) domain.BatchError {
+	return domain.BatchError{
+		Status:   "error",
+		Category: "OBJECT_NOT_FOUND",
+		Message:  fmt.Sprintf("Could not get some %s objects, they may be deleted or not exist. Check that ids are valid.", strings.ToUpper(label)),
+		Context:  map[string][]string{"ids": ids},
+	}
+}
+
+// addBatchError records e against result.
+func addBatchError(result *domain.BatchResult, e domain.BatchError) {
+	result.NumErrors++
+	result.Errors = append(result.Errors, e)
+}
+
+// objectTypeLabel returns the singular label of an object type, falling back
+// to the type ID when it has none.
+func objectTypeLabel(ctx context.Context, ex execer, typeID string) string {
+	var label string
+	err := ex.QueryRowContext(ctx, `SELECT label_singular FROM object_types WHERE id = ?`, typeID).Scan(&label)
+	if err != nil || label == "" {
+		return typeID
+	}
+	return label
+}
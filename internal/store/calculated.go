@@ -0,0 +1,253 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johnwards/hubspot/internal/domain"
+	"github.com/johnwards/hubspot/internal/formula"
+)
+
+// ErrInvalidFormula is returned when a property's calculation formula cannot
+// be parsed.
+var ErrInvalidFormula = fmt.Errorf("invalid calculation formula")
+
+// systemPropertyTypes gives the types of system properties that are set on
+// every object but have no definition.
+var systemPropertyTypes = map[string]string{
+	"createdate":       "datetime",
+	"lastmodifieddate": "datetime",
+}
+
+// parsedFormulas caches parsed calculation formulas by their source, so reads
+// parse each formula once however often its properties are evaluated. A
+// changed formula has a different source and is parsed afresh.
+var parsedFormulas sync.Map // source → *formula.Expr
+
+// parseFormula returns the parsed form of src.
+func parseFormula(src string) (*formula.Expr, error) {
+	if expr, ok := parsedFormulas.Load(src); ok {
+		return expr.(*formula.Expr), nil
+	}
+	expr, err := formula.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	parsedFormulas.Store(src, expr)
+	return expr, nil
+}
+
+// checkFormula validates the calculation formula of p, if it has one, and
+// marks p calculated.
+func checkFormula(p *domain.Property) error {
+	if p.CalculationFormula == "" {
+		return nil
+	}
+	if _, err := formula.Parse(p.CalculationFormula); err != nil {
+		return fmt.Errorf("%w for %s: %v", ErrInvalidFormula, p.Name, err)
+	}
+	p.Calculated = true
+	return nil
+}
+
+// requested returns a selector for calculated properties named in props.
+func requested(props []string) func(name string) bool {
+	return func(name string) bool { return slices.Contains(props, name) }
+}
+
+// allCalculated selects every calculated property.
+func allCalculated(string) bool { return true }
+
+// checkNotCalculated returns a *ValidationError if req filters or sorts on a
+// calculated property of typeID. Their values exist only when objects are
+// read, so the search could not match or order by them.
+func checkNotCalculated(ctx context.Context, ex execer, typeID string, req *domain.SearchRequest) error {
+	rows, err := ex.QueryContext(ctx,
+		`SELECT name FROM property_definitions
+		 WHERE object_type_id = ? AND archived = FALSE AND COALESCE(calculation_formula, '') != ''`, typeID)
+	if err != nil {
+		return fmt.Errorf("load calculated properties: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	calculated := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("scan property: %w", err)
+		}
+		calculated[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load calculated properties: %w", err)
+	}
+
+	invalid := func(use, name string) error {
+		return &ValidationError{Message: fmt.Sprintf("cannot %s calculated property %s", use, name)}
+	}
+	for _, group := range req.FilterGroups {
+		for _, f := range group.Filters {
+			if calculated[f.PropertyName] {
+				return invalid("filter on", f.PropertyName)
+			}
+		}
+	}
+	for _, sort := range req.Sorts {
+		if calculated[sort.PropertyName] {
+			return invalid("sort by", sort.PropertyName)
+		}
+	}
+	return nil
+}
+
+// applyCalculated evaluates the calculated properties of typeID selected by
+// want and sets their values on each object. Calculated values are never
+// stored; a formula that fails to evaluate leaves its property unset. The
+// definitions are read once and the values of all objs in one query.
+func applyCalculated(ctx context.Context, ex execer, typeID string, objs []*domain.Object, want func(name string) bool) error {
+	rows, err := ex.QueryContext(ctx,
+		`SELECT name, type, COALESCE(calculation_formula, '') FROM property_definitions
+		 WHERE object_type_id = ? AND archived = FALSE`, typeID)
+	if err != nil {
+		return fmt.Errorf("load calculated properties: %w", err)
+	}
+	types := maps.Clone(systemPropertyTypes)
+	formulas := make(map[string]*formula.Expr)
+	var selected []string
+	for rows.Next() {
+		var name, typ, src string
+		if err := rows.Scan(&name, &typ, &src); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("scan property: %w", err)
+		}
+		types[name] = typ
+		if src == "" {
+			continue
+		}
+		// Formulas are validated when the property is written.
+		if expr, err := parseFormula(src); err == nil {
+			formulas[name] = expr
+			if want(name) {
+				selected = append(selected, name)
+			}
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load calculated properties: %w", err)
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(objs))
+	for i, obj := range objs {
+		ids[i] = obj.ID
+	}
+	allValues, err := getObjectsProperties(ctx, ex, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		values := allValues[obj.ID]
+
+		// Calculated properties may refer to each other; a cycle leaves the
+		// properties in it unset.
+		results := make(map[string]any)
+		evaluating := make(map[string]bool)
+		var resolve formula.Env
+		resolve = func(name string) any {
+			expr, ok := formulas[name]
+			if !ok {
+				return typedValue(types[name], values[name])
+			}
+			if v, done := results[name]; done {
+				return v
+			}
+			if evaluating[name] {
+				return nil
+			}
+			evaluating[name] = true
+			v, err := expr.Eval(resolve)
+			if err != nil {
+				v = nil
+			}
+			results[name] = v
+			return v
+		}
+
+		if obj.Properties == nil {
+			obj.Properties = make(map[string]string)
+		}
+		for _, name := range selected {
+			if s, ok := formatValue(types[name], resolve(name)); ok {
+				obj.Properties[name] = s
+			}
+		}
+	}
+	return nil
+}
+
+// typedValue converts a stored property value to the formula value of its
+// property type. Dates and datetimes become epoch milliseconds.
+func typedValue(typ, raw string) any {
+	if raw == "" {
+		return nil
+	}
+	switch typ {
+	case "number":
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil
+		}
+		return f
+	case "bool":
+		return strings.EqualFold(raw, "true")
+	case "date", "datetime":
+		if ms, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return float64(ms)
+		}
+		for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return float64(t.UnixMilli())
+			}
+		}
+		return nil
+	}
+	return raw
+}
+
+// formatValue formats a formula result as a value of property type typ. It
+// reports false when the result is unset or does not fit the type.
+func formatValue(typ string, v any) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	switch typ {
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return "", false
+		}
+	case "bool":
+		if _, ok := v.(bool); !ok {
+			return "", false
+		}
+	case "date", "datetime":
+		ms, ok := v.(float64)
+		if !ok {
+			return "", false
+		}
+		t := time.UnixMilli(int64(ms)).UTC()
+		if typ == "date" {
+			return t.Format(time.DateOnly), true
+		}
+		return t.Format("2006-01-02T15:04:05.000Z"), true
+	}
+	return formula.String(v), true
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// Expectation describes requests a client is expected to have made. Matching
+// and verification against the request log happen in the admin API.
+type Expectation struct {
+	ID        int64            `json:"id"`
+	Method    string           `json:"method,omitempty"`
+	Path      string           `json:"path,omitempty"`
+	Body      []BodyPredicate  `json:"body,omitempty"`
+	Times     ExpectationTimes `json:"times"`
+	CreatedAt string           `json:"createdAt"`
+}
+
+// BodyPredicate asserts on the values a JSON path selects from a request body.
+type BodyPredicate struct {
+	Path     string          `json:"path"`
+	Equals   json.RawMessage `json:"equals,omitempty"`
+	Contains string          `json:"contains,omitempty"`
+	Exists   *bool           `json:"exists,omitempty"`
+}
+
+// ExpectationTimes bounds how many requests may match an expectation. Exactly
+// one of the fields is set.
+type ExpectationTimes struct {
+	Exactly *int `json:"exactly,omitempty"`
+	AtLeast *int `json:"atLeast,omitempty"`
+	Never   bool `json:"never,omitempty"`
+}
+
+// ExpectationStore defines the interface for expectation persistence.
+type ExpectationStore interface {
+	Create(ctx context.Context, exp *Expectation) error
+	List(ctx context.Context) ([]*Expectation, error)
+	DeleteAll(ctx context.Context) error
+}
+
+// SQLiteExpectationStore implements ExpectationStore backed by SQLite.
+type SQLiteExpectationStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteExpectationStore creates a new SQLiteExpectationStore.
+func NewSQLiteExpectationStore(db *sql.DB) *SQLiteExpectationStore {
+	return &SQLiteExpectationStore{db: db}
+}
+
+// Create stores an expectation and sets its ID and CreatedAt.
+func (s *SQLiteExpectationStore) Create(ctx context.Context, exp *Expectation) error {
+	exp.CreatedAt = now()
+
+	spec, err := json.Marshal(exp)
+	if err != nil {
+		return fmt.Errorf("marshal expectation: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO expectations (spec, created_at) VALUES (?, ?)`,
+		string(spec), exp.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert expectation: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("last insert id: %w", err)
+	}
+	exp.ID = id
+	return nil
+}
+
+// List returns all expectations in the order they were registered.
+func (s *SQLiteExpectationStore) List(ctx context.Context) ([]*Expectation, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, spec FROM expectations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query expectations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []*Expectation
+	for rows.Next() {
+		var id int64
+		var spec string
+		if err := rows.Scan(&id, &spec); err != nil {
+			return nil, fmt.Errorf("scan expectation: %w", err)
+		}
+		exp := &Expectation{}
+		if err := json.Unmarshal([]byte(spec), exp); err != nil {
+			return nil, fmt.Errorf("unmarshal expectation %d: %w", id, err)
+		}
+		exp.ID = id
+		out = append(out, exp)
+	}
+	return out, rows.Err()
+}
+
+// DeleteAll removes every expectation.
+func (s *SQLiteExpectationStore) DeleteAll(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM expectations`); err != nil {
+		return fmt.Errorf("delete expectations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package store_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/johnwards/hubspot/internal/database"
+	"github.com/johnwards/hubspot/internal/store"
+	"github.com/johnwards/hubspot/internal/testhelpers"
+)
+
+var _ store.ExpectationStore = (*store.SQLiteExpectationStore)(nil)
+
+func TestExpectationStore(t *testing.T) {
+	db := testhelpers.NewTestDB(t)
+	ctx := context.Background()
+	if err := database.Migrate(ctx, db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	s := store.NewSQLiteExpectationStore(db)
+
+	two := 2
+	exp := &store.Expectation{
+		Method: "POST",
+		Path:   "/crm/v3/objects/contacts",
+		Body:   []store.BodyPredicate{{Path: "$.properties.email", Equals: json.RawMessage(`"a@example.com"`)}},
+		Times:  store.ExpectationTimes{Exactly: &two},
+	}
+	if err := s.Create(ctx, exp); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if exp.ID == 0 || exp.CreatedAt == "" {
+		t.Errorf("expected ID and CreatedAt to be set, got %d %q", exp.ID, exp.CreatedAt)
+	}
+
+	exps, err := s.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(exps) != 1 {
+		t.Fatalf("expected 1 expectation, got %d", len(exps))
+	}
+	got := exps[0]
+	if got.ID != exp.ID || got.Method != "POST" || got.Path != exp.Path {
+		t.Errorf("unexpected expectation %+v", got)
+	}
+	if got.Times.Exactly == nil || *got.Times.Exactly != 2 {
+		t.Errorf("expected times.exactly=2, got %+v", got.Times)
+	}
+	if len(got.Body) != 1 || string(got.Body[0].Equals) != `"a@example.com"` {
+		t.Errorf("unexpected body predicates %+v", got.Body)
+	}
+
+	if err := s.DeleteAll(ctx); err != nil {
+		t.Fatalf("delete all: %v", err)
+	}
+	exps, err = s.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(exps) != 0 {
+		t.Errorf("expected no expectations after DeleteAll, got %d", len(exps))
+	}
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// indexPropertyValue replaces the full-text index entry of the property value
+// stored at rowID. Empty values are not indexed.
+func indexPropertyValue(ctx context.Context, ex execer, rowID, objectID int64, name, value string) error {
+	if _, err := ex.ExecContext(ctx, `DELETE FROM property_values_fts WHERE rowid = ?`, rowID); err != nil {
+		return fmt.Errorf("unindex property %s: %w", name, err)
+	}
+	if value == "" {
+		return nil
+	}
+	if _, err := ex.ExecContext(ctx,
+		`INSERT INTO property_values_fts (rowid, value, property_name, object_id) VALUES (?, ?, ?, ?)`,
+		rowID, value, name, objectID,
+	); err != nil {
+		return fmt.Errorf("index property %s: %w", name, err)
+	}
+	return nil
+}
+
+// tokens splits text into the words the full-text index holds. Like the
+// index's unicode61 tokenizer, it breaks on every character that is not a
+// letter or digit, so an email address or domain yields its parts: alice,
+// example and com.
+func tokens(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// tokenMatch returns the FTS5 query matching text as a sequence of adjacent
+// whole tokens, ignoring case, so "ali" does not match Alison. A trailing "*"
+// makes the last token match as a prefix, so "Alex*" matches Alexander; a
+// leading "*", as in "*@example.com", matches any preceding tokens. It
+// returns "" if text has no tokens.
+func tokenMatch(text string) string {
+	words := tokens(text)
+	if len(words) == 0 {
+		return ""
+	}
+	// Tokens hold only letters and digits, so they need no escaping.
+	match := `"` + strings.Join(words, " ") + `"`
+	if strings.HasSuffix(text, "*") {
+		match += "*"
+	}
+	return match
+}
+
+// tokenClause returns the condition matching objects with a value of one of
+// props that contains the tokens of text. It matches nothing if props is
+// empty.
+func tokenClause(text string, props ...string) (clause string, args []any) {
+	match := tokenMatch(text)
+	if match == "" || len(props) == 0 {
+		return "1=0", nil
+	}
+	placeholders := make([]string, len(props))
+	args = []any{match}
+	for i, p := range props {
+		placeholders[i] = "?"
+		args = append(args, p)
+	}
+	return fmt.Sprintf(`o.id IN (SELECT object_id FROM property_values_fts
+		WHERE property_values_fts MATCH ? AND property_name IN (%s))`, strings.Join(placeholders, ",")), args
+}
@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnwards/hubspot/internal/domain"
+)
+
+// contactTypeID is the object type ID of contacts, the only type GDPR
+// deletion applies to.
+const contactTypeID = "0-1"
+
+// legalBasisProperty records the lawful basis for processing a contact's
+// data. Setting it is how a GDPR-deleted email address is re-created with
+// consent.
+const legalBasisProperty = "hs_legal_basis"
+
+// noLegalBasis is the hs_legal_basis option recording that no lawful basis
+// applies, which is not consent to process a deleted contact's data.
+const noLegalBasis = "Not applicable"
+
+// hasLegalBasis reports whether props records a lawful basis for processing
+// a contact's data. hs_legal_basis may hold several options separated by
+// semicolons.
+func hasLegalBasis(props map[string]string) bool {
+	for basis := range strings.SplitSeq(props[legalBasisProperty], ";") {
+		basis = strings.TrimSpace(basis)
+		if basis != "" && !strings.EqualFold(basis, noLegalBasis) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrGDPRDeleted is returned when a contact is created or updated with the
+// email address of a permanently deleted contact and no legal basis for
+// processing it.
+var ErrGDPRDeleted = fmt.Errorf("email address was permanently deleted")
+
+// checkGDPRDeleted returns ErrGDPRDeleted if writing props to a contact would
+// bring back the email address of a GDPR-deleted contact without a legal
+// basis in the same write.
+func checkGDPRDeleted(ctx context.Context, ex execer, typeID string, props map[string]string) error {
+	email := strings.ToLower(strings.TrimSpace(props["email"]))
+	if typeID != contactTypeID || email == "" || hasLegalBasis(props) {
+		return nil
+	}
+	var exists int
+	err := ex.QueryRowContext(ctx, `SELECT 1 FROM gdpr_deleted_emails WHERE email = ?`, email).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check gdpr deleted email: %w", err)
+	}
+	return fmt.Errorf("%w: %s was deleted under GDPR and can only be re-created with %s set", ErrGDPRDeleted, email, legalBasisProperty)
+}
+
+// releaseGDPRDeleted unblocks the email address in props once a contact has
+// been written with it and a legal basis, so later writes are not blocked.
+func releaseGDPRDeleted(ctx context.Context, ex execer, typeID string, props map[string]string) error {
+	email := strings.ToLower(strings.TrimSpace(props["email"]))
+	if typeID != contactTypeID || email == "" || !hasLegalBasis(props) {
+		return nil
+	}
+	if _, err := ex.ExecContext(ctx, `DELETE FROM gdpr_deleted_emails WHERE email = ?`, email); err != nil {
+		return fmt.Errorf("release gdpr deleted email: %w", err)
+	}
+	return nil
+}
+
+// addGDPRDeletedError records err against result if it is ErrGDPRDeleted and
+// reports whether it did, so batch operations can skip the offending input.
+func addGDPRDeletedError(result *domain.BatchResult, err error) bool {
+	if !errors.Is(err, ErrGDPRDeleted) {
+		return false
+	}
+	addBatchError(result, domain.BatchError{
+		Status:   "error",
+		Category: "VALIDATION_ERROR",
+		Message:  err.Error(),
+	})
+	return true
+}
+
+// GDPRDelete permanently deletes a contact, archived or not, together with
+// any contacts previously merged into it. idProperty is empty to look the
+// contact up by ID or "email" to look it up by email address. The deleted
+// email addresses are blocked from being re-created without a legal basis.
+func (s *SQLiteObjectStore) GDPRDelete(ctx context.Context, id, idProperty string) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var objectID string
+		var err error
+		if idProperty == "email" {
+			err = tx.QueryRowContext(ctx,
+				`SELECT o.id FROM objects o
+				 JOIN property_values pv ON pv.object_id = o.id
+				 WHERE o.object_type_id = ? AND pv.property_name = 'email' AND pv.value = ? COLLATE NOCASE
+				 ORDER BY o.archived, o.id LIMIT 1`,
+				contactTypeID, strings.TrimSpace(id),
+			).Scan(&objectID)
+		} else {
+			err = tx.QueryRowContext(ctx,
+				`SELECT id FROM objects WHERE id = ? AND object_type_id = ?`, id, contactTypeID,
+			).Scan(&objectID)
+		}
+		if err != nil {
+			return fmt.Errorf("contact %s: %w", id, ErrNotFound)
+		}
+
+		ids := []string{objectID}
+		rows, err := tx.QueryContext(ctx, `SELECT id FROM objects WHERE merged_into_id = ?`, objectID)
+		if err != nil {
+			return fmt.Errorf("find merged contacts: %w", err)
+		}
+		for rows.Next() {
+			var mergedID string
+			if err := rows.Scan(&mergedID); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("scan merged contact: %w", err)
+			}
+			ids = append(ids, mergedID)
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("find merged contacts: %w", err)
+		}
+
+		ts := now()
+		for _, id := range ids {
+			var email string
+			err := tx.QueryRowContext(ctx,
+				`SELECT value FROM property_values WHERE object_id = ? AND property_name = 'email'`, id,
+			).Scan(&email)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("get email: %w", err)
+			}
+			if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+				if _, err := tx.ExecContext(ctx,
+					`INSERT OR REPLACE INTO gdpr_deleted_emails (email, deleted_at) VALUES (?, ?)`, email, ts,
+				); err != nil {
+					return fmt.Errorf("record gdpr deleted email: %w", err)
+				}
+			}
+			if err := deleteObject(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PurgeArchived permanently deletes every object archived at or before
+// cutoff and returns how many were removed.
+func (s *SQLiteObjectStore) PurgeArchived(ctx context.Context, cutoff time.Time) (int, error) {
+	var purged int
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx,
+			`SELECT id FROM objects WHERE archived = TRUE AND archived_at <= ?`,
+			cutoff.UTC().Format("2006-01-02T15:04:05.000Z"),
+		)
+		if err != nil {
+			return fmt.Errorf("find archived objects: %w", err)
+		}
+		var ids []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("scan archived object: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("find archived objects: %w", err)
+		}
+
+		for _, id := range ids {
+			if err := deleteObject(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		purged = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// deleteObject removes an object and every row that refers to it, and
+// recomputes the rollups of the objects it was associated with.
+func deleteObject(ctx context.Context, ex execer, id string) error {
+	referrers, err := referringObjects(ctx, ex, id)
+	if err != nil {
+		return err
+	}
+	stmts := []struct {
+		query string
+		args  []any
+	}{
+		{`DELETE FROM list_memberships WHERE object_id = ?`, []any{id}},
+		{`DELETE FROM associations WHERE from_object_id = ? OR to_object_id = ?`, []any{id, id}},
+		{`DELETE FROM property_value_history WHERE object_id = ?`, []any{id}},
+		{`DELETE FROM property_values_fts WHERE rowid IN (SELECT rowid FROM property_values WHERE object_id = ?)`, []any{id}},
+		{`DELETE FROM property_values WHERE object_id = ?`, []any{id}},
+		{`UPDATE objects SET merged_into_id = NULL WHERE merged_into_id = ?`, []any{id}},
+		{`DELETE FROM objects WHERE id = ?`, []any{id}},
+	}
+	for _, st := range stmts {
+		if _, err := ex.ExecContext(ctx, st.query, st.args...); err != nil {
+			return fmt.Errorf("delete object %s: %w", id, err)
+		}
+	}
+	return recomputeRollups(ctx, ex, referrers...)
+}
@@ -0,0 +1,190 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnwards/hubspot/internal/domain"
+	"github.com/johnwards/hubspot/internal/store"
+)
+
+func TestGDPRDelete(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	assocs := store.NewSQLiteAssociationStore(db)
+	ctx := context.Background()
+
+	contact, _ := s.Create(ctx, "contacts", map[string]string{"email": "Forget@Example.com"})
+	company, _ := s.Create(ctx, "companies", map[string]string{"name": "Kept"})
+	if _, err := assocs.AssociateDefault(ctx, "contacts", contact.ID, "companies", company.ID); err != nil {
+		t.Fatalf("associate: %v", err)
+	}
+
+	if err := s.GDPRDelete(ctx, "forget@example.com", "email"); err != nil {
+		t.Fatalf("gdpr delete: %v", err)
+	}
+
+	if _, err := s.Get(ctx, "contacts", contact.ID, nil); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected deleted contact to be gone, got %v", err)
+	}
+	for _, table := range []string{"property_values", "property_value_history"} {
+		if n := countRows(t, db, `SELECT COUNT(*) FROM `+table+` WHERE object_id = ?`, contact.ID); n != 0 {
+			t.Errorf("expected no %s rows, got %d", table, n)
+		}
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM associations`); n != 0 {
+		t.Errorf("expected associations to be deleted, got %d", n)
+	}
+	if _, err := s.Get(ctx, "companies", company.ID, nil); err != nil {
+		t.Errorf("expected associated company to survive: %v", err)
+	}
+
+	if err := s.GDPRDelete(ctx, contact.ID, ""); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	// The email cannot come back without a legal basis.
+	_, err := s.Create(ctx, "contacts", map[string]string{"email": "forget@example.com"})
+	if !errors.Is(err, store.ErrGDPRDeleted) {
+		t.Fatalf("expected ErrGDPRDeleted, got %v", err)
+	}
+	result, err := s.BatchCreate(ctx, "contacts", []domain.CreateInput{
+		{Properties: map[string]string{"email": "FORGET@example.com"}},
+		{Properties: map[string]string{"email": "other@example.com"}},
+	})
+	if err != nil {
+		t.Fatalf("batch create: %v", err)
+	}
+	if len(result.Results) != 1 || result.NumErrors != 1 {
+		t.Errorf("expected 1 result and 1 error, got %d results, %d errors", len(result.Results), result.NumErrors)
+	}
+
+	// "Not applicable" records that there is no lawful basis.
+	_, err = s.Create(ctx, "contacts", map[string]string{
+		"email":          "forget@example.com",
+		"hs_legal_basis": "Not applicable",
+	})
+	if !errors.Is(err, store.ErrGDPRDeleted) {
+		t.Fatalf("expected ErrGDPRDeleted with no applicable legal basis, got %v", err)
+	}
+
+	if _, err := s.Create(ctx, "contacts", map[string]string{
+		"email":          "forget@example.com",
+		"hs_legal_basis": "Freely given consent from contact",
+	}); err != nil {
+		t.Fatalf("create with legal basis: %v", err)
+	}
+}
+
+func TestGDPRDeletedEmailOnUpdate(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	ctx := context.Background()
+
+	if _, err := s.Create(ctx, "contacts", map[string]string{"email": "forget@example.com"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := s.GDPRDelete(ctx, "forget@example.com", "email"); err != nil {
+		t.Fatalf("gdpr delete: %v", err)
+	}
+	other, err := s.Create(ctx, "contacts", map[string]string{"email": "other@example.com"})
+	if err != nil {
+		t.Fatalf("create other: %v", err)
+	}
+	blocked := map[string]string{"email": "Forget@example.com"}
+
+	if _, err := s.Update(ctx, "contacts", other.ID, blocked); !errors.Is(err, store.ErrGDPRDeleted) {
+		t.Errorf("update: expected ErrGDPRDeleted, got %v", err)
+	}
+	result, err := s.BatchUpdate(ctx, "contacts", []domain.UpdateInput{{ID: other.ID, Properties: blocked}})
+	if err != nil {
+		t.Fatalf("batch update: %v", err)
+	}
+	if len(result.Results) != 0 || result.NumErrors != 1 {
+		t.Errorf("batch update: expected 1 error, got %d results, %d errors", len(result.Results), result.NumErrors)
+	}
+	result, err = s.BatchUpsert(ctx, "contacts", []domain.UpsertInput{
+		{ID: other.ID, Properties: domain.PropertyValues(blocked)},
+	}, "hs_object_id")
+	if err != nil {
+		t.Fatalf("batch upsert: %v", err)
+	}
+	if len(result.Results) != 0 || result.NumErrors != 1 {
+		t.Errorf("batch upsert: expected 1 error, got %d results, %d errors", len(result.Results), result.NumErrors)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM gdpr_deleted_emails`); n != 1 {
+		t.Fatalf("expected the email to stay blocked, got %d rows", n)
+	}
+
+	// A legal basis in the same write releases the address.
+	if _, err := s.Update(ctx, "contacts", other.ID, map[string]string{
+		"email":          "forget@example.com",
+		"hs_legal_basis": "Freely given consent from contact",
+	}); err != nil {
+		t.Fatalf("update with legal basis: %v", err)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM gdpr_deleted_emails`); n != 0 {
+		t.Errorf("expected the email to be released, got %d rows", n)
+	}
+}
+
+func TestGDPRDeleteIncludesMergedContacts(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	ctx := context.Background()
+
+	primary, _ := s.Create(ctx, "contacts", map[string]string{"email": "primary@example.com"})
+	merged, _ := s.Create(ctx, "contacts", map[string]string{"email": "merged@example.com"})
+	if _, err := s.Merge(ctx, "contacts", primary.ID, merged.ID); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	if err := s.GDPRDelete(ctx, primary.ID, ""); err != nil {
+		t.Fatalf("gdpr delete: %v", err)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM objects`); n != 0 {
+		t.Errorf("expected both contacts to be deleted, got %d", n)
+	}
+	if _, err := s.Create(ctx, "contacts", map[string]string{"email": "merged@example.com"}); !errors.Is(err, store.ErrGDPRDeleted) {
+		t.Errorf("expected merged email to be blocked, got %v", err)
+	}
+}
+
+func TestPurgeArchived(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	ctx := context.Background()
+
+	old, _ := s.Create(ctx, "contacts", map[string]string{"email": "old@example.com"})
+	kept, _ := s.Create(ctx, "deals", map[string]string{"dealname": "Active"})
+	if err := s.Archive(ctx, "contacts", old.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	n, err := s.PurgeArchived(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("expected recently archived object to be kept, purged %d", n)
+	}
+
+	n, err = s.PurgeArchived(ctx, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 purged object, got %d", n)
+	}
+	if _, err := s.Get(ctx, "contacts", old.ID, nil); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected purged object to be gone, got %v", err)
+	}
+	if _, err := s.Get(ctx, "deals", kept.ID, nil); err != nil {
+		t.Errorf("expected active object to survive: %v", err)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM property_values WHERE object_id = ?`, old.ID); n != 0 {
+		t.Errorf("expected purged property values to be gone, got %d", n)
+	}
+}
@@ -12,6 +12,19 @@ func now() string {
 	return time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
 }
 
+// ObjectTypeNotFoundError is returned by ResolveObjectType for a type that does
+// not exist. It wraps ErrNotFound.
+type ObjectTypeNotFoundError struct {
+	ObjectType string
+}
+
+func (e *ObjectTypeNotFoundError) Error() string {
+	return fmt.Sprintf("object type %q not found", e.ObjectType)
+}
+
+// Unwrap lets errors.Is match ErrNotFound.
+func (e *ObjectTypeNotFoundError) Unwrap() error { return ErrNotFound }
+
 // ResolveObjectType resolves an object type path parameter (name like "contacts"
 // or ID like "0-1") to the internal type ID used in the database.
 func ResolveObjectType(ctx context.Context, db *sql.DB, objectType string) (string, error) {
@@ -22,9 +35,26 @@ func ResolveObjectType(ctx context.Context, db *sql.DB, objectType string) (stri
 	).Scan(&typeID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return "", fmt.Errorf("object type %q not found", objectType)
+			return "", &ObjectTypeNotFoundError{ObjectType: objectType}
 		}
 		return "", fmt.Errorf("resolve object type: %w", err)
 	}
 	return typeID, nil
 }
+
+// ObjectTypeName resolves an object type path parameter, by name or ID, to
+// the type's plural name, such as "companies" for "0-2".
+func ObjectTypeName(ctx context.Context, db *sql.DB, objectType string) (string, error) {
+	var name string
+	err := db.QueryRowContext(ctx,
+		`SELECT name FROM object_types WHERE name = ? OR id = ?`,
+		objectType, objectType,
+	).Scan(&name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", &ObjectTypeNotFoundError{ObjectType: objectType}
+		}
+		return "", fmt.Errorf("resolve object type: %w", err)
+	}
+	return name, nil
+}
@@ -351,15 +351,21 @@ func (s *SQLiteListStore) AddMembers(ctx context.Context, listID string, recordI
 
 	ts := now()
 	var added []string
-	for _, rid := range recordIDs {
-		_, err := s.db.ExecContext(ctx,
-			`INSERT OR IGNORE INTO list_memberships (list_id, object_id, added_at) VALUES (?, ?, ?)`,
-			listID, rid, ts,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("add member %s: %w", rid, err)
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, rid := range recordIDs {
+			_, err := tx.ExecContext(ctx,
+				`INSERT OR IGNORE INTO list_memberships (list_id, object_id, added_at) VALUES (?, ?, ?)`,
+				listID, rid, ts,
+			)
+			if err != nil {
+				return fmt.Errorf("add member %s: %w", rid, err)
+			}
+			added = append(added, rid)
 		}
-		added = append(added, rid)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return added, nil
 }
@@ -371,18 +377,24 @@ func (s *SQLiteListStore) RemoveMembers(ctx context.Context, listID string, reco
 	}
 
 	var removed []string
-	for _, rid := range recordIDs {
-		result, err := s.db.ExecContext(ctx,
-			`DELETE FROM list_memberships WHERE list_id = ? AND object_id = ?`,
-			listID, rid,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("remove member %s: %w", rid, err)
-		}
-		n, _ := result.RowsAffected()
-		if n > 0 {
-			removed = append(removed, rid)
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, rid := range recordIDs {
+			result, err := tx.ExecContext(ctx,
+				`DELETE FROM list_memberships WHERE list_id = ? AND object_id = ?`,
+				listID, rid,
+			)
+			if err != nil {
+				return fmt.Errorf("remove member %s: %w", rid, err)
+			}
+			n, _ := result.RowsAffected()
+			if n > 0 {
+				removed = append(removed, rid)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return removed, nil
 }
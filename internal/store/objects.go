@@ -3,16 +3,28 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"maps"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/johnwards/hubspot/internal/domain"
 )
 
 // ObjectStore defines the interface for CRM object persistence.
+//
+// Every mutation runs in a single transaction. Batch operations follow
+// HubSpot's partial-success semantics: each input is applied in its own
+// savepoint, and an input that names a missing object, repeats a unique
+// property value or brings back a GDPR-deleted email address is rolled back
+// on its own and reported in the result's errors while the other inputs
+// commit. Any other failure, such as an invalid inline association or a
+// database error, rolls back the whole batch.
 type ObjectStore interface {
 	Create(ctx context.Context, objectType string, properties map[string]string) (*domain.Object, error)
+	CreateWithAssociations(ctx context.Context, objectType string, input domain.CreateInput) (*domain.Object, error)
 	Get(ctx context.Context, objectType, id string, props []string) (*domain.Object, error)
 	GetByProperty(ctx context.Context, objectType, propName, propValue string, props []string) (*domain.Object, error)
 	List(ctx context.Context, objectType string, opts domain.ListOpts) (*domain.ObjectPage, error)
@@ -22,13 +34,24 @@ type ObjectStore interface {
 	BatchRead(ctx context.Context, objectType string, ids, props []string, idProperty string) (*domain.BatchResult, error)
 	BatchUpdate(ctx context.Context, objectType string, inputs []domain.UpdateInput) (*domain.BatchResult, error)
 	BatchUpsert(ctx context.Context, objectType string, inputs []domain.UpsertInput, idProperty string) (*domain.BatchResult, error)
-	BatchArchive(ctx context.Context, objectType string, ids []string) error
+	BatchArchive(ctx context.Context, objectType string, ids []string) (*domain.BatchResult, error)
 	Merge(ctx context.Context, objectType, primaryID, mergeID string) (*domain.Object, error)
+	GetPropertyHistory(ctx context.Context, objectID string, props []string) (map[string][]domain.PropertyHistory, error)
+	GDPRDelete(ctx context.Context, id, idProperty string) error
+	PurgeArchived(ctx context.Context, cutoff time.Time) (int, error)
 }
 
 // ErrNotFound is returned when a requested object does not exist.
 var ErrNotFound = fmt.Errorf("object not found")
 
+// ErrInvalidIDProperty is returned when an upsert names an idProperty that
+// is neither hs_object_id nor a hasUniqueValue property.
+var ErrInvalidIDProperty = fmt.Errorf("invalid idProperty")
+
+// ErrInvalidAssociation is returned when an inline association on create
+// names a missing target object or an association type that does not apply.
+var ErrInvalidAssociation = fmt.Errorf("invalid association")
+
 // SQLiteObjectStore implements ObjectStore backed by SQLite.
 type SQLiteObjectStore struct {
 	db *sql.DB
@@ -52,51 +75,164 @@ func (s *SQLiteObjectStore) resolveType(ctx context.Context, objectType string)
 
 // Create inserts a new CRM object with the given properties.
 func (s *SQLiteObjectStore) Create(ctx context.Context, objectType string, properties map[string]string) (*domain.Object, error) {
+	return s.CreateWithAssociations(ctx, objectType, domain.CreateInput{Properties: properties})
+}
+
+// CreateWithAssociations creates a new object and associates it with the
+// existing objects listed in input.Associations. The object, its properties
+// and its associations are written in a single transaction, so an invalid
+// association leaves nothing behind.
+func (s *SQLiteObjectStore) CreateWithAssociations(ctx context.Context, objectType string, input domain.CreateInput) (*domain.Object, error) {
 	typeID, err := s.resolveType(ctx, objectType)
 	if err != nil {
 		return nil, err
 	}
 
-	ts := now()
+	var id int64
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		id, err = s.insertObject(ctx, tx, typeID, input, now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getWithAllProps(ctx, objectType, strconv.FormatInt(id, 10))
+}
+
+// insertObject writes a new object with its system properties, the given
+// properties and its inline associations using ex, and returns its ID.
+func (s *SQLiteObjectStore) insertObject(ctx context.Context, ex execer, typeID string, input domain.CreateInput, ts string) (int64, error) {
+	if err := checkUniqueValues(ctx, ex, typeID, "", input.Properties); err != nil {
+		return 0, err
+	}
+	if err := checkGDPRDeleted(ctx, ex, typeID, input.Properties); err != nil {
+		return 0, err
+	}
+	props := maps.Clone(input.Properties)
+	if props == nil {
+		props = make(map[string]string)
+	}
+	if err := applyPipeline(ctx, ex, typeID, "", props); err != nil {
+		return 0, err
+	}
 
-	res, err := s.db.ExecContext(ctx,
+	res, err := ex.ExecContext(ctx,
 		`INSERT INTO objects (object_type_id, created_at, updated_at) VALUES (?, ?, ?)`,
 		typeID, ts, ts,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("insert object: %w", err)
+		return 0, fmt.Errorf("insert object: %w", err)
 	}
 
 	id, err := res.LastInsertId()
 	if err != nil {
-		return nil, fmt.Errorf("last insert id: %w", err)
+		return 0, fmt.Errorf("last insert id: %w", err)
 	}
 
 	idStr := strconv.FormatInt(id, 10)
 
-	// Auto-set system properties.
+	// Auto-set system properties. The hs_object_source* properties record
+	// where the object was created and never change afterwards.
+	src := ChangeSourceFrom(ctx)
 	sysProps := map[string]string{
 		"hs_object_id":           idStr,
 		"hs_createdate":          ts,
 		"hs_lastmodifieddate":    ts,
 		"createdate":             ts,
 		"lastmodifieddate":       ts,
-		"hs_object_source":       "API",
-		"hs_object_source_id":    "",
-		"hs_object_source_label": "",
+		"hs_object_source":       src.Type,
+		"hs_object_source_id":    src.ID,
+		"hs_object_source_label": src.Label,
 	}
-	for k, v := range properties {
+	if src.UserID > 0 {
+		userID := strconv.Itoa(src.UserID)
+		sysProps["hs_object_source_user_id"] = userID
+		sysProps["hs_created_by_user_id"] = userID
+		sysProps["hs_updated_by_user_id"] = userID
+	}
+	for k, v := range props {
 		sysProps[k] = v
 	}
+	if err := applyStageProperties(ctx, ex, typeID, "", sysProps, ts); err != nil {
+		return 0, err
+	}
 
-	if err := s.setProperties(ctx, id, sysProps, ts); err != nil {
-		return nil, err
+	if err := setProperties(ctx, ex, id, sysProps, ts); err != nil {
+		return 0, err
+	}
+	if err := releaseGDPRDeleted(ctx, ex, typeID, props); err != nil {
+		return 0, err
+	}
+
+	for _, assoc := range input.Associations {
+		if err := insertInlineAssociation(ctx, ex, typeID, idStr, assoc, ts); err != nil {
+			return 0, err
+		}
+	}
+	if err := refreshRollups(ctx, ex, idStr); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// insertInlineAssociation validates one create-time association and writes
+// it together with the reverse default association.
+func insertInlineAssociation(ctx context.Context, ex execer, fromTypeID, fromID string, assoc domain.ObjectAssociationInput, ts string) error {
+	if assoc.To.ID == "" {
+		return fmt.Errorf("%w: to.id is required", ErrInvalidAssociation)
+	}
+	if len(assoc.Types) == 0 {
+		return fmt.Errorf("%w: types is required for association to %s", ErrInvalidAssociation, assoc.To.ID)
+	}
+
+	var toTypeID string
+	err := ex.QueryRowContext(ctx,
+		`SELECT object_type_id FROM objects WHERE id = ? AND archived = FALSE`, assoc.To.ID,
+	).Scan(&toTypeID)
+	if err != nil {
+		return fmt.Errorf("%w: object %s does not exist", ErrInvalidAssociation, assoc.To.ID)
 	}
 
-	return s.getWithAllProps(ctx, objectType, idStr)
+	for _, t := range assoc.Types {
+		var category string
+		err := ex.QueryRowContext(ctx,
+			`SELECT category FROM association_types WHERE id = ? AND from_object_type = ? AND to_object_type = ?`,
+			t.AssociationTypeID, fromTypeID, toTypeID,
+		).Scan(&category)
+		if err != nil {
+			return fmt.Errorf("%w: association type %d is not valid from %s to %s", ErrInvalidAssociation, t.AssociationTypeID, fromTypeID, toTypeID)
+		}
+		if t.AssociationCategory != "" && t.AssociationCategory != category {
+			return fmt.Errorf("%w: association type %d has category %s, not %s", ErrInvalidAssociation, t.AssociationTypeID, category, t.AssociationCategory)
+		}
+		if _, err := ex.ExecContext(ctx,
+			`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at) VALUES (?, ?, ?, ?)`,
+			fromID, assoc.To.ID, t.AssociationTypeID, ts,
+		); err != nil {
+			return fmt.Errorf("create association: %w", err)
+		}
+	}
+
+	// Mirror the association on the target with its default type, as the
+	// v4 associations API does.
+	if _, err := ex.ExecContext(ctx,
+		`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at)
+		 SELECT ?, ?, id, ? FROM association_types
+		 WHERE from_object_type = ? AND to_object_type = ? AND category = 'HUBSPOT_DEFINED' AND (label IS NULL OR label = '')
+		 ORDER BY id ASC LIMIT 1`,
+		assoc.To.ID, fromID, ts, toTypeID, fromTypeID,
+	); err != nil {
+		return fmt.Errorf("create reverse association: %w", err)
+	}
+	return nil
 }
 
-// Get retrieves a single object by ID, optionally filtering properties.
+// Get retrieves a single object by ID, optionally filtering properties. The
+// ID of an object that was merged away resolves to the object it was merged
+// into.
 func (s *SQLiteObjectStore) Get(ctx context.Context, objectType, id string, props []string) (*domain.Object, error) {
 	typeID, err := s.resolveType(ctx, objectType)
 	if err != nil {
@@ -104,14 +240,18 @@ func (s *SQLiteObjectStore) Get(ctx context.Context, objectType, id string, prop
 	}
 
 	var obj domain.Object
-	var archivedAt sql.NullString
+	var archivedAt, mergedInto sql.NullString
 	err = s.db.QueryRowContext(ctx,
-		`SELECT id, archived, archived_at, created_at, updated_at FROM objects WHERE id = ? AND object_type_id = ?`,
+		`SELECT id, archived, archived_at, created_at, updated_at, merged_into_id FROM objects WHERE id = ? AND object_type_id = ?`,
 		id, typeID,
-	).Scan(&obj.ID, &obj.Archived, &archivedAt, &obj.CreatedAt, &obj.UpdatedAt)
+	).Scan(&obj.ID, &obj.Archived, &archivedAt, &obj.CreatedAt, &obj.UpdatedAt, &mergedInto)
 	if err != nil {
 		return nil, fmt.Errorf("get object %s: %w", id, ErrNotFound)
 	}
+	if mergedInto.Valid && mergedInto.String != id {
+		// Merge keeps chains flat, so one hop reaches the surviving object.
+		return s.Get(ctx, objectType, mergedInto.String, props)
+	}
 	if archivedAt.Valid {
 		obj.ArchivedAt = archivedAt.String
 	}
@@ -121,6 +261,9 @@ func (s *SQLiteObjectStore) Get(ctx context.Context, objectType, id string, prop
 	if err != nil {
 		return nil, err
 	}
+	if err := applyCalculated(ctx, s.db, typeID, []*domain.Object{&obj}, requested(props)); err != nil {
+		return nil, err
+	}
 
 	return &obj, nil
 }
@@ -132,18 +275,28 @@ func (s *SQLiteObjectStore) GetByProperty(ctx context.Context, objectType, propN
 		return nil, err
 	}
 
+	objID, err := findByProperty(ctx, s.db, typeID, propName, propValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, objectType, objID, props)
+}
+
+// findByProperty returns the ID of the non-archived object of typeID whose
+// propName equals propValue.
+func findByProperty(ctx context.Context, ex execer, typeID, propName, propValue string) (string, error) {
 	var objID string
-	err = s.db.QueryRowContext(ctx,
+	err := ex.QueryRowContext(ctx,
 		`SELECT o.id FROM objects o
 		 JOIN property_values pv ON pv.object_id = o.id
-		 WHERE o.object_type_id = ? AND pv.property_name = ? AND pv.value = ? AND o.archived = FALSE`,
+		 WHERE o.object_type_id = ? AND pv.property_name = ? AND pv.value = ? COLLATE NOCASE AND o.archived = FALSE`,
 		typeID, propName, propValue,
 	).Scan(&objID)
 	if err != nil {
-		return nil, fmt.Errorf("get object by %s=%s: %w", propName, propValue, ErrNotFound)
+		return "", fmt.Errorf("get object by %s=%s: %w", propName, propValue, ErrNotFound)
 	}
-
-	return s.Get(ctx, objectType, objID, props)
+	return objID, nil
 }
 
 // List returns a paginated list of objects.
@@ -207,6 +360,9 @@ func (s *SQLiteObjectStore) List(ctx context.Context, objectType string, opts do
 			return nil, err
 		}
 	}
+	if err := applyCalculated(ctx, s.db, typeID, page.Results, requested(opts.Properties)); err != nil {
+		return nil, err
+	}
 
 	return page, nil
 }
@@ -218,36 +374,63 @@ func (s *SQLiteObjectStore) Update(ctx context.Context, objectType, id string, p
 		return nil, err
 	}
 
-	// Verify exists.
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return updateObject(ctx, tx, typeID, id, properties, now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getWithAllProps(ctx, objectType, id)
+}
+
+// updateObject merges properties into the non-archived object id of typeID
+// using ex, and bumps its modification timestamps.
+func updateObject(ctx context.Context, ex execer, typeID, id string, properties map[string]string, ts string) error {
 	var exists int
-	err = s.db.QueryRowContext(ctx,
+	err := ex.QueryRowContext(ctx,
 		`SELECT 1 FROM objects WHERE id = ? AND object_type_id = ? AND archived = FALSE`, id, typeID,
 	).Scan(&exists)
 	if err != nil {
-		return nil, fmt.Errorf("object %s not found: %w", id, ErrNotFound)
+		return fmt.Errorf("object %s not found: %w", id, ErrNotFound)
 	}
 
-	ts := now()
+	if err := checkUniqueValues(ctx, ex, typeID, id, properties); err != nil {
+		return err
+	}
+	if err := checkGDPRDeleted(ctx, ex, typeID, properties); err != nil {
+		return err
+	}
+	if err := applyPipeline(ctx, ex, typeID, id, properties); err != nil {
+		return err
+	}
+	if err := applyStageProperties(ctx, ex, typeID, id, properties, ts); err != nil {
+		return err
+	}
 
 	// Add system property update.
 	properties["hs_lastmodifieddate"] = ts
 	properties["lastmodifieddate"] = ts
+	if src := ChangeSourceFrom(ctx); src.UserID > 0 {
+		properties["hs_updated_by_user_id"] = strconv.Itoa(src.UserID)
+	}
 
 	idInt, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("invalid object id: %w", err)
+		return fmt.Errorf("invalid object id: %w", err)
 	}
 
-	if err := s.setProperties(ctx, idInt, properties, ts); err != nil {
-		return nil, err
+	if err := setProperties(ctx, ex, idInt, properties, ts); err != nil {
+		return err
 	}
-
-	_, err = s.db.ExecContext(ctx, `UPDATE objects SET updated_at = ? WHERE id = ?`, ts, id)
-	if err != nil {
-		return nil, fmt.Errorf("update object timestamp: %w", err)
+	if err := releaseGDPRDeleted(ctx, ex, typeID, properties); err != nil {
+		return err
 	}
 
-	return s.getWithAllProps(ctx, objectType, id)
+	if _, err := ex.ExecContext(ctx, `UPDATE objects SET updated_at = ? WHERE id = ?`, ts, id); err != nil {
+		return fmt.Errorf("update object timestamp: %w", err)
+	}
+	return refreshRollupsReading(ctx, ex, typeID, id, properties)
 }
 
 // Archive soft-deletes an object.
@@ -257,8 +440,15 @@ func (s *SQLiteObjectStore) Archive(ctx context.Context, objectType, id string)
 		return err
 	}
 
-	ts := now()
-	res, err := s.db.ExecContext(ctx,
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return archiveObject(ctx, tx, typeID, id, now())
+	})
+}
+
+// archiveObject soft-deletes the non-archived object id of typeID using ex
+// and removes its associations.
+func archiveObject(ctx context.Context, ex execer, typeID, id, ts string) error {
+	res, err := ex.ExecContext(ctx,
 		`UPDATE objects SET archived = TRUE, archived_at = ?, updated_at = ? WHERE id = ? AND object_type_id = ? AND archived = FALSE`,
 		ts, ts, id, typeID,
 	)
@@ -274,21 +464,54 @@ func (s *SQLiteObjectStore) Archive(ctx context.Context, objectType, id string)
 		return fmt.Errorf("object %s: %w", id, ErrNotFound)
 	}
 
-	// Clean up associations for the archived object.
-	_, _ = s.db.ExecContext(ctx,
+	referrers, err := referringObjects(ctx, ex, id)
+	if err != nil {
+		return err
+	}
+	if _, err := ex.ExecContext(ctx,
 		`DELETE FROM associations WHERE from_object_id = ? OR to_object_id = ?`,
 		id, id,
-	)
-
-	return nil
+	); err != nil {
+		return fmt.Errorf("remove associations: %w", err)
+	}
+	return recomputeRollups(ctx, ex, referrers...)
 }
 
-// BatchCreate creates multiple objects in a single operation.
+// BatchCreate creates multiple objects in a single operation. See the
+// ObjectStore documentation for how failed inputs are handled.
 func (s *SQLiteObjectStore) BatchCreate(ctx context.Context, objectType string, inputs []domain.CreateInput) (*domain.BatchResult, error) {
 	startedAt := now()
 	result := &domain.BatchResult{Status: "COMPLETE", StartedAt: startedAt, Results: []*domain.Object{}}
-	for _, input := range inputs {
-		obj, err := s.Create(ctx, objectType, input.Properties)
+	typeID, err := s.resolveType(ctx, objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(inputs))
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, input := range inputs {
+			var id int64
+			err := withSavepoint(ctx, tx, func() error {
+				var err error
+				id, err = s.insertObject(ctx, tx, typeID, input, now())
+				return err
+			})
+			if err != nil {
+				if addUniqueValueError(result, err) || addGDPRDeletedError(result, err) {
+					continue
+				}
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		obj, err := s.getWithAllProps(ctx, objectType, strconv.FormatInt(id, 10))
 		if err != nil {
 			return nil, err
 		}
@@ -298,10 +521,17 @@ func (s *SQLiteObjectStore) BatchCreate(ctx context.Context, objectType string,
 	return result, nil
 }
 
-// BatchRead reads multiple objects by ID.
+// BatchRead reads multiple objects by ID. IDs that match no object are
+// reported together as one error in the result.
 func (s *SQLiteObjectStore) BatchRead(ctx context.Context, objectType string, ids, props []string, idProperty string) (*domain.BatchResult, error) {
 	startedAt := now()
-	result := &domain.BatchResult{Status: "COMPLETE", StartedAt: startedAt}
+	result := &domain.BatchResult{Status: "COMPLETE", StartedAt: startedAt, Results: []*domain.Object{}}
+	typeID, err := s.resolveType(ctx, objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
 	for _, id := range ids {
 		var obj *domain.Object
 		var err error
@@ -310,149 +540,397 @@ func (s *SQLiteObjectStore) BatchRead(ctx context.Context, objectType string, id
 		} else {
 			obj, err = s.Get(ctx, objectType, id, props)
 		}
-		if err != nil {
-			result.NumErrors++
+		if errors.Is(err, ErrNotFound) {
+			missing = append(missing, id)
 			continue
 		}
+		if err != nil {
+			return nil, err
+		}
 		result.Results = append(result.Results, obj)
 	}
+	if len(missing) > 0 {
+		addBatchError(result, notFoundBatchError(objectTypeLabel(ctx, s.db, typeID), missing))
+	}
 	result.CompletedAt = now()
 	return result, nil
 }
 
-// BatchUpdate updates multiple objects.
+// BatchUpdate updates multiple objects. See the ObjectStore documentation
+// for how failed inputs are handled.
 func (s *SQLiteObjectStore) BatchUpdate(ctx context.Context, objectType string, inputs []domain.UpdateInput) (*domain.BatchResult, error) {
 	startedAt := now()
-	result := &domain.BatchResult{Status: "COMPLETE", StartedAt: startedAt}
-	for _, input := range inputs {
-		obj, err := s.Update(ctx, objectType, input.ID, input.Properties)
-		if err != nil {
-			return nil, err
+	result := &domain.BatchResult{Status: "COMPLETE", StartedAt: startedAt, Results: []*domain.Object{}}
+	typeID, err := s.resolveType(ctx, objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated, missing []string
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, input := range inputs {
+			err := withSavepoint(ctx, tx, func() error {
+				return updateObject(ctx, tx, typeID, input.ID, input.Properties, now())
+			})
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					missing = append(missing, input.ID)
+					continue
+				}
+				if addUniqueValueError(result, err) || addGDPRDeletedError(result, err) {
+					continue
+				}
+				return err
+			}
+			updated = append(updated, input.ID)
 		}
-		result.Results = append(result.Results, obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.appendResults(ctx, objectType, result, updated); err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		addBatchError(result, notFoundBatchError(objectTypeLabel(ctx, s.db, typeID), missing))
 	}
 	result.CompletedAt = now()
 	return result, nil
 }
 
-// BatchUpsert creates or updates objects based on a matching property.
+// BatchUpsert creates or updates objects based on a matching property. See
+// the ObjectStore documentation for how failed inputs are handled.
 func (s *SQLiteObjectStore) BatchUpsert(ctx context.Context, objectType string, inputs []domain.UpsertInput, idProperty string) (*domain.BatchResult, error) {
 	if idProperty == "" {
 		idProperty = "hs_object_id"
 	}
 
 	startedAt := now()
-	result := &domain.BatchResult{Status: "COMPLETE", StartedAt: startedAt}
+	result := &domain.BatchResult{Status: "COMPLETE", StartedAt: startedAt, Results: []*domain.Object{}}
+	typeID, err := s.resolveType(ctx, objectType)
+	if err != nil {
+		return nil, err
+	}
 	for _, input := range inputs {
-		lookupValue := input.ID
-		if lookupValue == "" {
-			lookupValue = input.Properties[idProperty]
+		if input.IDProperty != "" {
+			if err := checkIDProperty(ctx, s.db, typeID, input.IDProperty); err != nil {
+				return nil, err
+			}
 		}
+	}
+	if err := checkIDProperty(ctx, s.db, typeID, idProperty); err != nil {
+		return nil, err
+	}
 
-		existing, err := s.GetByProperty(ctx, objectType, idProperty, lookupValue, nil)
-		if err != nil {
-			// Not found — create.
-			obj, createErr := s.Create(ctx, objectType, input.Properties)
-			if createErr != nil {
-				return nil, createErr
+	var written []string
+	created := map[string]bool{}
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, input := range inputs {
+			prop := input.IDProperty
+			if prop == "" {
+				prop = idProperty
 			}
-			result.Results = append(result.Results, obj)
-		} else {
-			// Found — update.
-			obj, updateErr := s.Update(ctx, objectType, existing.ID, input.Properties)
-			if updateErr != nil {
-				return nil, updateErr
+			lookupValue := input.ID
+			if lookupValue == "" {
+				lookupValue = input.Properties[prop]
+			}
+
+			var id string
+			err := withSavepoint(ctx, tx, func() error {
+				existingID, err := findByProperty(ctx, tx, typeID, prop, lookupValue)
+				if err != nil {
+					// Not found — create, keeping the value it was looked up by.
+					props := input.Properties
+					if prop != "hs_object_id" && lookupValue != "" && props[prop] == "" {
+						props = maps.Clone(props)
+						if props == nil {
+							props = map[string]string{}
+						}
+						props[prop] = lookupValue
+					}
+					newID, err := s.insertObject(ctx, tx, typeID, domain.CreateInput{Properties: props}, now())
+					id = strconv.FormatInt(newID, 10)
+					created[id] = true
+					return err
+				}
+				// Found — update.
+				id = existingID
+				return updateObject(ctx, tx, typeID, existingID, input.Properties, now())
+			})
+			if err != nil {
+				if addUniqueValueError(result, err) || addGDPRDeletedError(result, err) {
+					continue
+				}
+				return err
 			}
-			result.Results = append(result.Results, obj)
+			written = append(written, id)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.appendResults(ctx, objectType, result, written); err != nil {
+		return nil, err
+	}
+	for _, obj := range result.Results {
+		isNew := created[obj.ID]
+		obj.New = &isNew
 	}
 	result.CompletedAt = now()
 	return result, nil
 }
 
-// BatchArchive archives multiple objects.
-func (s *SQLiteObjectStore) BatchArchive(ctx context.Context, objectType string, ids []string) error {
+// checkIDProperty returns an error wrapping ErrInvalidIDProperty unless name
+// is hs_object_id or a hasUniqueValue property of typeID.
+func checkIDProperty(ctx context.Context, ex execer, typeID, name string) error {
+	if name == "hs_object_id" {
+		return nil
+	}
+	var unique bool
+	err := ex.QueryRowContext(ctx,
+		`SELECT has_unique_value FROM property_definitions WHERE object_type_id = ? AND name = ? AND archived = FALSE`,
+		typeID, name,
+	).Scan(&unique)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: property %s does not exist", ErrInvalidIDProperty, name)
+	}
+	if err != nil {
+		return fmt.Errorf("check id property: %w", err)
+	}
+	if !unique {
+		return fmt.Errorf("%w: property %s is not a unique identifier", ErrInvalidIDProperty, name)
+	}
+	return nil
+}
+
+// appendResults loads the objects with the given IDs, with all their
+// properties, into result.
+func (s *SQLiteObjectStore) appendResults(ctx context.Context, objectType string, result *domain.BatchResult, ids []string) error {
 	for _, id := range ids {
-		if err := s.Archive(ctx, objectType, id); err != nil {
+		obj, err := s.getWithAllProps(ctx, objectType, id)
+		if err != nil {
 			return err
 		}
+		result.Results = append(result.Results, obj)
 	}
 	return nil
 }
 
-// Merge merges one object into another. The primary survives; the merged
-// object is archived.
-func (s *SQLiteObjectStore) Merge(ctx context.Context, objectType, primaryID, mergeID string) (*domain.Object, error) {
+// BatchArchive archives multiple objects. See the ObjectStore documentation
+// for how failed inputs are handled.
+func (s *SQLiteObjectStore) BatchArchive(ctx context.Context, objectType string, ids []string) (*domain.BatchResult, error) {
+	startedAt := now()
+	result := &domain.BatchResult{Status: "COMPLETE", StartedAt: startedAt, Results: []*domain.Object{}}
 	typeID, err := s.resolveType(ctx, objectType)
 	if err != nil {
 		return nil, err
 	}
 
-	// Verify both exist.
-	for _, id := range []string{primaryID, mergeID} {
-		var exists int
-		err := s.db.QueryRowContext(ctx,
-			`SELECT 1 FROM objects WHERE id = ? AND object_type_id = ? AND archived = FALSE`, id, typeID,
-		).Scan(&exists)
-		if err != nil {
-			return nil, fmt.Errorf("object %s: %w", id, ErrNotFound)
+	var missing []string
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		ts := now()
+		for _, id := range ids {
+			err := withSavepoint(ctx, tx, func() error {
+				return archiveObject(ctx, tx, typeID, id, ts)
+			})
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					missing = append(missing, id)
+					continue
+				}
+				return err
+			}
 		}
-	}
-
-	// Get ALL properties from the merged object.
-	mergedProps, err := s.getAllProperties(ctx, mergeID)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Get ALL properties from the primary object.
-	primaryProps, err := s.getAllProperties(ctx, primaryID)
+	if len(missing) > 0 {
+		addBatchError(result, notFoundBatchError(objectTypeLabel(ctx, s.db, typeID), missing))
+	}
+	result.CompletedAt = now()
+	return result, nil
+}
+
+// Merge merges one object into another. The primary survives; the merged
+// object is archived. All writes happen in one transaction.
+func (s *SQLiteObjectStore) Merge(ctx context.Context, objectType, primaryID, mergeID string) (*domain.Object, error) {
+	typeID, err := s.resolveType(ctx, objectType)
 	if err != nil {
 		return nil, err
 	}
 
-	// Copy unique properties from merged to primary (don't overwrite existing).
-	ts := now()
-	propsToSet := map[string]string{}
-	for k, v := range mergedProps {
-		if _, has := primaryProps[k]; !has {
-			propsToSet[k] = v
+	// Attribute the copied properties to the merge, keeping the acting user.
+	src := ChangeSourceFrom(ctx)
+	ctx = WithChangeSource(ctx, domain.ChangeSource{Type: domain.SourceMerge, ID: mergeID, UserID: src.UserID})
+
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		// Verify both exist.
+		for _, id := range []string{primaryID, mergeID} {
+			var exists int
+			err := tx.QueryRowContext(ctx,
+				`SELECT 1 FROM objects WHERE id = ? AND object_type_id = ? AND archived = FALSE`, id, typeID,
+			).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("object %s: %w", id, ErrNotFound)
+			}
 		}
-	}
 
-	// Record merged IDs.
-	existing := primaryProps["hs_merged_object_ids"]
-	if existing != "" {
-		propsToSet["hs_merged_object_ids"] = existing + ";" + mergeID
-	} else {
-		propsToSet["hs_merged_object_ids"] = mergeID
-	}
-	propsToSet["hs_lastmodifieddate"] = ts
-	propsToSet["lastmodifieddate"] = ts
+		// Get ALL properties from the merged object.
+		mergedProps, err := getAllProperties(ctx, tx, mergeID)
+		if err != nil {
+			return err
+		}
+
+		// Get ALL properties from the primary object.
+		primaryProps, err := getAllProperties(ctx, tx, primaryID)
+		if err != nil {
+			return err
+		}
 
-	primaryIDInt, err := strconv.ParseInt(primaryID, 10, 64)
+		// Copy unique properties from merged to primary (don't overwrite existing).
+		ts := now()
+		propsToSet := map[string]string{}
+		for k, v := range mergedProps {
+			if _, has := primaryProps[k]; !has {
+				propsToSet[k] = v
+			}
+		}
+
+		// Record merged IDs, including any the merged object had absorbed.
+		var mergedIDs []string
+		for _, ids := range []string{primaryProps["hs_merged_object_ids"], mergeID, mergedProps["hs_merged_object_ids"]} {
+			if ids != "" {
+				mergedIDs = append(mergedIDs, strings.Split(ids, ";")...)
+			}
+		}
+		propsToSet["hs_merged_object_ids"] = strings.Join(mergedIDs, ";")
+		propsToSet["hs_canonical_object_id"] = primaryID
+		propsToSet["hs_lastmodifieddate"] = ts
+		propsToSet["lastmodifieddate"] = ts
+		if src.UserID > 0 {
+			propsToSet["hs_updated_by_user_id"] = strconv.Itoa(src.UserID)
+		}
+
+		primaryIDInt, err := strconv.ParseInt(primaryID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid primary id: %w", err)
+		}
+
+		// Move related records first so the merge's own writes are the
+		// newest history entries.
+		if err := moveMergedRecords(ctx, tx, primaryID, mergeID); err != nil {
+			return err
+		}
+
+		if err := setProperties(ctx, tx, primaryIDInt, propsToSet, ts); err != nil {
+			return err
+		}
+
+		// Update primary timestamp.
+		if _, err := tx.ExecContext(ctx, `UPDATE objects SET updated_at = ? WHERE id = ?`, ts, primaryID); err != nil {
+			return fmt.Errorf("update primary: %w", err)
+		}
+
+		// Archive the merged object and point it, and anything previously
+		// merged into it, at the primary.
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE objects SET archived = TRUE, archived_at = ?, updated_at = ?, merged_into_id = ? WHERE id = ?`,
+			ts, ts, primaryID, mergeID,
+		); err != nil {
+			return fmt.Errorf("archive merged: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE objects SET merged_into_id = ? WHERE merged_into_id = ?`, primaryID, mergeID,
+		); err != nil {
+			return fmt.Errorf("redirect merged objects: %w", err)
+		}
+		return refreshRollups(ctx, tx, primaryID)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("invalid primary id: %w", err)
+		return nil, err
 	}
 
-	if err := s.setProperties(ctx, primaryIDInt, propsToSet, ts); err != nil {
-		return nil, err
+	return s.getWithAllProps(ctx, objectType, primaryID)
+}
+
+// moveMergedRecords moves the associations and list memberships of mergeID
+// to primaryID and copies its property history. Associations and memberships
+// the primary already has are kept once, and associations between the two
+// objects are dropped.
+func moveMergedRecords(ctx context.Context, ex execer, primaryID, mergeID string) error {
+	stmts := []struct {
+		query string
+		args  []any
+	}{
+		{`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at)
+		  SELECT ?, to_object_id, association_type_id, created_at FROM associations
+		  WHERE from_object_id = ? AND to_object_id != ?`, []any{primaryID, mergeID, primaryID}},
+		{`INSERT OR IGNORE INTO associations (from_object_id, to_object_id, association_type_id, created_at)
+		  SELECT from_object_id, ?, association_type_id, created_at FROM associations
+		  WHERE to_object_id = ? AND from_object_id != ?`, []any{primaryID, mergeID, primaryID}},
+		{`DELETE FROM associations WHERE from_object_id = ? OR to_object_id = ?`, []any{mergeID, mergeID}},
+		{`INSERT OR IGNORE INTO list_memberships (list_id, object_id, added_at)
+		  SELECT list_id, ?, added_at FROM list_memberships WHERE object_id = ?`, []any{primaryID, mergeID}},
+		{`DELETE FROM list_memberships WHERE object_id = ?`, []any{mergeID}},
+		{`INSERT INTO property_value_history (object_id, property_name, value, timestamp, source, source_id, source_label, updated_by_user_id)
+		  SELECT ?, property_name, value, timestamp, source, source_id, source_label, updated_by_user_id
+		  FROM property_value_history
+		  WHERE object_id = ? AND property_name NOT IN ('hs_object_id', 'hs_merged_object_ids', 'hs_canonical_object_id')
+		  ORDER BY id`, []any{primaryID, mergeID}},
+	}
+	for _, st := range stmts {
+		if _, err := ex.ExecContext(ctx, st.query, st.args...); err != nil {
+			return fmt.Errorf("move merged records: %w", err)
+		}
 	}
+	return nil
+}
 
-	// Update primary timestamp.
-	if _, err := s.db.ExecContext(ctx, `UPDATE objects SET updated_at = ? WHERE id = ?`, ts, primaryID); err != nil {
-		return nil, fmt.Errorf("update primary: %w", err)
+// GetPropertyHistory returns the recorded values of the named properties,
+// newest first. Properties without history are omitted.
+func (s *SQLiteObjectStore) GetPropertyHistory(ctx context.Context, objectID string, props []string) (map[string][]domain.PropertyHistory, error) {
+	result := make(map[string][]domain.PropertyHistory)
+	if len(props) == 0 {
+		return result, nil
 	}
 
-	// Archive the merged object and set merged_into_id.
-	if _, err := s.db.ExecContext(ctx,
-		`UPDATE objects SET archived = TRUE, archived_at = ?, updated_at = ?, merged_into_id = ? WHERE id = ?`,
-		ts, ts, primaryID, mergeID,
-	); err != nil {
-		return nil, fmt.Errorf("archive merged: %w", err)
+	placeholders := make([]string, len(props))
+	args := make([]any, 0, len(props)+1)
+	args = append(args, objectID)
+	for i, p := range props {
+		placeholders[i] = "?"
+		args = append(args, p)
 	}
 
-	return s.getWithAllProps(ctx, objectType, primaryID)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT property_name, COALESCE(value,''), timestamp, COALESCE(source,'API'), COALESCE(source_id,''),
+		 COALESCE(source_label,''), COALESCE(updated_by_user_id,0)
+		 FROM property_value_history
+		 WHERE object_id = ? AND property_name IN (`+strings.Join(placeholders, ",")+`)
+		 ORDER BY timestamp DESC, id DESC`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get property history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var name string
+		var h domain.PropertyHistory
+		if err := rows.Scan(&name, &h.Value, &h.Timestamp, &h.SourceType, &h.SourceID, &h.SourceLabel, &h.UpdatedByUserID); err != nil {
+			return nil, fmt.Errorf("scan property history: %w", err)
+		}
+		result[name] = append(result[name], h)
+	}
+	return result, rows.Err()
 }
 
 // getWithAllProps retrieves an object with ALL its properties (used by
@@ -496,13 +974,16 @@ func (s *SQLiteObjectStore) getWithAllProps(ctx context.Context, objectType, id
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration: %w", err)
 	}
+	if err := applyCalculated(ctx, s.db, typeID, []*domain.Object{&obj}, allCalculated); err != nil {
+		return nil, err
+	}
 
 	return &obj, nil
 }
 
 // getAllProperties fetches every property value for an object.
-func (s *SQLiteObjectStore) getAllProperties(ctx context.Context, objectID string) (map[string]string, error) {
-	rows, err := s.db.QueryContext(ctx,
+func getAllProperties(ctx context.Context, ex execer, objectID string) (map[string]string, error) {
+	rows, err := ex.QueryContext(ctx,
 		`SELECT property_name, value FROM property_values WHERE object_id = ?`, objectID,
 	)
 	if err != nil {
@@ -521,21 +1002,73 @@ func (s *SQLiteObjectStore) getAllProperties(ctx context.Context, objectID strin
 	return result, rows.Err()
 }
 
-// setProperties upserts property values and records history.
-func (s *SQLiteObjectStore) setProperties(ctx context.Context, objectID int64, props map[string]string, ts string) error {
+// getObjectsProperties returns all property values of each of objectIDs,
+// keyed by object ID, in one query.
+func getObjectsProperties(ctx context.Context, ex execer, objectIDs []string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string, len(objectIDs))
+	if len(objectIDs) == 0 {
+		return result, nil
+	}
+	placeholders := make([]string, len(objectIDs))
+	args := make([]any, len(objectIDs))
+	for i, id := range objectIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	rows, err := ex.QueryContext(ctx,
+		`SELECT object_id, property_name, value FROM property_values
+		 WHERE object_id IN (`+strings.Join(placeholders, ",")+`)`, args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get object properties: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var id, name, value string
+		if err := rows.Scan(&id, &name, &value); err != nil {
+			return nil, fmt.Errorf("scan property: %w", err)
+		}
+		if result[id] == nil {
+			result[id] = make(map[string]string)
+		}
+		result[id][name] = value
+	}
+	return result, rows.Err()
+}
+
+// setProperties upserts property values, records history and keeps the
+// full-text index in step, attributing each change to the change source in
+// ctx.
+func setProperties(ctx context.Context, ex execer, objectID int64, props map[string]string, ts string) error {
+	src := ChangeSourceFrom(ctx)
+	var userID any
+	if src.UserID > 0 {
+		userID = src.UserID
+	}
+
 	for name, value := range props {
-		_, err := s.db.ExecContext(ctx,
-			`INSERT INTO property_values (object_id, property_name, value, updated_at) VALUES (?, ?, ?, ?)
-			 ON CONFLICT(object_id, property_name) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
-			objectID, name, value, ts,
-		)
+		var rowID int64
+		err := ex.QueryRowContext(ctx,
+			`INSERT INTO property_values (object_id, property_name, value, updated_at, source, source_id, source_label, updated_by_user_id)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			 ON CONFLICT(object_id, property_name) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at,
+			 source = excluded.source, source_id = excluded.source_id, source_label = excluded.source_label,
+			 updated_by_user_id = excluded.updated_by_user_id
+			 RETURNING rowid`,
+			objectID, name, value, ts, src.Type, src.ID, src.Label, userID,
+		).Scan(&rowID)
 		if err != nil {
 			return fmt.Errorf("set property %s: %w", name, err)
 		}
+		if err := indexPropertyValue(ctx, ex, rowID, objectID, name, value); err != nil {
+			return err
+		}
 
-		_, err = s.db.ExecContext(ctx,
-			`INSERT INTO property_value_history (object_id, property_name, value, timestamp) VALUES (?, ?, ?, ?)`,
-			objectID, name, value, ts,
+		_, err = ex.ExecContext(ctx,
+			`INSERT INTO property_value_history (object_id, property_name, value, timestamp, source, source_id, source_label, updated_by_user_id)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			objectID, name, value, ts, src.Type, src.ID, src.Label, userID,
 		)
 		if err != nil {
 			return fmt.Errorf("record property history %s: %w", name, err)
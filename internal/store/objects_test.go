@@ -2,6 +2,7 @@ package store_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/johnwards/hubspot/internal/database"
@@ -279,6 +280,7 @@ func TestBatchUpdate(t *testing.T) {
 
 	result, err := s.BatchUpdate(ctx, "contacts", []domain.UpdateInput{
 		{ID: obj.ID, Properties: map[string]string{"firstname": "New"}},
+		{ID: "999999", Properties: map[string]string{"firstname": "Nobody"}},
 	})
 	if err != nil {
 		t.Fatalf("batch update: %v", err)
@@ -286,6 +288,9 @@ func TestBatchUpdate(t *testing.T) {
 	if len(result.Results) != 1 {
 		t.Fatalf("expected 1 result, got %d", len(result.Results))
 	}
+	if result.NumErrors != 1 || result.Errors[0].Category != "OBJECT_NOT_FOUND" {
+		t.Errorf("expected one OBJECT_NOT_FOUND error, got %+v", result.Errors)
+	}
 }
 
 func TestBatchArchive(t *testing.T) {
@@ -295,10 +300,13 @@ func TestBatchArchive(t *testing.T) {
 	obj1, _ := s.Create(ctx, "contacts", map[string]string{"email": "a1@example.com"})
 	obj2, _ := s.Create(ctx, "contacts", map[string]string{"email": "a2@example.com"})
 
-	err := s.BatchArchive(ctx, "contacts", []string{obj1.ID, obj2.ID})
+	result, err := s.BatchArchive(ctx, "contacts", []string{obj1.ID, obj2.ID, "999999"})
 	if err != nil {
 		t.Fatalf("batch archive: %v", err)
 	}
+	if result.NumErrors != 1 || result.Errors[0].Context["ids"][0] != "999999" {
+		t.Errorf("expected one error for the missing id, got %+v", result.Errors)
+	}
 
 	page, _ := s.List(ctx, "contacts", domain.ListOpts{})
 	if len(page.Results) != 0 {
@@ -339,13 +347,95 @@ func TestMerge(t *testing.T) {
 		t.Errorf("expected hs_merged_object_ids=%s, got %s", merged.ID, got.Properties["hs_merged_object_ids"])
 	}
 
-	// Merged object should be archived.
-	mergedObj, err := s.Get(ctx, "contacts", merged.ID, nil)
+	if got.Properties["hs_canonical_object_id"] != "" {
+		t.Errorf("expected hs_canonical_object_id to be omitted without being requested")
+	}
+
+	// The merged ID resolves to the primary.
+	mergedObj, err := s.Get(ctx, "contacts", merged.ID, []string{"hs_canonical_object_id"})
 	if err != nil {
-		t.Fatalf("get archived merged: %v", err)
+		t.Fatalf("get merged id: %v", err)
 	}
-	if !mergedObj.Archived {
-		t.Error("expected merged object to be archived")
+	if mergedObj.ID != primary.ID || mergedObj.Archived {
+		t.Errorf("expected merged id to return active primary %s, got %s (archived=%v)", primary.ID, mergedObj.ID, mergedObj.Archived)
+	}
+	if mergedObj.Properties["hs_canonical_object_id"] != primary.ID {
+		t.Errorf("expected hs_canonical_object_id=%s, got %s", primary.ID, mergedObj.Properties["hs_canonical_object_id"])
+	}
+}
+
+func TestMergeMovesRelatedRecords(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	assocs := store.NewSQLiteAssociationStore(db)
+	lists := store.NewSQLiteListStore(db)
+	ctx := context.Background()
+
+	primary, _ := s.Create(ctx, "contacts", map[string]string{"email": "keep@example.com"})
+	first, _ := s.Create(ctx, "contacts", map[string]string{"email": "first@example.com", "jobtitle": "CTO"})
+	second, _ := s.Create(ctx, "contacts", map[string]string{"email": "second@example.com"})
+	shared, _ := s.Create(ctx, "companies", map[string]string{"name": "Shared"})
+	other, _ := s.Create(ctx, "companies", map[string]string{"name": "Other"})
+
+	for _, pair := range [][2]string{{primary.ID, shared.ID}, {first.ID, shared.ID}, {first.ID, other.ID}} {
+		if _, err := assocs.AssociateDefault(ctx, "contacts", pair[0], "companies", pair[1]); err != nil {
+			t.Fatalf("associate: %v", err)
+		}
+	}
+	list, err := lists.Create(ctx, "merge list", "0-1", "MANUAL", nil)
+	if err != nil {
+		t.Fatalf("create list: %v", err)
+	}
+	if _, err := lists.AddMembers(ctx, list.ListID, []string{primary.ID, first.ID}); err != nil {
+		t.Fatalf("add members: %v", err)
+	}
+
+	// Merge first into second, then second into primary: the chain collapses.
+	if _, err := s.Merge(ctx, "contacts", second.ID, first.ID); err != nil {
+		t.Fatalf("merge first: %v", err)
+	}
+	result, err := s.Merge(ctx, "contacts", primary.ID, second.ID)
+	if err != nil {
+		t.Fatalf("merge second: %v", err)
+	}
+	if got, want := result.Properties["hs_merged_object_ids"], second.ID+";"+first.ID; got != want {
+		t.Errorf("expected hs_merged_object_ids=%s, got %s", want, got)
+	}
+
+	got, err := s.Get(ctx, "contacts", first.ID, nil)
+	if err != nil {
+		t.Fatalf("get first: %v", err)
+	}
+	if got.ID != primary.ID {
+		t.Errorf("expected first to resolve to %s, got %s", primary.ID, got.ID)
+	}
+
+	companies, err := assocs.GetAssociations(ctx, "contacts", primary.ID, "companies")
+	if err != nil {
+		t.Fatalf("get associations: %v", err)
+	}
+	if len(companies) != 2 {
+		t.Errorf("expected 2 de-duplicated company associations, got %d", len(companies))
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM associations WHERE from_object_id IN (?, ?) OR to_object_id IN (?, ?)`,
+		first.ID, second.ID, first.ID, second.ID); n != 0 {
+		t.Errorf("expected no associations left on merged objects, got %d", n)
+	}
+
+	members, err := lists.GetMemberships(ctx, list.ListID, "", 100)
+	if err != nil {
+		t.Fatalf("get memberships: %v", err)
+	}
+	if len(members.Results) != 1 || members.Results[0].RecordID != primary.ID {
+		t.Errorf("expected only the primary in the list, got %+v", members.Results)
+	}
+
+	history, err := s.GetPropertyHistory(ctx, primary.ID, []string{"email"})
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(history["email"]) != 3 {
+		t.Errorf("expected combined email history of 3 values, got %d", len(history["email"]))
 	}
 }
 
@@ -364,3 +454,471 @@ func TestCreateWithObjectTypeID(t *testing.T) {
 		t.Fatal("expected non-empty ID")
 	}
 }
+
+func TestGetPropertyHistory(t *testing.T) {
+	s := setupStore(t)
+	ctx := context.Background()
+
+	obj, err := s.Create(ctx, "contacts", map[string]string{"firstname": "First"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := s.Update(ctx, "contacts", obj.ID, map[string]string{"firstname": "Second"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	history, err := s.GetPropertyHistory(ctx, obj.ID, []string{"firstname", "lastname"})
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+
+	versions := history["firstname"]
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 firstname versions, got %d", len(versions))
+	}
+	if versions[0].Value != "Second" || versions[1].Value != "First" {
+		t.Errorf("expected newest first, got %q then %q", versions[0].Value, versions[1].Value)
+	}
+	if versions[0].SourceType != "API" {
+		t.Errorf("expected sourceType=API, got %q", versions[0].SourceType)
+	}
+	if versions[0].Timestamp == "" {
+		t.Error("expected non-empty timestamp")
+	}
+	if _, ok := history["lastname"]; ok {
+		t.Error("expected no entry for a property that was never set")
+	}
+}
+
+func TestChangeSourceRecorded(t *testing.T) {
+	s := setupStore(t)
+	ctx := store.WithChangeSource(context.Background(), domain.ChangeSource{
+		Type:   domain.SourceImport,
+		ID:     "42",
+		Label:  "Spring import",
+		UserID: 1002,
+	})
+
+	obj, err := s.Create(ctx, "contacts", map[string]string{"email": "source@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if obj.Properties["hs_object_source"] != "IMPORT" || obj.Properties["hs_object_source_id"] != "42" {
+		t.Errorf("unexpected object source %q/%q", obj.Properties["hs_object_source"], obj.Properties["hs_object_source_id"])
+	}
+	if obj.Properties["hs_updated_by_user_id"] != "1002" {
+		t.Errorf("expected hs_updated_by_user_id=1002, got %q", obj.Properties["hs_updated_by_user_id"])
+	}
+
+	history, err := s.GetPropertyHistory(ctx, obj.ID, []string{"email"})
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	h := history["email"][0]
+	if h.SourceType != "IMPORT" || h.SourceID != "42" || h.SourceLabel != "Spring import" || h.UpdatedByUserID != 1002 {
+		t.Errorf("unexpected history source %+v", h)
+	}
+
+	// Writes without a source in the context default to API.
+	updated, err := s.Update(context.Background(), "contacts", obj.ID, map[string]string{"email": "source2@example.com"})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	history, err = s.GetPropertyHistory(ctx, updated.ID, []string{"email"})
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	if got := history["email"][0].SourceType; got != "API" {
+		t.Errorf("expected default sourceType=API, got %q", got)
+	}
+}
+
+func TestUniqueValueEnforced(t *testing.T) {
+	s := setupStore(t)
+	ctx := context.Background()
+
+	first, err := s.Create(ctx, "companies", map[string]string{"name": "Acme", "domain": "acme.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	_, err = s.Create(ctx, "companies", map[string]string{"name": "Acme 2", "domain": "ACME.com"})
+	var uv *store.UniqueValueError
+	if !errors.As(err, &uv) {
+		t.Fatalf("expected UniqueValueError, got %v", err)
+	}
+	if !errors.Is(err, store.ErrConflict) {
+		t.Error("expected UniqueValueError to match ErrConflict")
+	}
+	if uv.ExistingID != first.ID || uv.Property != "domain" {
+		t.Errorf("unexpected conflict %+v", uv)
+	}
+	if want := "Company already exists. Existing ID: " + first.ID; err.Error() != want {
+		t.Errorf("expected message %q, got %q", want, err.Error())
+	}
+
+	// Non-unique properties may repeat.
+	if _, err := s.Create(ctx, "companies", map[string]string{"name": "Acme"}); err != nil {
+		t.Errorf("expected duplicate name to be allowed, got %v", err)
+	}
+
+	result, err := s.BatchCreate(ctx, "companies", []domain.CreateInput{
+		{Properties: map[string]string{"domain": "new.com"}},
+		{Properties: map[string]string{"domain": "acme.com"}},
+	})
+	if err != nil {
+		t.Fatalf("batch create: %v", err)
+	}
+	if len(result.Results) != 1 || result.NumErrors != 1 || len(result.Errors) != 1 {
+		t.Errorf("expected 1 result and 1 error, got %d results, %d errors", len(result.Results), result.NumErrors)
+	}
+}
+
+func TestUniqueValueConflictIsDeterministic(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	ps := store.NewSQLitePropertyStore(db)
+	ctx := context.Background()
+
+	if _, err := ps.Create(ctx, "companies", &domain.Property{
+		Name: "ticker", Label: "Ticker", Type: "string", FieldType: "text",
+		GroupName: "companyinformation", HasUniqueValue: true,
+	}); err != nil {
+		t.Fatalf("create ticker: %v", err)
+	}
+	if _, err := s.Create(ctx, "companies", map[string]string{"domain": "acme.com", "ticker": "ACME"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Both unique properties clash; the first by name is always the one
+	// reported.
+	for range 20 {
+		_, err := s.Create(ctx, "companies", map[string]string{"ticker": "ACME", "domain": "acme.com"})
+		var uv *store.UniqueValueError
+		if !errors.As(err, &uv) {
+			t.Fatalf("expected UniqueValueError, got %v", err)
+		}
+		if uv.Property != "domain" {
+			t.Fatalf("conflict on %q, want domain", uv.Property)
+		}
+	}
+}
+
+func TestBatchUpsertIDProperty(t *testing.T) {
+	s := setupStore(t)
+	ctx := context.Background()
+
+	existing, _ := s.Create(ctx, "companies", map[string]string{"domain": "known.com"})
+
+	result, err := s.BatchUpsert(ctx, "companies", []domain.UpsertInput{
+		{ID: "KNOWN.com", Properties: domain.PropertyValues{"name": "Known"}},
+		{ID: "fresh.com", Properties: domain.PropertyValues{"name": "Fresh"}},
+	}, "domain")
+	if err != nil {
+		t.Fatalf("batch upsert: %v", err)
+	}
+	if len(result.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(result.Results))
+	}
+	for _, obj := range result.Results {
+		wantNew := obj.ID != existing.ID
+		if obj.New == nil || *obj.New != wantNew {
+			t.Errorf("object %s: expected new=%v, got %v", obj.ID, wantNew, obj.New)
+		}
+		if wantNew && obj.Properties["domain"] != "fresh.com" {
+			t.Errorf("expected created object to get domain=fresh.com, got %q", obj.Properties["domain"])
+		}
+	}
+
+	_, err = s.BatchUpsert(ctx, "companies", []domain.UpsertInput{
+		{ID: "Known", IDProperty: "name", Properties: domain.PropertyValues{}},
+	}, "domain")
+	if !errors.Is(err, store.ErrInvalidIDProperty) {
+		t.Errorf("expected ErrInvalidIDProperty for a non-unique property, got %v", err)
+	}
+}
+
+func TestCalculatedProperties(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	ps := store.NewSQLitePropertyStore(db)
+	ctx := context.Background()
+
+	if _, err := ps.Create(ctx, "deals", &domain.Property{
+		Name: "kickoff", Label: "Kickoff", Type: "date", FieldType: "date", GroupName: "dealinformation",
+	}); err != nil {
+		t.Fatalf("create kickoff: %v", err)
+	}
+	for _, p := range []domain.Property{
+		{Name: "days_to_close", Type: "number", CalculationFormula: "time_between(kickoff, closedate) / 86400000"},
+		{Name: "deal_size", Type: "string", CalculationFormula: "if days_to_close > 5 then concat(dealname, ' (slow)') else dealname endif"},
+		{Name: "follow_up", Type: "date", CalculationFormula: "closedate + 7 * 86400000"},
+	} {
+		p.Label, p.FieldType, p.GroupName = p.Name, "calculation_equation", "dealinformation"
+		if _, err := ps.Create(ctx, "deals", &p); err != nil {
+			t.Fatalf("create %s: %v", p.Name, err)
+		}
+	}
+
+	obj, err := s.Create(ctx, "deals", map[string]string{
+		"dealname":  "Big",
+		"kickoff":   "2024-01-01",
+		"closedate": "2024-01-11",
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	want := map[string]string{"days_to_close": "10", "deal_size": "Big (slow)", "follow_up": "2024-01-18"}
+	for name, v := range want {
+		if got := obj.Properties[name]; got != v {
+			t.Errorf("create response %s = %q, want %q", name, got, v)
+		}
+	}
+
+	got, err := s.Get(ctx, "deals", obj.ID, []string{"deal_size"})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Properties["deal_size"] != "Big (slow)" {
+		t.Errorf("deal_size = %q, want %q", got.Properties["deal_size"], "Big (slow)")
+	}
+	if _, ok := got.Properties["follow_up"]; ok {
+		t.Error("expected unrequested calculated properties to be omitted")
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM property_values WHERE property_name = 'deal_size'`); n != 0 {
+		t.Errorf("expected calculated values not to be stored, got %d rows", n)
+	}
+
+	// Each object of a page gets its own values, and a changed formula takes
+	// effect on the next read.
+	quick, err := s.Create(ctx, "deals", map[string]string{
+		"dealname":  "Quick",
+		"kickoff":   "2024-01-01",
+		"closedate": "2024-01-03",
+	})
+	if err != nil {
+		t.Fatalf("create quick: %v", err)
+	}
+	listSizes := func(t *testing.T) map[string]string {
+		t.Helper()
+		page, err := s.List(ctx, "deals", domain.ListOpts{Limit: 10, Properties: []string{"deal_size"}})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		sizes := map[string]string{}
+		for _, o := range page.Results {
+			sizes[o.ID] = o.Properties["deal_size"]
+		}
+		return sizes
+	}
+	if sizes := listSizes(t); sizes[obj.ID] != "Big (slow)" || sizes[quick.ID] != "Quick" {
+		t.Errorf("list deal_size = %v, want Big (slow) and Quick", sizes)
+	}
+	if _, err := ps.Update(ctx, "deals", "deal_size", &domain.Property{
+		CalculationFormula: "if days_to_close > 1 then concat(dealname, ' (slow)') else dealname endif",
+	}); err != nil {
+		t.Fatalf("update formula: %v", err)
+	}
+	if sizes := listSizes(t); sizes[quick.ID] != "Quick (slow)" {
+		t.Errorf("after formula change, deal_size = %q, want %q", sizes[quick.ID], "Quick (slow)")
+	}
+
+	// Calculated values are not stored, so searches cannot use them.
+	ss := store.NewSQLiteSearchStore(db)
+	for name, req := range map[string]*domain.SearchRequest{
+		"filter": {FilterGroups: []domain.FilterGroup{{Filters: []domain.Filter{
+			{PropertyName: "days_to_close", Operator: "GT", Value: "5"},
+		}}}},
+		"sort": {Sorts: []domain.Sort{{PropertyName: "deal_size", Direction: "ASCENDING"}}},
+	} {
+		var ve *store.ValidationError
+		if _, err := ss.Search(ctx, "deals", req); !errors.As(err, &ve) {
+			t.Errorf("search %s on calculated property: got %v, want a ValidationError", name, err)
+		}
+	}
+
+	if _, err := ps.Create(ctx, "deals", &domain.Property{
+		Name: "broken", Label: "Broken", Type: "number", FieldType: "calculation_equation",
+		GroupName: "dealinformation", CalculationFormula: "amount *",
+	}); !errors.Is(err, store.ErrInvalidFormula) {
+		t.Errorf("expected ErrInvalidFormula, got %v", err)
+	}
+}
+
+func TestPipelineStageValidation(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	ctx := context.Background()
+
+	var defaultPipeline string
+	if err := db.QueryRowContext(ctx,
+		`SELECT id FROM pipelines WHERE object_type_id = '0-3' ORDER BY display_order, id LIMIT 1`,
+	).Scan(&defaultPipeline); err != nil {
+		t.Fatalf("default pipeline: %v", err)
+	}
+	other, err := store.NewSQLitePipelineStore(db).Create(ctx, "deals", &domain.Pipeline{
+		Label:        "Renewals",
+		DisplayOrder: 1,
+		Stages: []domain.PipelineStage{
+			{Label: "Due", DisplayOrder: 0},
+			{Label: "Renewed", DisplayOrder: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create pipeline: %v", err)
+	}
+	due, renewed := other.Stages[0].ID, other.Stages[1].ID
+
+	t.Run("defaults", func(t *testing.T) {
+		deal, err := s.Create(ctx, "deals", map[string]string{"dealname": "Defaulted"})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if deal.Properties["pipeline"] != defaultPipeline {
+			t.Errorf("pipeline = %q, want %q", deal.Properties["pipeline"], defaultPipeline)
+		}
+		if want := stageID(t, db, "Appointment Scheduled"); deal.Properties["dealstage"] != want {
+			t.Errorf("dealstage = %q, want %q", deal.Properties["dealstage"], want)
+		}
+	})
+
+	t.Run("pipeline inferred from stage", func(t *testing.T) {
+		deal, err := s.Create(ctx, "deals", map[string]string{"dealname": "Renewal", "dealstage": due})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if deal.Properties["pipeline"] != other.ID {
+			t.Errorf("pipeline = %q, want %q", deal.Properties["pipeline"], other.ID)
+		}
+
+		if _, err := s.Update(ctx, "deals", deal.ID, map[string]string{"dealstage": renewed}); err != nil {
+			t.Fatalf("move within pipeline: %v", err)
+		}
+		_, err = s.Update(ctx, "deals", deal.ID, map[string]string{"dealstage": stageID(t, db, "Closed Won")})
+		var se *store.StageError
+		if !errors.As(err, &se) || se.Property != "dealstage" {
+			t.Fatalf("stage of another pipeline: got %v, want a dealstage StageError", err)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, props := range []map[string]string{
+			{"dealstage": "nope"},
+			{"pipeline": "nope"},
+			{"pipeline": defaultPipeline, "dealstage": due},
+		} {
+			_, err := s.Create(ctx, "deals", props)
+			var se *store.StageError
+			if !errors.As(err, &se) {
+				t.Errorf("create %v: got %v, want a StageError", props, err)
+			}
+		}
+	})
+
+	t.Run("archived stage", func(t *testing.T) {
+		if _, err := db.ExecContext(ctx, `UPDATE pipeline_stages SET archived = TRUE WHERE id = ?`, renewed); err != nil {
+			t.Fatalf("archive stage: %v", err)
+		}
+		_, err := s.Create(ctx, "deals", map[string]string{"pipeline": other.ID, "dealstage": renewed})
+		var se *store.StageError
+		if !errors.As(err, &se) {
+			t.Fatalf("got %v, want a StageError", err)
+		}
+		if want := renewed + " was not one of the allowed options: [" + due + "]"; se.Error() != want {
+			t.Errorf("message = %q, want %q", se.Error(), want)
+		}
+	})
+}
+
+func TestStageProperties(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	ctx := context.Background()
+	presentation, won := stageID(t, db, "Presentation Scheduled"), stageID(t, db, "Closed Won")
+
+	deal, err := s.Create(ctx, "deals", map[string]string{"dealname": "Forecast", "amount": "1000", "dealstage": presentation})
+	if err != nil {
+		t.Fatalf("create deal: %v", err)
+	}
+	props := deal.Properties
+	for name, want := range map[string]string{
+		"hs_is_closed":              "false",
+		"hs_is_closed_won":          "false",
+		"hs_deal_stage_probability": "0.4",
+		"hs_forecast_amount":        "400",
+		"closedate":                 "",
+	} {
+		if props[name] != want {
+			t.Errorf("after create: %s = %q, want %q", name, props[name], want)
+		}
+	}
+	entered := props["hs_date_entered_"+presentation]
+	if entered == "" {
+		t.Errorf("hs_date_entered_%s not set", presentation)
+	}
+
+	deal, err = s.Update(ctx, "deals", deal.ID, map[string]string{"amount": "2000"})
+	if err != nil {
+		t.Fatalf("update amount: %v", err)
+	}
+	if got := deal.Properties["hs_forecast_amount"]; got != "800" {
+		t.Errorf("hs_forecast_amount after amount change = %q, want 800", got)
+	}
+
+	deal, err = s.Update(ctx, "deals", deal.ID, map[string]string{"dealstage": won})
+	if err != nil {
+		t.Fatalf("close deal: %v", err)
+	}
+	props = deal.Properties
+	for name, want := range map[string]string{
+		"hs_is_closed":              "true",
+		"hs_is_closed_won":          "true",
+		"hs_deal_stage_probability": "1.0",
+		"hs_forecast_amount":        "2000",
+		"closedate":                 props["hs_date_entered_"+won][:10],
+	} {
+		if props[name] != want {
+			t.Errorf("after close: %s = %q, want %q", name, props[name], want)
+		}
+	}
+	if props["hs_date_entered_"+presentation] != entered {
+		t.Errorf("hs_date_entered_%s changed on exit", presentation)
+	}
+	if props["hs_date_exited_"+presentation] == "" || props["hs_time_in_"+presentation] == "" {
+		t.Errorf("exit of %s not recorded: %v", presentation, props)
+	}
+
+	t.Run("tickets", func(t *testing.T) {
+		var open, closed string
+		if err := db.QueryRowContext(ctx,
+			`SELECT s.id FROM pipeline_stages s JOIN pipelines p ON p.id = s.pipeline_id
+			 WHERE p.object_type_id = '0-5' AND s.metadata LIKE '%"CLOSED"%'`).Scan(&closed); err != nil {
+			t.Fatalf("closed ticket stage: %v", err)
+		}
+		ticket, err := s.Create(ctx, "tickets", map[string]string{"subject": "Broken"})
+		if err != nil {
+			t.Fatalf("create ticket: %v", err)
+		}
+		open = ticket.Properties["hs_pipeline_stage"]
+		if ticket.Properties["closed_date"] != "" {
+			t.Errorf("new ticket has closed_date %q", ticket.Properties["closed_date"])
+		}
+
+		ticket, err = s.Update(ctx, "tickets", ticket.ID, map[string]string{"hs_pipeline_stage": closed})
+		if err != nil {
+			t.Fatalf("close ticket: %v", err)
+		}
+		if ticket.Properties["closed_date"] == "" || ticket.Properties["time_to_close"] == "" {
+			t.Errorf("closing did not set closed_date and time_to_close: %v", ticket.Properties)
+		}
+
+		ticket, err = s.Update(ctx, "tickets", ticket.ID, map[string]string{"hs_pipeline_stage": open})
+		if err != nil {
+			t.Fatalf("reopen ticket: %v", err)
+		}
+		if ticket.Properties["closed_date"] != "" || ticket.Properties["time_to_close"] != "" {
+			t.Errorf("reopening kept closed_date %q and time_to_close %q",
+				ticket.Properties["closed_date"], ticket.Properties["time_to_close"])
+		}
+	})
+}
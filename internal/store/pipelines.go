@@ -88,29 +88,38 @@ func (s *SQLitePipelineStore) Create(ctx context.Context, objectType string, p *
 	}
 
 	ts := now()
-	result, err := s.db.ExecContext(ctx,
-		`INSERT INTO pipelines (object_type_id, label, display_order, archived, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?)`,
-		typeID, p.Label, p.DisplayOrder, false, ts, ts,
-	)
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			`INSERT INTO pipelines (object_type_id, label, display_order, archived, created_at, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?)`,
+			typeID, p.Label, p.DisplayOrder, false, ts, ts,
+		)
+		if err != nil {
+			return fmt.Errorf("create pipeline: %w", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("last insert id: %w", err)
+		}
+		p.ID = strconv.FormatInt(id, 10)
+
+		for i := range p.Stages {
+			created, err := createStageRow(ctx, tx, p.ID, &p.Stages[i])
+			if err != nil {
+				return err
+			}
+			p.Stages[i] = *created
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("create pipeline: %w", err)
+		return nil, err
 	}
 
-	id, _ := result.LastInsertId()
-	p.ID = strconv.FormatInt(id, 10)
 	p.Archived = false
 	p.CreatedAt = ts
 	p.UpdatedAt = ts
-
-	for i := range p.Stages {
-		created, err := s.createStageRow(ctx, p.ID, &p.Stages[i])
-		if err != nil {
-			return nil, err
-		}
-		p.Stages[i] = *created
-	}
-
 	return p, nil
 }
 
@@ -175,30 +184,38 @@ func (s *SQLitePipelineStore) Replace(ctx context.Context, objectType, id string
 	}
 
 	ts := now()
-	_, err = s.db.ExecContext(ctx,
-		`UPDATE pipelines SET label = ?, display_order = ?, updated_at = ? WHERE id = ?`,
-		p.Label, p.DisplayOrder, ts, id,
-	)
+	var newStages []domain.PipelineStage
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE pipelines SET label = ?, display_order = ?, updated_at = ? WHERE id = ?`,
+			p.Label, p.DisplayOrder, ts, id,
+		)
+		if err != nil {
+			return fmt.Errorf("replace pipeline: %w", err)
+		}
+		if p.Stages == nil {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM pipeline_stages WHERE pipeline_id = ?`, id); err != nil {
+			return fmt.Errorf("delete stages for replace: %w", err)
+		}
+		for i := range p.Stages {
+			created, err := createStageRow(ctx, tx, id, &p.Stages[i])
+			if err != nil {
+				return err
+			}
+			newStages = append(newStages, *created)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("replace pipeline: %w", err)
+		return nil, err
 	}
 
 	existing.Label = p.Label
 	existing.DisplayOrder = p.DisplayOrder
 	existing.UpdatedAt = ts
-
 	if p.Stages != nil {
-		if _, err := s.db.ExecContext(ctx, `DELETE FROM pipeline_stages WHERE pipeline_id = ?`, id); err != nil {
-			return nil, fmt.Errorf("delete stages for replace: %w", err)
-		}
-		var newStages []domain.PipelineStage
-		for i := range p.Stages {
-			created, err := s.createStageRow(ctx, id, &p.Stages[i])
-			if err != nil {
-				return nil, err
-			}
-			newStages = append(newStages, *created)
-		}
 		existing.Stages = newStages
 	}
 
@@ -212,24 +229,26 @@ func (s *SQLitePipelineStore) Delete(ctx context.Context, objectType, id string)
 		return err
 	}
 
-	if _, err := s.db.ExecContext(ctx,
-		`DELETE FROM pipeline_stages WHERE pipeline_id = ?`, id); err != nil {
-		return fmt.Errorf("delete pipeline stages: %w", err)
-	}
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM pipeline_stages WHERE pipeline_id = ?`, id); err != nil {
+			return fmt.Errorf("delete pipeline stages: %w", err)
+		}
 
-	result, err := s.db.ExecContext(ctx,
-		`DELETE FROM pipelines WHERE id = ? AND object_type_id = ?`,
-		id, typeID,
-	)
-	if err != nil {
-		return fmt.Errorf("delete pipeline: %w", err)
-	}
+		result, err := tx.ExecContext(ctx,
+			`DELETE FROM pipelines WHERE id = ? AND object_type_id = ?`,
+			id, typeID,
+		)
+		if err != nil {
+			return fmt.Errorf("delete pipeline: %w", err)
+		}
 
-	n, _ := result.RowsAffected()
-	if n == 0 {
-		return fmt.Errorf("pipeline %q not found", id)
-	}
-	return nil
+		n, _ := result.RowsAffected()
+		if n == 0 {
+			return fmt.Errorf("pipeline %q not found", id)
+		}
+		return nil
+	})
 }
 
 // ListStages returns all stages for a pipeline.
@@ -245,7 +264,7 @@ func (s *SQLitePipelineStore) CreateStage(ctx context.Context, objectType, pipel
 	if _, err := s.Get(ctx, objectType, pipelineID); err != nil {
 		return nil, err
 	}
-	return s.createStageRow(ctx, pipelineID, st)
+	return createStageRow(ctx, s.db, pipelineID, st)
 }
 
 // GetStage returns a single stage by ID.
@@ -384,7 +403,7 @@ func (s *SQLitePipelineStore) loadStages(ctx context.Context, pipelineID string)
 	return stages, rows.Err()
 }
 
-func (s *SQLitePipelineStore) createStageRow(ctx context.Context, pipelineID string, st *domain.PipelineStage) (*domain.PipelineStage, error) {
+func createStageRow(ctx context.Context, ex execer, pipelineID string, st *domain.PipelineStage) (*domain.PipelineStage, error) {
 	ts := now()
 	meta := st.Metadata
 	if meta == nil {
@@ -395,7 +414,7 @@ func (s *SQLitePipelineStore) createStageRow(ctx context.Context, pipelineID str
 		return nil, fmt.Errorf("marshal metadata: %w", err)
 	}
 
-	result, err := s.db.ExecContext(ctx,
+	result, err := ex.ExecContext(ctx,
 		`INSERT INTO pipeline_stages (pipeline_id, label, display_order, metadata, archived, created_at, updated_at)
 		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		pipelineID, st.Label, st.DisplayOrder, string(metaJSON), false, ts, ts,
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/johnwards/hubspot/internal/domain"
 )
@@ -17,9 +18,9 @@ type PropertyStore interface {
 	Get(ctx context.Context, objectType string, name string) (*domain.Property, error)
 	Update(ctx context.Context, objectType string, name string, p *domain.Property) (*domain.Property, error)
 	Archive(ctx context.Context, objectType string, name string) error
-	BatchCreate(ctx context.Context, objectType string, props []domain.Property) ([]domain.Property, error)
-	BatchRead(ctx context.Context, objectType string, names []string) ([]domain.Property, error)
-	BatchArchive(ctx context.Context, objectType string, names []string) error
+	BatchCreate(ctx context.Context, objectType string, props []domain.Property) ([]domain.Property, []domain.BatchError, error)
+	BatchRead(ctx context.Context, objectType string, names []string) ([]domain.Property, []domain.BatchError, error)
+	BatchArchive(ctx context.Context, objectType string, names []string) ([]domain.BatchError, error)
 
 	ListGroups(ctx context.Context, objectType string) ([]domain.PropertyGroup, error)
 	CreateGroup(ctx context.Context, objectType string, g *domain.PropertyGroup) (*domain.PropertyGroup, error)
@@ -66,13 +67,13 @@ func decodeOptions(raw sql.NullString) ([]domain.Option, error) {
 
 func scanProperty(row interface{ Scan(dest ...any) error }) (*domain.Property, error) {
 	var p domain.Property
-	var optionsRaw sql.NullString
+	var optionsRaw, formula, rollup sql.NullString
 	err := row.Scan(
 		&p.Name, &p.Label, &p.Type, &p.FieldType,
 		&p.GroupName, &p.Description, &p.DisplayOrder,
 		&p.HasUniqueValue, &p.Hidden, &p.FormField,
-		&p.Calculated, &p.ExternalOptions, &p.HubspotDefined,
-		&optionsRaw, &p.Archived, &p.CreatedAt, &p.UpdatedAt,
+		&p.Calculated, &formula, &p.ExternalOptions, &p.HubspotDefined,
+		&optionsRaw, &rollup, &p.Archived, &p.CreatedAt, &p.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -82,6 +83,13 @@ func scanProperty(row interface{ Scan(dest ...any) error }) (*domain.Property, e
 		return nil, err
 	}
 	p.Options = opts
+	p.CalculationFormula = formula.String
+	if rollup.String != "" {
+		p.Rollup = new(domain.Rollup)
+		if err := json.Unmarshal([]byte(rollup.String), p.Rollup); err != nil {
+			return nil, fmt.Errorf("decode rollup: %w", err)
+		}
+	}
 	if p.HubspotDefined {
 		p.ModificationMetadata = &domain.ModificationMetadata{
 			ReadOnlyDefinition: true,
@@ -90,12 +98,18 @@ func scanProperty(row interface{ Scan(dest ...any) error }) (*domain.Property, e
 			Archivable:         false,
 		}
 	}
+	if p.Calculated {
+		if p.ModificationMetadata == nil {
+			p.ModificationMetadata = &domain.ModificationMetadata{Archivable: true}
+		}
+		p.ModificationMetadata.ReadOnlyValue = true
+	}
 	return &p, nil
 }
 
 const propertyCols = `name, label, type, field_type, group_name, description,
-	display_order, has_unique_value, hidden, form_field, calculated,
-	external_options, hubspot_defined, options, archived, created_at, updated_at`
+	display_order, has_unique_value, hidden, form_field, calculated, calculation_formula,
+	external_options, hubspot_defined, options, rollup, archived, created_at, updated_at`
 
 // List returns all non-archived properties for the given object type.
 func (s *SQLitePropertyStore) List(ctx context.Context, objectType string) ([]domain.Property, error) {
@@ -124,37 +138,58 @@ func (s *SQLitePropertyStore) List(ctx context.Context, objectType string) ([]do
 	return props, rows.Err()
 }
 
-// Create inserts a new property definition.
+// Create inserts a new property definition. A rollup property is filled in
+// for every existing object in the same transaction.
 func (s *SQLitePropertyStore) Create(ctx context.Context, objectType string, p *domain.Property) (*domain.Property, error) {
 	typeID, err := s.resolveType(ctx, objectType)
 	if err != nil {
 		return nil, err
 	}
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return insertProperty(ctx, tx, typeID, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
 
+// insertProperty writes a property definition row for typeID and sets its
+// timestamps. A property with a calculation formula is marked calculated.
+func insertProperty(ctx context.Context, ex execer, typeID string, p *domain.Property) error {
+	if err := checkFormula(p); err != nil {
+		return err
+	}
+	rollup, err := checkRollup(ctx, ex, p)
+	if err != nil {
+		return err
+	}
 	ts := now()
 	p.CreatedAt = ts
 	p.UpdatedAt = ts
 
 	optStr, err := encodeOptions(p.Options)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	_, err = s.db.ExecContext(ctx,
+	_, err = ex.ExecContext(ctx,
 		`INSERT INTO property_definitions (
 			object_type_id, name, label, type, field_type, group_name, description,
-			display_order, has_unique_value, hidden, form_field, calculated,
-			external_options, hubspot_defined, options, archived, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, FALSE, ?, ?)`,
+			display_order, has_unique_value, hidden, form_field, calculated, calculation_formula,
+			external_options, hubspot_defined, options, rollup, archived, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, FALSE, ?, ?)`,
 		typeID, p.Name, p.Label, p.Type, p.FieldType, p.GroupName, p.Description,
-		p.DisplayOrder, p.HasUniqueValue, p.Hidden, p.FormField, p.Calculated,
-		p.ExternalOptions, p.HubspotDefined, optStr, ts, ts,
+		p.DisplayOrder, p.HasUniqueValue, p.Hidden, p.FormField, p.Calculated, p.CalculationFormula,
+		p.ExternalOptions, p.HubspotDefined, optStr, rollup, ts, ts,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("create property: %w", err)
+		return fmt.Errorf("create property: %w", err)
 	}
-
-	return p, nil
+	if p.Rollup != nil {
+		return recomputeRollupsOfType(ctx, ex, typeID)
+	}
+	return nil
 }
 
 // Get retrieves a single property definition by name.
@@ -178,35 +213,68 @@ func (s *SQLitePropertyStore) Get(ctx context.Context, objectType, name string)
 	return p, nil
 }
 
-// Update modifies an existing property definition.
+// Update modifies an existing property definition. When its rollup
+// definition changes, the rollup is recomputed for every object of the type;
+// when it is removed, the stored rollup values are cleared. A property left
+// with neither a formula nor a rollup is no longer calculated.
 func (s *SQLitePropertyStore) Update(ctx context.Context, objectType, name string, p *domain.Property) (*domain.Property, error) {
 	typeID, err := s.resolveType(ctx, objectType)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := checkFormula(p); err != nil {
+		return nil, err
+	}
 	ts := now()
 	optStr, err := encodeOptions(p.Options)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := s.db.ExecContext(ctx,
-		`UPDATE property_definitions SET
-			label = ?, description = ?, group_name = ?, field_type = ?,
-			display_order = ?, options = ?, hidden = ?, form_field = ?,
-			updated_at = ?
-		 WHERE object_type_id = ? AND name = ? AND archived = FALSE`,
-		p.Label, p.Description, p.GroupName, p.FieldType,
-		p.DisplayOrder, optStr, p.Hidden, p.FormField,
-		ts, typeID, name,
-	)
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var oldFormula, oldRollup sql.NullString
+		err := tx.QueryRowContext(ctx,
+			`SELECT calculation_formula, rollup FROM property_definitions WHERE object_type_id = ? AND name = ? AND archived = FALSE`,
+			typeID, name,
+		).Scan(&oldFormula, &oldRollup)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("property %q not found", name)
+		}
+		if err != nil {
+			return fmt.Errorf("update property: %w", err)
+		}
+		if (oldFormula.String != "" || oldRollup.String != "") && p.CalculationFormula == "" && p.Rollup == nil {
+			p.Calculated = false
+		}
+		rollup, err := checkRollup(ctx, tx, p)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE property_definitions SET
+				label = ?, description = ?, group_name = ?, field_type = ?,
+				display_order = ?, options = ?, hidden = ?, form_field = ?,
+				calculated = ?, calculation_formula = ?, rollup = ?, updated_at = ?
+			 WHERE object_type_id = ? AND name = ? AND archived = FALSE`,
+			p.Label, p.Description, p.GroupName, p.FieldType,
+			p.DisplayOrder, optStr, p.Hidden, p.FormField,
+			p.Calculated, p.CalculationFormula, rollup, ts, typeID, name,
+		); err != nil {
+			return fmt.Errorf("update property: %w", err)
+		}
+		newRollup, _ := rollup.(string)
+		switch {
+		case newRollup != "" && newRollup != oldRollup.String:
+			return recomputeRollupsOfType(ctx, tx, typeID)
+		case newRollup == "" && oldRollup.String != "":
+			return clearRollupValues(ctx, tx, typeID, name)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("update property: %w", err)
-	}
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return nil, fmt.Errorf("property %q not found", name)
+		return nil, err
 	}
 
 	return s.Get(ctx, objectType, name)
@@ -234,40 +302,120 @@ func (s *SQLitePropertyStore) Archive(ctx context.Context, objectType, name stri
 	return nil
 }
 
-// BatchCreate inserts multiple property definitions.
-func (s *SQLitePropertyStore) BatchCreate(ctx context.Context, objectType string, props []domain.Property) ([]domain.Property, error) {
-	var results []domain.Property
-	for i := range props {
-		created, err := s.Create(ctx, objectType, &props[i])
-		if err != nil {
-			return nil, err
+// BatchCreate inserts multiple property definitions in one transaction.
+// Properties whose name is already taken are skipped and reported as errors;
+// any other failure rolls back the whole batch.
+func (s *SQLitePropertyStore) BatchCreate(ctx context.Context, objectType string, props []domain.Property) ([]domain.Property, []domain.BatchError, error) {
+	typeID, err := s.resolveType(ctx, objectType)
+	if err != nil {
+		return nil, nil, err
+	}
+	results := []domain.Property{}
+	var errs []domain.BatchError
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for i := range props {
+			err := withSavepoint(ctx, tx, func() error {
+				return insertProperty(ctx, tx, typeID, &props[i])
+			})
+			if err != nil {
+				if errors.Is(err, ErrInvalidFormula) || errors.Is(err, ErrInvalidRollup) {
+					errs = append(errs, domain.BatchError{
+						Status:   "error",
+						Category: "VALIDATION_ERROR",
+						Message:  err.Error(),
+						Context:  map[string][]string{"ids": {props[i].Name}},
+					})
+					continue
+				}
+				if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+					errs = append(errs, domain.BatchError{
+						Status:   "error",
+						Category: "OBJECT_ALREADY_EXISTS",
+						Message:  fmt.Sprintf("A property named %q already exists.", props[i].Name),
+						Context:  map[string][]string{"ids": {props[i].Name}},
+					})
+					continue
+				}
+				return err
+			}
+			results = append(results, props[i])
 		}
-		results = append(results, *created)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
-	return results, nil
+	return results, errs, nil
 }
 
-// BatchRead retrieves multiple property definitions by name.
-func (s *SQLitePropertyStore) BatchRead(ctx context.Context, objectType string, names []string) ([]domain.Property, error) {
-	var results []domain.Property
+// BatchRead retrieves multiple property definitions by name. Names that
+// match no definition are reported together as one error.
+func (s *SQLitePropertyStore) BatchRead(ctx context.Context, objectType string, names []string) ([]domain.Property, []domain.BatchError, error) {
+	typeID, err := s.resolveType(ctx, objectType)
+	if err != nil {
+		return nil, nil, err
+	}
+	results := []domain.Property{}
+	var missing []string
 	for _, name := range names {
-		p, err := s.Get(ctx, objectType, name)
+		row := s.db.QueryRowContext(ctx,
+			`SELECT `+propertyCols+` FROM property_definitions
+			 WHERE object_type_id = ? AND name = ?`, typeID, name)
+		p, err := scanProperty(row)
+		if errors.Is(err, sql.ErrNoRows) {
+			missing = append(missing, name)
+			continue
+		}
 		if err != nil {
-			return nil, err
+			return nil, nil, fmt.Errorf("get property: %w", err)
 		}
 		results = append(results, *p)
 	}
-	return results, nil
+	var errs []domain.BatchError
+	if len(missing) > 0 {
+		errs = append(errs, notFoundBatchError("property", missing))
+	}
+	return results, errs, nil
 }
 
-// BatchArchive soft-deletes multiple property definitions.
-func (s *SQLitePropertyStore) BatchArchive(ctx context.Context, objectType string, names []string) error {
-	for _, name := range names {
-		if err := s.Archive(ctx, objectType, name); err != nil {
-			return err
+// BatchArchive soft-deletes multiple property definitions in one
+// transaction. Names that match no active definition are reported together
+// as one error.
+func (s *SQLitePropertyStore) BatchArchive(ctx context.Context, objectType string, names []string) ([]domain.BatchError, error) {
+	typeID, err := s.resolveType(ctx, objectType)
+	if err != nil {
+		return nil, err
+	}
+	ts := now()
+	var missing []string
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, name := range names {
+			res, err := tx.ExecContext(ctx,
+				`UPDATE property_definitions SET archived = TRUE, updated_at = ?
+				 WHERE object_type_id = ? AND name = ? AND archived = FALSE`,
+				ts, typeID, name,
+			)
+			if err != nil {
+				return fmt.Errorf("archive property: %w", err)
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("rows affected: %w", err)
+			}
+			if n == 0 {
+				missing = append(missing, name)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	var errs []domain.BatchError
+	if len(missing) > 0 {
+		errs = append(errs, notFoundBatchError("property", missing))
+	}
+	return errs, nil
 }
 
 // ListGroups returns all non-archived property groups for the given object type.
@@ -166,13 +166,30 @@ func TestPropertyStore_BatchCreate(t *testing.T) {
 		{Name: "phone", Label: "Phone", Type: "string", FieldType: "phonenumber", GroupName: "contactinformation"},
 	}
 
-	created, err := s.BatchCreate(ctx, "contacts", props)
+	created, errs, err := s.BatchCreate(ctx, "contacts", props)
 	if err != nil {
 		t.Fatalf("BatchCreate: %v", err)
 	}
 	if len(created) != 2 {
 		t.Errorf("len(created) = %d, want 2", len(created))
 	}
+	if len(errs) != 0 {
+		t.Errorf("errs = %+v, want none", errs)
+	}
+
+	created, errs, err = s.BatchCreate(ctx, "contacts", []domain.Property{
+		props[0],
+		{Name: "fax", Label: "Fax", Type: "string", FieldType: "text", GroupName: "contactinformation"},
+	})
+	if err != nil {
+		t.Fatalf("BatchCreate duplicate: %v", err)
+	}
+	if len(created) != 1 || created[0].Name != "fax" {
+		t.Errorf("created = %+v, want only fax", created)
+	}
+	if len(errs) != 1 || errs[0].Category != "OBJECT_ALREADY_EXISTS" {
+		t.Errorf("errs = %+v, want one OBJECT_ALREADY_EXISTS", errs)
+	}
 }
 
 func TestPropertyStore_BatchRead(t *testing.T) {
@@ -187,13 +204,16 @@ func TestPropertyStore_BatchRead(t *testing.T) {
 		}
 	}
 
-	results, err := s.BatchRead(ctx, "contacts", []string{"email", "phone"})
+	results, errs, err := s.BatchRead(ctx, "contacts", []string{"email", "phone", "missing"})
 	if err != nil {
 		t.Fatalf("BatchRead: %v", err)
 	}
 	if len(results) != 2 {
 		t.Errorf("len(results) = %d, want 2", len(results))
 	}
+	if len(errs) != 1 || errs[0].Context["ids"][0] != "missing" {
+		t.Errorf("errs = %+v, want one error for missing", errs)
+	}
 }
 
 func TestPropertyStore_BatchArchive(t *testing.T) {
@@ -208,9 +228,13 @@ func TestPropertyStore_BatchArchive(t *testing.T) {
 		}
 	}
 
-	if err := s.BatchArchive(ctx, "contacts", []string{"email", "phone"}); err != nil {
+	errs, err := s.BatchArchive(ctx, "contacts", []string{"email", "phone"})
+	if err != nil {
 		t.Fatalf("BatchArchive: %v", err)
 	}
+	if len(errs) != 0 {
+		t.Errorf("errs = %+v, want none", errs)
+	}
 
 	props, err := s.List(ctx, "contacts")
 	if err != nil {
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RequestLogEntry is a single recorded API call.
+type RequestLogEntry struct {
+	ID              int64               `json:"id"`
+	Method          string              `json:"method"`
+	Path            string              `json:"path"`
+	Query           string              `json:"query,omitempty"`
+	StatusCode      int                 `json:"statusCode"`
+	RequestHeaders  map[string][]string `json:"requestHeaders,omitempty"`
+	RequestBody     string              `json:"requestBody,omitempty"`
+	ResponseHeaders map[string][]string `json:"responseHeaders,omitempty"`
+	ResponseBody    string              `json:"responseBody,omitempty"`
+	DurationMs      int64               `json:"durationMs"`
+	CorrelationID   string              `json:"correlationId,omitempty"`
+	CreatedAt       string              `json:"createdAt"`
+}
+
+// StatusRange is an inclusive range of HTTP status codes.
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+// RequestLogFilter narrows the entries returned by RequestLogStore.List. Zero
+// values match everything.
+type RequestLogFilter struct {
+	Methods       []string      // any of these methods
+	PathPrefix    string        // path starts with this prefix
+	PathGlob      string        // path matches this glob (* and ? wildcards)
+	Statuses      []StatusRange // status code falls in any of these ranges
+	CorrelationID string
+	Since         string // created at or after this timestamp
+	Until         string // created before this timestamp
+	BodyContains  string // substring of the request or response body
+	After         int64  // cursor: only entries with a lower ID
+	Limit         int
+}
+
+// RequestLogStore defines the interface for request log persistence.
+type RequestLogStore interface {
+	Insert(ctx context.Context, entry *RequestLogEntry) error
+	Get(ctx context.Context, id int64) (*RequestLogEntry, error)
+	List(ctx context.Context, filter RequestLogFilter) ([]*RequestLogEntry, bool, error)
+}
+
+// SQLiteRequestLogStore implements RequestLogStore backed by SQLite.
+type SQLiteRequestLogStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteRequestLogStore creates a new SQLiteRequestLogStore.
+func NewSQLiteRequestLogStore(db *sql.DB) *SQLiteRequestLogStore {
+	return &SQLiteRequestLogStore{db: db}
+}
+
+// Insert records a request log entry and sets its ID. CreatedAt defaults to
+// the current time when empty.
+func (s *SQLiteRequestLogStore) Insert(ctx context.Context, entry *RequestLogEntry) error {
+	if entry.CreatedAt == "" {
+		entry.CreatedAt = now()
+	}
+
+	reqHeaders, err := marshalHeaders(entry.RequestHeaders)
+	if err != nil {
+		return err
+	}
+	respHeaders, err := marshalHeaders(entry.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO request_log (method, path, query, status_code, request_headers, request_body,
+		 response_headers, response_body, duration_ms, correlation_id, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Method, entry.Path, entry.Query, entry.StatusCode, reqHeaders, entry.RequestBody,
+		respHeaders, entry.ResponseBody, entry.DurationMs, entry.CorrelationID, entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert request log: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("last insert id: %w", err)
+	}
+	entry.ID = id
+	return nil
+}
+
+const requestLogColumns = `id, method, path, COALESCE(query,''), status_code,
+	COALESCE(request_headers,''), COALESCE(request_body,''),
+	COALESCE(response_headers,''), COALESCE(response_body,''),
+	COALESCE(duration_ms,0), COALESCE(correlation_id,''), created_at`
+
+// Get returns a single request log entry by ID.
+func (s *SQLiteRequestLogStore) Get(ctx context.Context, id int64) (*RequestLogEntry, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+requestLogColumns+` FROM request_log WHERE id = ?`, id)
+	e, err := scanRequestLogEntry(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+// List returns matching request log entries, newest first, and whether more
+// entries exist beyond the limit.
+func (s *SQLiteRequestLogStore) List(ctx context.Context, filter RequestLogFilter) ([]*RequestLogEntry, bool, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var where []string
+	var args []any
+
+	if len(filter.Methods) > 0 {
+		placeholders := make([]string, len(filter.Methods))
+		for i, m := range filter.Methods {
+			placeholders[i] = "?"
+			args = append(args, strings.ToUpper(m))
+		}
+		where = append(where, "method IN ("+strings.Join(placeholders, ",")+")")
+	}
+	if filter.PathPrefix != "" {
+		where = append(where, "substr(path, 1, ?) = ?")
+		args = append(args, len(filter.PathPrefix), filter.PathPrefix)
+	}
+	if filter.PathGlob != "" {
+		where = append(where, "path GLOB ?")
+		args = append(args, filter.PathGlob)
+	}
+	if len(filter.Statuses) > 0 {
+		ranges := make([]string, len(filter.Statuses))
+		for i, r := range filter.Statuses {
+			ranges[i] = "status_code BETWEEN ? AND ?"
+			args = append(args, r.Min, r.Max)
+		}
+		where = append(where, "("+strings.Join(ranges, " OR ")+")")
+	}
+	if filter.CorrelationID != "" {
+		where = append(where, "correlation_id = ?")
+		args = append(args, filter.CorrelationID)
+	}
+	if filter.Since != "" {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if filter.Until != "" {
+		where = append(where, "created_at < ?")
+		args = append(args, filter.Until)
+	}
+	if filter.BodyContains != "" {
+		where = append(where, "(instr(COALESCE(request_body,''), ?) > 0 OR instr(COALESCE(response_body,''), ?) > 0)")
+		args = append(args, filter.BodyContains, filter.BodyContains)
+	}
+	if filter.After > 0 {
+		where = append(where, "id < ?")
+		args = append(args, filter.After)
+	}
+
+	query := `SELECT ` + requestLogColumns + ` FROM request_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("query request log: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := make([]*RequestLogEntry, 0, limit)
+	for rows.Next() {
+		e, err := scanRequestLogEntry(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("iterate request log: %w", err)
+	}
+
+	hasMore := len(entries) > limit
+	if hasMore {
+		entries = entries[:limit]
+	}
+	return entries, hasMore, nil
+}
+
+func scanRequestLogEntry(row scanner) (*RequestLogEntry, error) {
+	var e RequestLogEntry
+	var reqHeaders, respHeaders string
+	if err := row.Scan(&e.ID, &e.Method, &e.Path, &e.Query, &e.StatusCode,
+		&reqHeaders, &e.RequestBody, &respHeaders, &e.ResponseBody,
+		&e.DurationMs, &e.CorrelationID, &e.CreatedAt); err != nil {
+		return nil, fmt.Errorf("scan request log: %w", err)
+	}
+	if reqHeaders != "" {
+		if err := json.Unmarshal([]byte(reqHeaders), &e.RequestHeaders); err != nil {
+			return nil, fmt.Errorf("unmarshal request headers: %w", err)
+		}
+	}
+	if respHeaders != "" {
+		if err := json.Unmarshal([]byte(respHeaders), &e.ResponseHeaders); err != nil {
+			return nil, fmt.Errorf("unmarshal response headers: %w", err)
+		}
+	}
+	return &e, nil
+}
+
+// marshalHeaders encodes headers as JSON, storing NULL when there are none.
+func marshalHeaders(h map[string][]string) (any, error) {
+	if len(h) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(h)
+	if err != nil {
+		return nil, fmt.Errorf("marshal headers: %w", err)
+	}
+	return string(b), nil
+}
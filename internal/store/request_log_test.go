@@ -0,0 +1,159 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/johnwards/hubspot/internal/database"
+	"github.com/johnwards/hubspot/internal/store"
+	"github.com/johnwards/hubspot/internal/testhelpers"
+)
+
+var _ store.RequestLogStore = (*store.SQLiteRequestLogStore)(nil)
+
+func TestRequestLogInsert(t *testing.T) {
+	db := testhelpers.NewTestDB(t)
+	ctx := context.Background()
+	if err := database.Migrate(ctx, db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	s := store.NewSQLiteRequestLogStore(db)
+
+	entry := &store.RequestLogEntry{
+		Method:         "GET",
+		Path:           "/crm/v3/objects/contacts",
+		Query:          "limit=5",
+		StatusCode:     200,
+		RequestHeaders: map[string][]string{"Accept": {"application/json"}},
+		DurationMs:     3,
+		CorrelationID:  "corr-1",
+	}
+	if err := s.Insert(ctx, entry); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if entry.ID == 0 {
+		t.Error("expected ID to be set")
+	}
+	if entry.CreatedAt == "" {
+		t.Error("expected CreatedAt to default to now")
+	}
+
+	var query, headers string
+	var respHeaders *string
+	err := db.QueryRowContext(ctx,
+		`SELECT query, request_headers, response_headers FROM request_log WHERE id = ?`, entry.ID,
+	).Scan(&query, &headers, &respHeaders)
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if query != "limit=5" {
+		t.Errorf("query = %q, want %q", query, "limit=5")
+	}
+	if headers != `{"Accept":["application/json"]}` {
+		t.Errorf("request_headers = %q", headers)
+	}
+	if respHeaders != nil {
+		t.Errorf("response_headers = %q, want NULL", *respHeaders)
+	}
+}
+
+func TestRequestLogListFilters(t *testing.T) {
+	db := testhelpers.NewTestDB(t)
+	ctx := context.Background()
+	if err := database.Migrate(ctx, db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	s := store.NewSQLiteRequestLogStore(db)
+
+	seedEntries := []*store.RequestLogEntry{
+		{Method: "POST", Path: "/crm/v3/objects/contacts", StatusCode: 201, RequestBody: `{"email":"a@example.com"}`, CorrelationID: "c1", CreatedAt: "2024-01-01T00:00:00.000Z"},
+		{Method: "GET", Path: "/crm/v3/objects/contacts/1", StatusCode: 200, CorrelationID: "c2", CreatedAt: "2024-01-02T00:00:00.000Z"},
+		{Method: "GET", Path: "/crm/v3/objects/deals/9", StatusCode: 404, ResponseBody: `{"category":"OBJECT_NOT_FOUND"}`, CorrelationID: "c3", CreatedAt: "2024-01-03T00:00:00.000Z"},
+		{Method: "PATCH", Path: "/crm/v3/objects/contacts/1", StatusCode: 500, CorrelationID: "c4", CreatedAt: "2024-01-04T00:00:00.000Z"},
+	}
+	for _, e := range seedEntries {
+		if err := s.Insert(ctx, e); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter store.RequestLogFilter
+		want   []string
+	}{
+		{"all newest first", store.RequestLogFilter{}, []string{"c4", "c3", "c2", "c1"}},
+		{"method", store.RequestLogFilter{Methods: []string{"get"}}, []string{"c3", "c2"}},
+		{"path prefix", store.RequestLogFilter{PathPrefix: "/crm/v3/objects/contacts/"}, []string{"c4", "c2"}},
+		{"path glob", store.RequestLogFilter{PathGlob: "/crm/v3/objects/*/9"}, []string{"c3"}},
+		{"status class", store.RequestLogFilter{Statuses: []store.StatusRange{{Min: 400, Max: 599}}}, []string{"c4", "c3"}},
+		{"correlation id", store.RequestLogFilter{CorrelationID: "c2"}, []string{"c2"}},
+		{"time range", store.RequestLogFilter{Since: "2024-01-02T00:00:00.000Z", Until: "2024-01-04T00:00:00.000Z"}, []string{"c3", "c2"}},
+		{"request body", store.RequestLogFilter{BodyContains: "a@example.com"}, []string{"c1"}},
+		{"response body", store.RequestLogFilter{BodyContains: "OBJECT_NOT_FOUND"}, []string{"c3"}},
+		{"cursor", store.RequestLogFilter{After: seedEntries[2].ID}, []string{"c2", "c1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, hasMore, err := s.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if hasMore {
+				t.Error("expected hasMore=false")
+			}
+			got := make([]string, len(entries))
+			for i, e := range entries {
+				got[i] = e.CorrelationID
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	entries, hasMore, err := s.List(ctx, store.RequestLogFilter{Limit: 3})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(entries) != 3 || !hasMore {
+		t.Errorf("limit 3: got %d entries, hasMore=%v", len(entries), hasMore)
+	}
+}
+
+func TestRequestLogGet(t *testing.T) {
+	db := testhelpers.NewTestDB(t)
+	ctx := context.Background()
+	if err := database.Migrate(ctx, db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	s := store.NewSQLiteRequestLogStore(db)
+
+	entry := &store.RequestLogEntry{
+		Method:          "GET",
+		Path:            "/crm/v3/owners",
+		StatusCode:      200,
+		ResponseHeaders: map[string][]string{"Content-Type": {"application/json"}},
+	}
+	if err := s.Insert(ctx, entry); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	got, err := s.Get(ctx, entry.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Path != "/crm/v3/owners" {
+		t.Errorf("Path = %q", got.Path)
+	}
+	if ct := got.ResponseHeaders["Content-Type"]; len(ct) != 1 || ct[0] != "application/json" {
+		t.Errorf("ResponseHeaders = %v", got.ResponseHeaders)
+	}
+
+	if _, err := s.Get(ctx, entry.ID+1); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,416 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/johnwards/hubspot/internal/domain"
+)
+
+// ErrInvalidRollup is returned when a property's rollup definition is not
+// valid.
+var ErrInvalidRollup = fmt.Errorf("invalid rollup")
+
+var rollupFunctions = []string{"COUNT", "SUM", "MIN", "MAX", "LATEST"}
+
+var rollupOperators = []string{"EQ", "NEQ", "IN", "NOT_IN", "HAS_PROPERTY", "NOT_HAS_PROPERTY"}
+
+// checkRollup validates the rollup definition of p, if it has one, marks p
+// calculated and returns the definition encoded for storage.
+func checkRollup(ctx context.Context, ex execer, p *domain.Property) (any, error) {
+	r := p.Rollup
+	if r == nil {
+		return nil, nil
+	}
+	invalid := func(format string, args ...any) (any, error) {
+		return nil, fmt.Errorf("%w for %s: %s", ErrInvalidRollup, p.Name, fmt.Sprintf(format, args...))
+	}
+
+	if p.CalculationFormula != "" {
+		return invalid("a property cannot have both a formula and a rollup")
+	}
+	r.Function = strings.ToUpper(r.Function)
+	if !slices.Contains(rollupFunctions, r.Function) {
+		return invalid("function must be one of %s", strings.Join(rollupFunctions, ", "))
+	}
+	var exists int
+	if err := ex.QueryRowContext(ctx,
+		`SELECT 1 FROM object_types WHERE name = ? OR id = ?`, r.ObjectType, r.ObjectType,
+	).Scan(&exists); err != nil {
+		return invalid("object type %q does not exist", r.ObjectType)
+	}
+	if r.Function != "COUNT" && r.PropertyName == "" {
+		return invalid("propertyName is required for %s", r.Function)
+	}
+	if r.Function == "LATEST" && r.SortPropertyName == "" {
+		return invalid("sortPropertyName is required for LATEST")
+	}
+	for _, f := range r.Filters {
+		if f.PropertyName == "" || !slices.Contains(rollupOperators, f.Operator) {
+			return invalid("filters need a propertyName and an operator of %s", strings.Join(rollupOperators, ", "))
+		}
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("encode rollup: %w", err)
+	}
+	p.Calculated = true
+	return string(b), nil
+}
+
+// rollupDef is a rollup property of one object type.
+type rollupDef struct {
+	name         string
+	rollup       domain.Rollup
+	targetTypeID string
+	valueType    string // type of rollup.PropertyName on the target type
+	sortType     string // type of rollup.SortPropertyName on the target type
+}
+
+// reads reports whether d aggregates any of the properties in props.
+func (d rollupDef) reads(props map[string]string) bool {
+	r := d.rollup
+	if _, ok := props[r.PropertyName]; ok && r.PropertyName != "" {
+		return true
+	}
+	if _, ok := props[r.SortPropertyName]; ok && r.SortPropertyName != "" {
+		return true
+	}
+	for _, f := range r.Filters {
+		if _, ok := props[f.PropertyName]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// rollupCache memoises rollup definitions by object type for one
+// recomputation.
+type rollupCache map[string][]rollupDef
+
+func (c rollupCache) defs(ctx context.Context, ex execer, typeID string) ([]rollupDef, error) {
+	if defs, ok := c[typeID]; ok {
+		return defs, nil
+	}
+	rows, err := ex.QueryContext(ctx,
+		`SELECT name, rollup FROM property_definitions
+		 WHERE object_type_id = ? AND archived = FALSE AND rollup IS NOT NULL AND rollup != ''
+		 ORDER BY name`, typeID)
+	if err != nil {
+		return nil, fmt.Errorf("load rollups: %w", err)
+	}
+	var defs []rollupDef
+	for rows.Next() {
+		var d rollupDef
+		var raw string
+		if err := rows.Scan(&d.name, &raw); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan rollup: %w", err)
+		}
+		if err := json.Unmarshal([]byte(raw), &d.rollup); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("decode rollup %s: %w", d.name, err)
+		}
+		defs = append(defs, d)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load rollups: %w", err)
+	}
+
+	for i := range defs {
+		err := ex.QueryRowContext(ctx,
+			`SELECT id FROM object_types WHERE name = ? OR id = ?`,
+			defs[i].rollup.ObjectType, defs[i].rollup.ObjectType,
+		).Scan(&defs[i].targetTypeID)
+		if err != nil {
+			// The associated type was deleted; the rollup has nothing to aggregate.
+			defs[i].targetTypeID = ""
+			continue
+		}
+		types, err := propertyTypes(ctx, ex, defs[i].targetTypeID)
+		if err != nil {
+			return nil, err
+		}
+		defs[i].valueType = types[defs[i].rollup.PropertyName]
+		defs[i].sortType = types[defs[i].rollup.SortPropertyName]
+	}
+	c[typeID] = defs
+	return defs, nil
+}
+
+// referringObjects returns the IDs of the objects with an association to id,
+// whose rollups may aggregate id's values.
+func referringObjects(ctx context.Context, ex execer, id string) ([]string, error) {
+	rows, err := ex.QueryContext(ctx,
+		`SELECT DISTINCT from_object_id FROM associations WHERE to_object_id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("find associated objects: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var ids []string
+	for rows.Next() {
+		var from string
+		if err := rows.Scan(&from); err != nil {
+			return nil, fmt.Errorf("scan associated object: %w", err)
+		}
+		ids = append(ids, from)
+	}
+	return ids, rows.Err()
+}
+
+// refreshRollups recomputes the rollups of the given objects and of every
+// object associated with them. It runs after writes that change an object's
+// associations; value updates use refreshRollupsReading.
+func refreshRollups(ctx context.Context, ex execer, ids ...string) error {
+	targets := slices.Clone(ids)
+	for _, id := range ids {
+		referrers, err := referringObjects(ctx, ex, id)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, referrers...)
+	}
+	return recomputeRollups(ctx, ex, targets...)
+}
+
+// refreshRollupsReading recomputes, after props were written to the object id
+// of typeID, the rollups of the objects associated with id that aggregate
+// typeID and read one of props. Rollups that only count id, or read other
+// properties, cannot have changed.
+func refreshRollupsReading(ctx context.Context, ex execer, typeID, id string, props map[string]string) error {
+	referrers, err := referringObjects(ctx, ex, id)
+	if err != nil {
+		return err
+	}
+	return recomputeMatchingRollups(ctx, ex, referrers, func(d rollupDef) bool {
+		return d.targetTypeID == typeID && d.reads(props)
+	})
+}
+
+// recomputeRollupsOfType recomputes the rollups of every object of typeID,
+// after a rollup property is defined.
+func recomputeRollupsOfType(ctx context.Context, ex execer, typeID string) error {
+	rows, err := ex.QueryContext(ctx, `SELECT id FROM objects WHERE object_type_id = ?`, typeID)
+	if err != nil {
+		return fmt.Errorf("list objects: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("scan object: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list objects: %w", err)
+	}
+	return recomputeRollups(ctx, ex, ids...)
+}
+
+// clearRollupValues removes the stored values of the property name from
+// every object of typeID, after its rollup definition is removed.
+func clearRollupValues(ctx context.Context, ex execer, typeID, name string) error {
+	const values = `SELECT rowid FROM property_values
+		WHERE property_name = ? AND object_id IN (SELECT id FROM objects WHERE object_type_id = ?)`
+	if _, err := ex.ExecContext(ctx,
+		`DELETE FROM property_values_fts WHERE rowid IN (`+values+`)`, name, typeID,
+	); err != nil {
+		return fmt.Errorf("clear rollup %s: %w", name, err)
+	}
+	if _, err := ex.ExecContext(ctx,
+		`DELETE FROM property_values WHERE rowid IN (`+values+`)`, name, typeID,
+	); err != nil {
+		return fmt.Errorf("clear rollup %s: %w", name, err)
+	}
+	return nil
+}
+
+// recomputeRollups evaluates the rollup properties of each object and stores
+// the values that changed, attributed to the CALCULATED source. Rollup writes
+// do not cascade into the rollups of other objects.
+func recomputeRollups(ctx context.Context, ex execer, ids ...string) error {
+	return recomputeMatchingRollups(ctx, ex, ids, func(rollupDef) bool { return true })
+}
+
+// recomputeMatchingRollups is recomputeRollups limited to the rollup
+// properties for which match returns true.
+func recomputeMatchingRollups(ctx context.Context, ex execer, ids []string, match func(rollupDef) bool) error {
+	cache := rollupCache{}
+	ctx = WithChangeSource(ctx, domain.ChangeSource{Type: domain.SourceCalculated})
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	for _, id := range slices.Compact(ids) {
+		var typeID string
+		if err := ex.QueryRowContext(ctx, `SELECT object_type_id FROM objects WHERE id = ?`, id).Scan(&typeID); err != nil {
+			// Deleted objects have nothing to recompute.
+			continue
+		}
+		defs, err := cache.defs(ctx, ex, typeID)
+		if err != nil {
+			return err
+		}
+		defs = slices.DeleteFunc(slices.Clone(defs), func(d rollupDef) bool { return !match(d) })
+		if len(defs) == 0 {
+			continue
+		}
+
+		current, err := getAllProperties(ctx, ex, id)
+		if err != nil {
+			return err
+		}
+		changed := make(map[string]string)
+		for _, d := range defs {
+			v, err := evaluateRollup(ctx, ex, id, d)
+			if err != nil {
+				return err
+			}
+			if v != current[d.name] {
+				changed[d.name] = v
+			}
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		idInt, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid object id: %w", err)
+		}
+		if err := setProperties(ctx, ex, idInt, changed, now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluateRollup aggregates d over the non-archived objects associated with
+// id in a single query. It returns "" when there is no value to aggregate.
+func evaluateRollup(ctx context.Context, ex execer, id string, d rollupDef) (string, error) {
+	if d.targetTypeID == "" {
+		return "", nil
+	}
+	r := d.rollup
+	var joins, where []string
+	args := []any{id, d.targetTypeID}
+	if r.Function != "COUNT" {
+		joins = append(joins, `JOIN property_values v ON v.object_id = a.id AND v.property_name = ?`)
+		args = append(args, r.PropertyName)
+		where = append(where, `v.value != ''`)
+	}
+	if r.Function == "LATEST" {
+		joins = append(joins, `LEFT JOIN property_values s ON s.object_id = a.id AND s.property_name = ?`)
+		args = append(args, r.SortPropertyName)
+	}
+	for i, f := range r.Filters {
+		joins = append(joins, fmt.Sprintf(
+			`LEFT JOIN property_values f%[1]d ON f%[1]d.object_id = a.id AND f%[1]d.property_name = ?`, i))
+		args = append(args, f.PropertyName)
+	}
+	for i, f := range r.Filters {
+		cond, condArgs := rollupFilterSQL(fmt.Sprintf("COALESCE(f%d.value, '')", i), f)
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+
+	var sel, order string
+	switch r.Function {
+	case "COUNT":
+		sel = `COUNT(*)`
+	case "SUM":
+		sel = `SUM(CAST(v.value AS REAL))`
+		where = append(where, numericValueSQL("v.value"))
+	case "MIN":
+		sel = `v.value`
+		order = `ORDER BY ` + typedValueSQL("v.value", d.valueType) + `, a.id LIMIT 1`
+	case "MAX":
+		sel = `v.value`
+		order = `ORDER BY ` + typedValueSQL("v.value", d.valueType) + ` DESC, a.id LIMIT 1`
+	case "LATEST":
+		sel = `v.value`
+		order = `ORDER BY COALESCE(s.value, '') = '', ` + typedValueSQL("s.value", d.sortType) + ` DESC, a.id LIMIT 1`
+	}
+
+	query := `WITH a AS (
+		SELECT DISTINCT o.id FROM associations assoc JOIN objects o ON o.id = assoc.to_object_id
+		WHERE assoc.from_object_id = ? AND o.object_type_id = ? AND o.archived = FALSE
+	) SELECT ` + sel + ` FROM a ` + strings.Join(joins, " ")
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += " " + order
+
+	switch r.Function {
+	case "COUNT":
+		var count int
+		if err := ex.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+			return "", fmt.Errorf("evaluate rollup %s: %w", d.name, err)
+		}
+		return strconv.Itoa(count), nil
+	case "SUM":
+		var sum sql.NullFloat64
+		if err := ex.QueryRowContext(ctx, query, args...).Scan(&sum); err != nil {
+			return "", fmt.Errorf("evaluate rollup %s: %w", d.name, err)
+		}
+		if !sum.Valid {
+			return "", nil
+		}
+		return strconv.FormatFloat(sum.Float64, 'f', -1, 64), nil
+	}
+	var v string
+	err := ex.QueryRowContext(ctx, query, args...).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("evaluate rollup %s: %w", d.name, err)
+	}
+	return v, nil
+}
+
+// rollupFilterSQL returns the condition and arguments that apply f to the
+// value expression column. EQ and NEQ ignore case; IN and NOT_IN do not.
+func rollupFilterSQL(column string, f domain.RollupFilter) (string, []any) {
+	switch f.Operator {
+	case "EQ":
+		return column + ` = ? COLLATE NOCASE`, []any{f.Value}
+	case "NEQ":
+		return column + ` != ? COLLATE NOCASE`, []any{f.Value}
+	case "IN", "NOT_IN":
+		if len(f.Values) == 0 {
+			if f.Operator == "IN" {
+				return "FALSE", nil
+			}
+			return "TRUE", nil
+		}
+		args := make([]any, len(f.Values))
+		for i, v := range f.Values {
+			args[i] = v
+		}
+		op := " IN "
+		if f.Operator == "NOT_IN" {
+			op = " NOT IN "
+		}
+		return column + op + "(" + strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ") + ")", args
+	case "HAS_PROPERTY":
+		return column + ` != ''`, nil
+	case "NOT_HAS_PROPERTY":
+		return column + ` = ''`, nil
+	}
+	return "FALSE", nil
+}
+
+// numericValueSQL returns a condition that holds when column is a plain
+// decimal number, so SUM skips values that are not numbers.
+func numericValueSQL(column string) string {
+	return fmt.Sprintf(`(%[1]s GLOB '*[0-9]*' AND %[1]s NOT GLOB '*[^0-9.eE+-]*')`, column)
+}
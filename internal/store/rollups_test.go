@@ -0,0 +1,185 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/johnwards/hubspot/internal/domain"
+	"github.com/johnwards/hubspot/internal/store"
+)
+
+// stageID returns the ID of the seeded deal stage with the given label.
+func stageID(t *testing.T, db *sql.DB, label string) string {
+	t.Helper()
+	var id string
+	if err := db.QueryRowContext(context.Background(),
+		`SELECT id FROM pipeline_stages WHERE label = ?`, label).Scan(&id); err != nil {
+		t.Fatalf("stage %s: %v", label, err)
+	}
+	return id
+}
+
+func TestRollups(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	as := store.NewSQLiteAssociationStore(db)
+	ctx := context.Background()
+
+	company, err := s.Create(ctx, "companies", map[string]string{"name": "Acme"})
+	if err != nil {
+		t.Fatalf("create company: %v", err)
+	}
+	won, lost := stageID(t, db, "Closed Won"), stageID(t, db, "Closed Lost")
+	open := stageID(t, db, "Qualified To Buy")
+
+	var dealIDs []string
+	for _, props := range []map[string]string{
+		{"dealname": "Old win", "amount": "100", "dealstage": won, "closedate": "2024-01-01"},
+		{"dealname": "New win", "amount": "250", "dealstage": won, "closedate": "2024-03-01"},
+		{"dealname": "Lost", "amount": "999", "dealstage": lost, "closedate": "2024-04-01"},
+		{"dealname": "Open", "amount": "50", "dealstage": open},
+	} {
+		deal, err := s.CreateWithAssociations(ctx, "deals", domain.CreateInput{
+			Properties: props,
+			Associations: []domain.ObjectAssociationInput{{
+				To:    domain.AssociationTarget{ID: company.ID},
+				Types: []domain.AssociationTypeInput{{AssociationCategory: "HUBSPOT_DEFINED", AssociationTypeID: 6}},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("create deal: %v", err)
+		}
+		dealIDs = append(dealIDs, deal.ID)
+	}
+
+	check := func(t *testing.T, want map[string]string) {
+		t.Helper()
+		names := make([]string, 0, len(want))
+		for name := range want {
+			names = append(names, name)
+		}
+		got, err := s.Get(ctx, "companies", company.ID, names)
+		if err != nil {
+			t.Fatalf("get company: %v", err)
+		}
+		for name, v := range want {
+			if got.Properties[name] != v {
+				t.Errorf("%s = %q, want %q", name, got.Properties[name], v)
+			}
+		}
+	}
+
+	check(t, map[string]string{
+		"num_associated_deals": "4",
+		"hs_num_open_deals":    "1",
+		"total_revenue":        "350",
+		"recent_deal_amount":   "250",
+	})
+
+	t.Run("value change", func(t *testing.T) {
+		if _, err := s.Update(ctx, "deals", dealIDs[0], map[string]string{"amount": "150"}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		check(t, map[string]string{"total_revenue": "400"})
+	})
+
+	t.Run("association removed", func(t *testing.T) {
+		if err := as.RemoveAssociations(ctx, "companies", company.ID, "deals", dealIDs[1]); err != nil {
+			t.Fatalf("remove association: %v", err)
+		}
+		check(t, map[string]string{"num_associated_deals": "3", "total_revenue": "150", "recent_deal_amount": "150"})
+	})
+
+	t.Run("deal archived", func(t *testing.T) {
+		if err := s.Archive(ctx, "deals", dealIDs[3]); err != nil {
+			t.Fatalf("archive: %v", err)
+		}
+		check(t, map[string]string{"num_associated_deals": "2", "hs_num_open_deals": "0"})
+	})
+
+	t.Run("custom rollup", func(t *testing.T) {
+		ps := store.NewSQLitePropertyStore(db)
+		if _, err := ps.Create(ctx, "companies", &domain.Property{
+			Name: "largest_deal", Label: "Largest deal", Type: "number", FieldType: "number", GroupName: "companyinformation",
+			Rollup: &domain.Rollup{Function: "MAX", ObjectType: "deals", PropertyName: "amount"},
+		}); err != nil {
+			t.Fatalf("create rollup property: %v", err)
+		}
+		check(t, map[string]string{"largest_deal": "999"})
+
+		_, err := ps.Create(ctx, "companies", &domain.Property{
+			Name: "broken", Label: "Broken", Type: "number", FieldType: "number", GroupName: "companyinformation",
+			Rollup: &domain.Rollup{Function: "SUM", ObjectType: "deals"},
+		})
+		if !errors.Is(err, store.ErrInvalidRollup) {
+			t.Errorf("expected ErrInvalidRollup, got %v", err)
+		}
+	})
+
+	t.Run("filter property change", func(t *testing.T) {
+		if _, err := s.Update(ctx, "deals", dealIDs[2], map[string]string{"dealstage": open}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		check(t, map[string]string{"hs_num_open_deals": "1", "total_revenue": "150"})
+	})
+
+	t.Run("filtered min", func(t *testing.T) {
+		ps := store.NewSQLitePropertyStore(db)
+		if _, err := ps.Create(ctx, "companies", &domain.Property{
+			Name: "smallest_named_deal", Label: "Smallest named deal", Type: "number", FieldType: "number", GroupName: "companyinformation",
+			Rollup: &domain.Rollup{Function: "MIN", ObjectType: "deals", PropertyName: "amount", Filters: []domain.RollupFilter{
+				{PropertyName: "dealname", Operator: "IN", Values: []string{"Old win", "Lost"}},
+			}},
+		}); err != nil {
+			t.Fatalf("create rollup property: %v", err)
+		}
+		check(t, map[string]string{"smallest_named_deal": "150"})
+
+		if _, err := s.Update(ctx, "deals", dealIDs[0], map[string]string{"amount": "1000"}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		check(t, map[string]string{"smallest_named_deal": "999", "largest_deal": "1000"})
+	})
+
+	t.Run("rollup updated", func(t *testing.T) {
+		ps := store.NewSQLitePropertyStore(db)
+		p, err := ps.Get(ctx, "companies", "largest_deal")
+		if err != nil {
+			t.Fatalf("get rollup property: %v", err)
+		}
+		p.Rollup.Function = "MIN"
+		if _, err := ps.Update(ctx, "companies", "largest_deal", p); err != nil {
+			t.Fatalf("update rollup property: %v", err)
+		}
+		check(t, map[string]string{"largest_deal": "999"})
+
+		p.Rollup.PropertyName = ""
+		if _, err := ps.Update(ctx, "companies", "largest_deal", p); !errors.Is(err, store.ErrInvalidRollup) {
+			t.Errorf("expected ErrInvalidRollup, got %v", err)
+		}
+	})
+
+	t.Run("rollup removed", func(t *testing.T) {
+		ps := store.NewSQLitePropertyStore(db)
+		p, err := ps.Get(ctx, "companies", "largest_deal")
+		if err != nil {
+			t.Fatalf("get rollup property: %v", err)
+		}
+		p.Rollup = nil
+		updated, err := ps.Update(ctx, "companies", "largest_deal", p)
+		if err != nil {
+			t.Fatalf("remove rollup: %v", err)
+		}
+		if updated.Rollup != nil || updated.Calculated {
+			t.Errorf("rollup = %v, calculated = %v; want a plain property", updated.Rollup, updated.Calculated)
+		}
+		check(t, map[string]string{"largest_deal": ""})
+
+		if _, err := s.Update(ctx, "deals", dealIDs[0], map[string]string{"amount": "2000"}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		check(t, map[string]string{"largest_deal": ""})
+	})
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -49,9 +50,9 @@ func (s *SQLiteSchemaStore) resolveSchemaType(ctx context.Context, objectType st
 }
 
 // nextCustomTypeID generates the next 2-{n} ID for a custom object type.
-func (s *SQLiteSchemaStore) nextCustomTypeID(ctx context.Context) (string, error) {
+func nextCustomTypeID(ctx context.Context, ex execer) (string, error) {
 	var maxNum int
-	err := s.db.QueryRowContext(ctx,
+	err := ex.QueryRowContext(ctx,
 		`SELECT COALESCE(MAX(CAST(SUBSTR(id, 3) AS INTEGER)), 0)
 		 FROM object_types WHERE id LIKE '2-%'`,
 	).Scan(&maxNum)
@@ -66,13 +67,13 @@ func (s *SQLiteSchemaStore) nextCustomTypeID(ctx context.Context) (string, error
 func (s *SQLiteSchemaStore) loadSchema(ctx context.Context, typeID string) (*domain.ObjectSchema, error) {
 	var schema domain.ObjectSchema
 	var archived bool
-	var fqn, pdp sql.NullString
+	var fqn, pdp, searchable sql.NullString
 	err := s.db.QueryRowContext(ctx,
 		`SELECT id, name, label_singular, label_plural, primary_display_property,
-		        fully_qualified_name, archived, created_at, updated_at
+		        searchable_properties, fully_qualified_name, archived, created_at, updated_at
 		 FROM object_types WHERE id = ? AND is_custom = TRUE`, typeID,
 	).Scan(&schema.ID, &schema.Name, &schema.Labels.Singular, &schema.Labels.Plural,
-		&pdp, &fqn, &archived, &schema.CreatedAt, &schema.UpdatedAt)
+		&pdp, &searchable, &fqn, &archived, &schema.CreatedAt, &schema.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("schema %q not found", typeID)
@@ -82,6 +83,9 @@ func (s *SQLiteSchemaStore) loadSchema(ctx context.Context, typeID string) (*dom
 	schema.Archived = archived
 	schema.FullyQualifiedName = fqn.String
 	schema.PrimaryDisplayProperty = pdp.String
+	if schema.SearchableProperties, err = decodeSearchableProperties(searchable); err != nil {
+		return nil, err
+	}
 
 	props, err := s.loadProperties(ctx, typeID)
 	if err != nil {
@@ -188,56 +192,92 @@ func (s *SQLiteSchemaStore) List(ctx context.Context) ([]domain.ObjectSchema, er
 	return schemas, nil
 }
 
-// Create inserts a new custom object schema and registers the type.
+// Create inserts a new custom object schema and registers the type. The type,
+// its properties and its association types are written in one transaction.
+// A schema that declares no searchable properties searches its primary
+// display property.
 func (s *SQLiteSchemaStore) Create(ctx context.Context, schema *domain.ObjectSchema) (*domain.ObjectSchema, error) {
 	if schema.Name == "" {
 		return nil, fmt.Errorf("schema name is required")
 	}
 
-	typeID, err := s.nextCustomTypeID(ctx)
+	searchable := schema.SearchableProperties
+	if len(searchable) == 0 && schema.PrimaryDisplayProperty != "" {
+		searchable = []string{schema.PrimaryDisplayProperty}
+	}
+	searchableJSON, err := encodeSearchableProperties(searchable)
 	if err != nil {
 		return nil, err
 	}
 
-	ts := now()
-	fqn := "p0_" + schema.Name
-
-	_, err = s.db.ExecContext(ctx,
-		`INSERT INTO object_types (id, name, label_singular, label_plural, primary_display_property,
-		 is_custom, fully_qualified_name, archived, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, TRUE, ?, FALSE, ?, ?)`,
-		typeID, schema.Name, schema.Labels.Singular, schema.Labels.Plural,
-		schema.PrimaryDisplayProperty, fqn, ts, ts,
-	)
-	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
-			return nil, fmt.Errorf("schema %q already exists", schema.Name)
+	// Unknown associated object types are skipped.
+	var assocTypeIDs []string
+	for _, assocObj := range schema.AssociatedObjects {
+		if assocTypeID, err := ResolveObjectType(ctx, s.db, assocObj); err == nil {
+			assocTypeIDs = append(assocTypeIDs, assocTypeID)
 		}
-		return nil, fmt.Errorf("create schema: %w", err)
-	}
-
-	if err := s.createDefaultProperties(ctx, typeID, ts); err != nil {
-		return nil, err
 	}
 
-	// Auto-register default association types for declared associated objects.
-	for _, assocObj := range schema.AssociatedObjects {
-		assocTypeID, err := ResolveObjectType(ctx, s.db, assocObj)
+	var typeID string
+	err = withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		typeID, err = nextCustomTypeID(ctx, tx)
 		if err != nil {
-			continue
+			return err
 		}
-		// Create forward association: custom → associated
-		_, _ = s.db.ExecContext(ctx,
-			`INSERT OR IGNORE INTO association_types (from_object_type, to_object_type, category, label)
-			 VALUES (?, ?, 'HUBSPOT_DEFINED', NULL)`,
-			typeID, assocTypeID,
-		)
-		// Create reverse association: associated → custom
-		_, _ = s.db.ExecContext(ctx,
-			`INSERT OR IGNORE INTO association_types (from_object_type, to_object_type, category, label)
-			 VALUES (?, ?, 'HUBSPOT_DEFINED', NULL)`,
-			assocTypeID, typeID,
+
+		ts := now()
+		fqn := "p0_" + schema.Name
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO object_types (id, name, label_singular, label_plural, primary_display_property,
+			 searchable_properties, is_custom, fully_qualified_name, archived, created_at, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?, TRUE, ?, FALSE, ?, ?)`,
+			typeID, schema.Name, schema.Labels.Singular, schema.Labels.Plural,
+			schema.PrimaryDisplayProperty, searchableJSON, fqn, ts, ts,
 		)
+		if err != nil {
+			if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+				return fmt.Errorf("schema %q already exists", schema.Name)
+			}
+			return fmt.Errorf("create schema: %w", err)
+		}
+
+		if err := createDefaultProperties(ctx, tx, typeID, ts); err != nil {
+			return err
+		}
+
+		for i := range schema.Properties {
+			p := schema.Properties[i]
+			if p.GroupName == "" {
+				p.GroupName = "schemainfo"
+			}
+			if err := insertProperty(ctx, tx, typeID, &p); err != nil {
+				return fmt.Errorf("create schema property %q: %w", p.Name, err)
+			}
+		}
+
+		if err := checkSearchableProperties(ctx, tx, typeID, schema.SearchableProperties); err != nil {
+			return err
+		}
+
+		// Auto-register default association types for declared associated
+		// objects, in both directions.
+		for _, assocTypeID := range assocTypeIDs {
+			for _, pair := range [][2]string{{typeID, assocTypeID}, {assocTypeID, typeID}} {
+				if _, err := tx.ExecContext(ctx,
+					`INSERT OR IGNORE INTO association_types (from_object_type, to_object_type, category, label)
+					 VALUES (?, ?, 'HUBSPOT_DEFINED', NULL)`,
+					pair[0], pair[1],
+				); err != nil {
+					return fmt.Errorf("create schema association type: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return s.loadSchema(ctx, typeID)
@@ -245,7 +285,7 @@ func (s *SQLiteSchemaStore) Create(ctx context.Context, schema *domain.ObjectSch
 
 // createDefaultProperties inserts the standard HubSpot default properties for a
 // newly created custom object type.
-func (s *SQLiteSchemaStore) createDefaultProperties(ctx context.Context, typeID, ts string) error {
+func createDefaultProperties(ctx context.Context, ex execer, typeID, ts string) error {
 	defaults := []struct {
 		name, label, typ, fieldType string
 		hubspotDefined              bool
@@ -256,7 +296,7 @@ func (s *SQLiteSchemaStore) createDefaultProperties(ctx context.Context, typeID,
 	}
 
 	for _, d := range defaults {
-		_, err := s.db.ExecContext(ctx,
+		_, err := ex.ExecContext(ctx,
 			`INSERT INTO property_definitions (
 				object_type_id, name, label, type, field_type, group_name,
 				description, display_order, has_unique_value, hidden, form_field,
@@ -272,6 +312,39 @@ func (s *SQLiteSchemaStore) createDefaultProperties(ctx context.Context, typeID,
 	return nil
 }
 
+// encodeSearchableProperties encodes props for the searchable_properties
+// column of object_types.
+func encodeSearchableProperties(props []string) (string, error) {
+	if props == nil {
+		props = []string{}
+	}
+	b, err := json.Marshal(props)
+	if err != nil {
+		return "", fmt.Errorf("encode searchable properties: %w", err)
+	}
+	return string(b), nil
+}
+
+// checkSearchableProperties returns a ValidationError if one of props is not
+// an active property of typeID.
+func checkSearchableProperties(ctx context.Context, ex execer, typeID string, props []string) error {
+	for _, name := range props {
+		var exists bool
+		err := ex.QueryRowContext(ctx,
+			`SELECT EXISTS(SELECT 1 FROM property_definitions
+			 WHERE object_type_id = ? AND name = ? AND archived = FALSE)`,
+			typeID, name,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("check searchable property %q: %w", name, err)
+		}
+		if !exists {
+			return &ValidationError{Message: fmt.Sprintf("searchable property %q is not a property of the schema", name)}
+		}
+	}
+	return nil
+}
+
 // Get retrieves a single custom object schema by name or ID.
 func (s *SQLiteSchemaStore) Get(ctx context.Context, objectType string) (*domain.ObjectSchema, error) {
 	typeID, _, err := s.resolveSchemaType(ctx, objectType)
@@ -281,23 +354,35 @@ func (s *SQLiteSchemaStore) Get(ctx context.Context, objectType string) (*domain
 	return s.loadSchema(ctx, typeID)
 }
 
-// Update modifies an existing custom object schema.
+// Update modifies an existing custom object schema. Searchable properties are
+// replaced when the patch lists them, and left alone when it omits them.
 func (s *SQLiteSchemaStore) Update(ctx context.Context, objectType string, patch *domain.ObjectSchema) (*domain.ObjectSchema, error) {
 	typeID, _, err := s.resolveSchemaType(ctx, objectType)
 	if err != nil {
 		return nil, err
 	}
 
+	var searchableJSON any
+	if patch.SearchableProperties != nil {
+		if err := checkSearchableProperties(ctx, s.db, typeID, patch.SearchableProperties); err != nil {
+			return nil, err
+		}
+		if searchableJSON, err = encodeSearchableProperties(patch.SearchableProperties); err != nil {
+			return nil, err
+		}
+	}
+
 	ts := now()
 	res, err := s.db.ExecContext(ctx,
 		`UPDATE object_types SET
 			label_singular = COALESCE(NULLIF(?, ''), label_singular),
 			label_plural = COALESCE(NULLIF(?, ''), label_plural),
 			primary_display_property = COALESCE(NULLIF(?, ''), primary_display_property),
+			searchable_properties = COALESCE(?, searchable_properties),
 			updated_at = ?
 		 WHERE id = ? AND is_custom = TRUE AND archived = FALSE`,
 		patch.Labels.Singular, patch.Labels.Plural,
-		patch.PrimaryDisplayProperty, ts, typeID,
+		patch.PrimaryDisplayProperty, searchableJSON, ts, typeID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("update schema: %w", err)
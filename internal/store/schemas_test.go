@@ -2,6 +2,8 @@ package store_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/johnwards/hubspot/internal/database"
@@ -176,6 +178,49 @@ func TestSchemaStore_Update(t *testing.T) {
 	}
 }
 
+func TestSchemaStore_SearchableProperties(t *testing.T) {
+	s, ctx := setupSchemaStore(t)
+
+	schema := createTestSchema(t, s, ctx, "cars")
+	if got := fmt.Sprint(schema.SearchableProperties); got != "[hs_object_id]" {
+		t.Errorf("default searchable = %s, want [hs_object_id]", got)
+	}
+
+	// A patch without searchable properties leaves them alone.
+	updated, err := s.Update(ctx, "cars", &domain.ObjectSchema{Labels: domain.SchemaLabels{Singular: "Automobile"}})
+	if err != nil {
+		t.Fatalf("update labels: %v", err)
+	}
+	if got := fmt.Sprint(updated.SearchableProperties); got != "[hs_object_id]" {
+		t.Errorf("searchable after label update = %s, want [hs_object_id]", got)
+	}
+
+	updated, err = s.Update(ctx, "cars", &domain.ObjectSchema{SearchableProperties: []string{"hs_object_id", "hs_createdate"}})
+	if err != nil {
+		t.Fatalf("update searchable: %v", err)
+	}
+	if got := fmt.Sprint(updated.SearchableProperties); got != "[hs_object_id hs_createdate]" {
+		t.Errorf("searchable = %s, want [hs_object_id hs_createdate]", got)
+	}
+
+	var validationErr *store.ValidationError
+	_, err = s.Update(ctx, "cars", &domain.ObjectSchema{SearchableProperties: []string{"wheels"}})
+	if !errors.As(err, &validationErr) {
+		t.Errorf("update with unknown property: err = %v, want ValidationError", err)
+	}
+	_, err = s.Create(ctx, &domain.ObjectSchema{
+		Name:                 "boats",
+		Labels:               domain.SchemaLabels{Singular: "Boat", Plural: "Boats"},
+		SearchableProperties: []string{"hull"},
+	})
+	if !errors.As(err, &validationErr) {
+		t.Errorf("create with unknown property: err = %v, want ValidationError", err)
+	}
+	if _, err := s.Get(ctx, "boats"); err == nil {
+		t.Error("schema with unknown searchable property was created")
+	}
+}
+
 func TestSchemaStore_Update_NotFound(t *testing.T) {
 	s, ctx := setupSchemaStore(t)
 
@@ -3,9 +3,13 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/johnwards/hubspot/internal/domain"
 )
@@ -32,12 +36,6 @@ const (
 	maxSearchTotal     = 10000
 )
 
-// defaultSearchableProps are properties searched by the "query" field.
-var defaultSearchableProps = []string{
-	"email", "firstname", "lastname", "name", "domain", "company",
-	"hs_object_id", "phone", "website",
-}
-
 // Search executes a CRM search with filters, sorts, and pagination.
 func (s *SQLiteSearchStore) Search(ctx context.Context, objectType string, req *domain.SearchRequest) (*domain.SearchResult, error) {
 	typeID, err := ResolveObjectType(ctx, s.db, objectType)
@@ -76,8 +74,26 @@ func (s *SQLiteSearchStore) Search(ctx context.Context, objectType string, req *
 		}
 	}
 
+	types, err := propertyTypes(ctx, s.db, typeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotCalculated(ctx, s.db, typeID, req); err != nil {
+		return nil, err
+	}
+
+	assocTypes, err := associationFilterTypes(ctx, s.db, req)
+	if err != nil {
+		return nil, err
+	}
+
+	searchable, err := searchableProperties(ctx, s.db, typeID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build the shared FROM + WHERE clause used by both count and select.
-	fromClause, whereClause, baseArgs, sortAlias, err := buildSearchClauses(typeID, req)
+	fromClause, whereClause, baseArgs, orderClause, err := buildSearchClauses(typeID, req, types, assocTypes, searchable)
 	if err != nil {
 		return nil, err
 	}
@@ -97,16 +113,7 @@ func (s *SQLiteSearchStore) Search(ctx context.Context, objectType string, req *
 	selectArgs := make([]any, len(baseArgs))
 	copy(selectArgs, baseArgs)
 
-	if sortAlias != "" && len(req.Sorts) > 0 {
-		direction := "ASC"
-		if strings.EqualFold(req.Sorts[0].Direction, "DESCENDING") {
-			direction = "DESC"
-		}
-		selectSQL += fmt.Sprintf(" ORDER BY %s.value %s, o.id ASC", sortAlias, direction)
-	} else {
-		selectSQL += " ORDER BY o.id ASC"
-	}
-	selectSQL += " LIMIT ? OFFSET ?"
+	selectSQL += orderClause + " LIMIT ? OFFSET ?"
 	selectArgs = append(selectArgs, limit, offset)
 
 	rows, err := s.db.QueryContext(ctx, selectSQL, selectArgs...)
@@ -149,6 +156,13 @@ func (s *SQLiteSearchStore) Search(ctx context.Context, objectType string, req *
 
 		results = append(results, &obj)
 	}
+	want := allCalculated
+	if len(req.Properties) > 0 {
+		want = requested(req.Properties)
+	}
+	if err := applyCalculated(ctx, s.db, typeID, results, want); err != nil {
+		return nil, err
+	}
 
 	result := &domain.SearchResult{
 		Total:   total,
@@ -167,6 +181,58 @@ func (s *SQLiteSearchStore) Search(ctx context.Context, objectType string, req *
 	return result, nil
 }
 
+// propertyTypes returns the type of every property of typeID, including the
+// system properties that have no definition.
+func propertyTypes(ctx context.Context, ex execer, typeID string) (map[string]string, error) {
+	rows, err := ex.QueryContext(ctx,
+		`SELECT name, type FROM property_definitions WHERE object_type_id = ? AND archived = FALSE`, typeID)
+	if err != nil {
+		return nil, fmt.Errorf("load property types: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	types := maps.Clone(systemPropertyTypes)
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, fmt.Errorf("scan property type: %w", err)
+		}
+		types[name] = typ
+	}
+	return types, rows.Err()
+}
+
+// associationFilterPrefix starts the pseudo-property names that filter on
+// associated records, e.g. associations.company.
+const associationFilterPrefix = "associations."
+
+// associationFilterTypes resolves the object type of each associations.<type>
+// filter in req, keyed by property name. The type may be given as an object
+// type ID, its name or its singular label, e.g. "company".
+func associationFilterTypes(ctx context.Context, ex execer, req *domain.SearchRequest) (map[string]string, error) {
+	types := make(map[string]string)
+	for _, group := range req.FilterGroups {
+		for _, f := range group.Filters {
+			name, ok := strings.CutPrefix(f.PropertyName, associationFilterPrefix)
+			if !ok {
+				continue
+			}
+			var typeID string
+			err := ex.QueryRowContext(ctx,
+				`SELECT id FROM object_types WHERE id = ? OR name = ? OR label_singular = ? COLLATE NOCASE`,
+				name, name, name,
+			).Scan(&typeID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, &ValidationError{Message: fmt.Sprintf("unknown object type %s in filter %s", name, f.PropertyName)}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("resolve association filter: %w", err)
+			}
+			types[f.PropertyName] = typeID
+		}
+	}
+	return types, nil
+}
+
 // getProperties fetches property values for an object.
 func (s *SQLiteSearchStore) getProperties(ctx context.Context, objectID string, props []string) (map[string]string, error) {
 	var rows *sql.Rows
@@ -214,7 +280,33 @@ func (s *SQLiteSearchStore) getProperties(ctx context.Context, objectID string,
 	return result, rows.Err()
 }
 
-// ValidationError represents a search validation error.
+// searchableProperties returns the properties of typeID that the query field
+// of a search matches.
+func searchableProperties(ctx context.Context, ex execer, typeID string) ([]string, error) {
+	var raw sql.NullString
+	err := ex.QueryRowContext(ctx,
+		`SELECT searchable_properties FROM object_types WHERE id = ?`, typeID,
+	).Scan(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("get searchable properties: %w", err)
+	}
+	return decodeSearchableProperties(raw)
+}
+
+// decodeSearchableProperties decodes the searchable_properties column of an
+// object type, which is NULL for types that have none.
+func decodeSearchableProperties(raw sql.NullString) ([]string, error) {
+	props := []string{}
+	if !raw.Valid || raw.String == "" {
+		return props, nil
+	}
+	if err := json.Unmarshal([]byte(raw.String), &props); err != nil {
+		return nil, fmt.Errorf("decode searchable properties: %w", err)
+	}
+	return props, nil
+}
+
+// ValidationError represents an invalid search request or schema definition.
 type ValidationError struct {
 	Message string
 }
@@ -265,9 +357,33 @@ func isValidOperator(op string) bool {
 	return false
 }
 
-// buildSearchClauses builds the FROM and WHERE portions of the search query,
-// returning them along with the ordered args and the sort join alias (if any).
-func buildSearchClauses(typeID string, req *domain.SearchRequest) (fromClause, whereClause string, args []any, sortAlias string, err error) {
+// defaultSorts is the order of search results when the request has no sorts.
+var defaultSorts = []domain.Sort{{PropertyName: "createdate", Direction: "ASCENDING"}}
+
+// buildSearchClauses builds the FROM, WHERE and ORDER BY portions of the
+// search query, returning them along with the ordered args. types gives the
+// type of each property, which decides how its values compare and sort, and
+// assocTypes the object type of each associations.<type> filter. searchable
+// lists the properties the query field matches.
+//
+// Results are ordered by each sort in turn, with objects missing the value
+// last in either direction, and finally by ID so pages are stable.
+func buildSearchClauses(typeID string, req *domain.SearchRequest, types, assocTypes map[string]string, searchable []string) (fromClause, whereClause string, args []any, orderClause string, err error) {
+	sorts := req.Sorts
+	if len(sorts) == 0 {
+		sorts = defaultSorts
+	}
+	for _, sort := range sorts {
+		if _, ok := types[sort.PropertyName]; !ok {
+			err = &ValidationError{Message: fmt.Sprintf("cannot sort by %s: property does not exist", sort.PropertyName)}
+			return
+		}
+		if sort.Direction != "" && !strings.EqualFold(sort.Direction, "ASCENDING") && !strings.EqualFold(sort.Direction, "DESCENDING") {
+			err = &ValidationError{Message: fmt.Sprintf("invalid sort direction: %s", sort.Direction)}
+			return
+		}
+	}
+
 	var fromSB strings.Builder
 	var whereSB strings.Builder
 	filterIdx := 0
@@ -277,6 +393,11 @@ func buildSearchClauses(typeID string, req *domain.SearchRequest) (fromClause, w
 	// Add LEFT JOINs for each filter property.
 	for _, group := range req.FilterGroups {
 		for _, f := range group.Filters {
+			if _, ok := assocTypes[f.PropertyName]; ok || isTokenOperator(f.Operator) {
+				// Association and token filters query their own tables.
+				filterIdx++
+				continue
+			}
 			alias := fmt.Sprintf("pv_f%d", filterIdx)
 			fmt.Fprintf(&fromSB, " LEFT JOIN property_values %s ON %s.object_id = o.id AND %s.property_name = ?",
 				alias, alias, alias)
@@ -285,21 +406,25 @@ func buildSearchClauses(typeID string, req *domain.SearchRequest) (fromClause, w
 		}
 	}
 
-	// Add LEFT JOIN for query (full-text search across searchable properties).
-	queryAlias := ""
-	if req.Query != "" {
-		queryAlias = fmt.Sprintf("pv_q%d", filterIdx)
-		fmt.Fprintf(&fromSB, " LEFT JOIN property_values %s ON %s.object_id = o.id", queryAlias, queryAlias)
+	// Add LEFT JOINs for each sort property.
+	orderBy := make([]string, 0, 2*len(sorts)+1)
+	for _, sort := range sorts {
+		alias := fmt.Sprintf("pv_s%d", filterIdx)
+		fmt.Fprintf(&fromSB, " LEFT JOIN property_values %s ON %s.object_id = o.id AND %s.property_name = ?",
+			alias, alias, alias)
+		args = append(args, sort.PropertyName)
 		filterIdx++
-	}
 
-	// Add LEFT JOIN for sort property.
-	if len(req.Sorts) > 0 {
-		sortAlias = fmt.Sprintf("pv_s%d", filterIdx)
-		fmt.Fprintf(&fromSB, " LEFT JOIN property_values %s ON %s.object_id = o.id AND %s.property_name = ?",
-			sortAlias, sortAlias, sortAlias)
-		args = append(args, req.Sorts[0].PropertyName)
+		direction := "ASC"
+		if strings.EqualFold(sort.Direction, "DESCENDING") {
+			direction = "DESC"
+		}
+		orderBy = append(orderBy,
+			fmt.Sprintf("COALESCE(%s.value, '') = ''", alias),
+			typedValueSQL(alias+".value", types[sort.PropertyName])+" "+direction)
 	}
+	orderBy = append(orderBy, "o.id ASC")
+	orderClause = " ORDER BY " + strings.Join(orderBy, ", ")
 
 	// WHERE clause base.
 	whereSB.WriteString(" WHERE o.object_type_id = ? AND o.archived = FALSE")
@@ -313,7 +438,15 @@ func buildSearchClauses(typeID string, req *domain.SearchRequest) (fromClause, w
 			var filterClauses []string
 			for i := range group.Filters {
 				alias := fmt.Sprintf("pv_f%d", filterIdx)
-				clause, filterArgs, buildErr := buildFilterClause(alias, &group.Filters[i])
+				f := &group.Filters[i]
+				var clause string
+				var filterArgs []any
+				var buildErr error
+				if assocType, ok := assocTypes[f.PropertyName]; ok {
+					clause, filterArgs, buildErr = buildAssociationFilterClause(f, assocType)
+				} else {
+					clause, filterArgs, buildErr = buildFilterClause(alias, f, types[f.PropertyName])
+				}
 				if buildErr != nil {
 					err = buildErr
 					return
@@ -329,16 +462,13 @@ func buildSearchClauses(typeID string, req *domain.SearchRequest) (fromClause, w
 		whereSB.WriteString(")")
 	}
 
-	// Add query condition.
-	if req.Query != "" {
-		propPlaceholders := make([]string, len(defaultSearchableProps))
-		for i, p := range defaultSearchableProps {
-			propPlaceholders[i] = "?"
-			args = append(args, p)
-		}
-		fmt.Fprintf(&whereSB, " AND %s.property_name IN (%s)", queryAlias, strings.Join(propPlaceholders, ","))
-		fmt.Fprintf(&whereSB, " AND %s.value LIKE ?", queryAlias)
-		args = append(args, "%"+req.Query+"%")
+	// Add query condition: every term of the query must match a searchable
+	// property of the type, though not necessarily the same one. The last
+	// token of each term matches as a prefix.
+	for _, term := range strings.Fields(req.Query) {
+		clause, termArgs := tokenClause(strings.TrimSuffix(term, "*")+"*", searchable...)
+		whereSB.WriteString(" AND " + clause)
+		args = append(args, termArgs...)
 	}
 
 	fromClause = fromSB.String()
@@ -346,22 +476,45 @@ func buildSearchClauses(typeID string, req *domain.SearchRequest) (fromClause, w
 	return
 }
 
-func buildFilterClause(alias string, f *domain.Filter) (clause string, args []any, err error) {
+// buildFilterClause builds the condition for one filter on the property
+// value joined as alias. Comparisons of number, date and datetime properties
+// are made on their numeric values, with dates as epoch milliseconds.
+func buildFilterClause(alias string, f *domain.Filter, typ string) (clause string, args []any, err error) {
+	value := typedValueSQL(alias+".value", typ)
+	switch f.Operator {
+	case "EQ", "NEQ", "LT", "LTE", "GT", "GTE", "BETWEEN":
+		arg, err := typedFilterArg(f.PropertyName, typ, f.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		args = []any{arg}
+		if f.Operator == "BETWEEN" {
+			high, err := typedFilterArg(f.PropertyName, typ, f.HighValue)
+			if err != nil {
+				return "", nil, err
+			}
+			args = append(args, high)
+		}
+	}
+
+	// A cleared value is stored as "", which would otherwise compare as 0 or
+	// as the epoch; it has no value, like a property that was never set.
+	hasValue := fmt.Sprintf("%s.value != ''", alias)
 	switch f.Operator {
 	case "EQ":
-		return fmt.Sprintf("%s.value = ?", alias), []any{f.Value}, nil
+		return fmt.Sprintf("%s AND %s = ?", hasValue, value), args, nil
 	case "NEQ":
-		return fmt.Sprintf("(%s.value IS NULL OR %s.value != ?)", alias, alias), []any{f.Value}, nil
+		return fmt.Sprintf("(%s.value IS NULL OR %s.value = '' OR %s != ?)", alias, alias, value), args, nil
 	case "LT":
-		return fmt.Sprintf("%s.value < ?", alias), []any{f.Value}, nil
+		return fmt.Sprintf("%s AND %s < ?", hasValue, value), args, nil
 	case "LTE":
-		return fmt.Sprintf("%s.value <= ?", alias), []any{f.Value}, nil
+		return fmt.Sprintf("%s AND %s <= ?", hasValue, value), args, nil
 	case "GT":
-		return fmt.Sprintf("%s.value > ?", alias), []any{f.Value}, nil
+		return fmt.Sprintf("%s AND %s > ?", hasValue, value), args, nil
 	case "GTE":
-		return fmt.Sprintf("%s.value >= ?", alias), []any{f.Value}, nil
+		return fmt.Sprintf("%s AND %s >= ?", hasValue, value), args, nil
 	case "BETWEEN":
-		return fmt.Sprintf("%s.value BETWEEN ? AND ?", alias), []any{f.Value, f.HighValue}, nil
+		return fmt.Sprintf("%s AND %s BETWEEN ? AND ?", hasValue, value), args, nil
 	case "IN":
 		if len(f.Values) == 0 {
 			return "1=0", nil, nil
@@ -385,14 +538,111 @@ func buildFilterClause(alias string, f *domain.Filter) (clause string, args []an
 		}
 		return fmt.Sprintf("(%s.value IS NULL OR %s.value NOT IN (%s))", alias, alias, strings.Join(placeholders, ",")), fArgs, nil
 	case "HAS_PROPERTY":
-		return fmt.Sprintf("%s.value IS NOT NULL", alias), nil, nil
+		return fmt.Sprintf("(%s.value IS NOT NULL AND %s.value != '')", alias, alias), nil, nil
 	case "NOT_HAS_PROPERTY":
-		return fmt.Sprintf("%s.value IS NULL", alias), nil, nil
+		return fmt.Sprintf("(%s.value IS NULL OR %s.value = '')", alias, alias), nil, nil
 	case "CONTAINS_TOKEN":
-		return fmt.Sprintf("%s.value LIKE ?", alias), []any{"%" + f.Value + "%"}, nil
+		clause, args := tokenClause(f.Value, f.PropertyName)
+		return clause, args, nil
 	case "NOT_CONTAINS_TOKEN":
-		return fmt.Sprintf("(%s.value IS NULL OR %s.value NOT LIKE ?)", alias, alias), []any{"%" + f.Value + "%"}, nil
+		clause, args := tokenClause(f.Value, f.PropertyName)
+		return "NOT " + clause, args, nil
 	default:
 		return "", nil, &ValidationError{Message: fmt.Sprintf("unsupported operator: %s", f.Operator)}
 	}
 }
+
+// isTokenOperator reports whether op matches tokens through the full-text
+// index rather than comparing whole values.
+func isTokenOperator(op string) bool {
+	return op == "CONTAINS_TOKEN" || op == "NOT_CONTAINS_TOKEN"
+}
+
+// buildAssociationFilterClause builds the condition for an
+// associations.<type> filter, which matches objects by the IDs of their
+// associated records of assocType. EQ and IN match objects associated with
+// any of the given records, and HAS_PROPERTY objects with any association to
+// the type; NEQ, NOT_IN and NOT_HAS_PROPERTY negate them.
+func buildAssociationFilterClause(f *domain.Filter, assocType string) (clause string, args []any, err error) {
+	const exists = `EXISTS (SELECT 1 FROM associations a JOIN objects ao ON ao.id = a.to_object_id
+		WHERE a.from_object_id = o.id AND ao.object_type_id = ?%s)`
+	args = []any{assocType}
+	var ids []string
+	negate := false
+	switch f.Operator {
+	case "EQ", "NEQ":
+		ids = []string{f.Value}
+		negate = f.Operator == "NEQ"
+	case "IN", "NOT_IN":
+		if len(f.Values) == 0 {
+			if f.Operator == "IN" {
+				return "1=0", nil, nil
+			}
+			return "1=1", nil, nil
+		}
+		ids = f.Values
+		negate = f.Operator == "NOT_IN"
+	case "HAS_PROPERTY":
+	case "NOT_HAS_PROPERTY":
+		negate = true
+	default:
+		return "", nil, &ValidationError{Message: fmt.Sprintf("operator %s is not supported for %s", f.Operator, f.PropertyName)}
+	}
+
+	idClause := ""
+	if len(ids) > 0 {
+		placeholders := make([]string, len(ids))
+		for i, id := range ids {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		idClause = " AND a.to_object_id IN (" + strings.Join(placeholders, ",") + ")"
+	}
+	clause = fmt.Sprintf(exists, idClause)
+	if negate {
+		clause = "NOT " + clause
+	}
+	return clause, args, nil
+}
+
+// typedValueSQL returns the SQL expression that compares the stored value
+// column as a value of property type typ. Numbers compare as reals, and dates
+// and datetimes as epoch milliseconds whether they are stored as ISO 8601 or
+// as epoch milliseconds; other types compare as text.
+func typedValueSQL(column, typ string) string {
+	switch typ {
+	case "number":
+		return fmt.Sprintf("CAST(%s AS REAL)", column)
+	case "date", "datetime":
+		return fmt.Sprintf(
+			"(CASE WHEN %[1]s GLOB '*[^0-9]*' THEN CAST(ROUND((julianday(%[1]s) - 2440587.5) * 86400000) AS INTEGER) ELSE CAST(%[1]s AS INTEGER) END)",
+			column)
+	}
+	return column
+}
+
+// typedFilterArg converts a filter value to the value typedValueSQL compares
+// for property type typ. Dates and datetimes are accepted as epoch
+// milliseconds or ISO 8601.
+func typedFilterArg(property, typ, value string) (any, error) {
+	switch typ {
+	case "number":
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, &ValidationError{Message: fmt.Sprintf("%s is not a valid number for %s", value, property)}
+		}
+		return f, nil
+	case "date", "datetime":
+		value = strings.TrimSpace(value)
+		if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return ms, nil
+		}
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", time.DateOnly} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t.UnixMilli(), nil
+			}
+		}
+		return nil, &ValidationError{Message: fmt.Sprintf("%s is not a valid %s for %s", value, typ, property)}
+	}
+	return value, nil
+}
@@ -590,3 +590,398 @@ func TestSearchLTFilter(t *testing.T) {
 		t.Errorf("expected total=1, got %d", result.Total)
 	}
 }
+
+func TestSearchTypedComparisons(t *testing.T) {
+	ss, os := setupSearchStore(t)
+	ctx := context.Background()
+
+	ids := map[string]string{}
+	for _, d := range []struct{ name, amount, closedate string }{
+		{"small", "50", "2024-01-15"},
+		{"medium", "900", "2024-02-01"},
+		{"large", "1000", "2024-03-10"},
+	} {
+		obj, err := os.Create(ctx, "deals", map[string]string{"dealname": d.name, "amount": d.amount, "closedate": d.closedate})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		ids[obj.ID] = d.name
+	}
+
+	search := func(t *testing.T, req *domain.SearchRequest) []string {
+		t.Helper()
+		result, err := ss.Search(ctx, "deals", req)
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		names := make([]string, len(result.Results))
+		for i, obj := range result.Results {
+			names[i] = ids[obj.ID]
+		}
+		return names
+	}
+	filter := func(f domain.Filter) *domain.SearchRequest {
+		return &domain.SearchRequest{
+			FilterGroups: []domain.FilterGroup{{Filters: []domain.Filter{f}}},
+			Sorts:        []domain.Sort{{PropertyName: "amount", Direction: "ASCENDING"}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.Filter
+		want   string
+	}{
+		{"number GT", domain.Filter{PropertyName: "amount", Operator: "GT", Value: "900"}, "[large]"},
+		{"number BETWEEN", domain.Filter{PropertyName: "amount", Operator: "BETWEEN", Value: "60", HighValue: "1000"}, "[medium large]"},
+		{"number EQ", domain.Filter{PropertyName: "amount", Operator: "EQ", Value: "900.0"}, "[medium]"},
+		{"date GTE epoch ms", domain.Filter{PropertyName: "closedate", Operator: "GTE", Value: "1706745600000"}, "[medium large]"},
+		{"date LT ISO", domain.Filter{PropertyName: "closedate", Operator: "LT", Value: "2024-02-01T00:00:00.000Z"}, "[small]"},
+		{"date BETWEEN mixed", domain.Filter{PropertyName: "closedate", Operator: "BETWEEN", Value: "2024-01-01", HighValue: "1706745600000"}, "[small medium]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(search(t, filter(tt.filter))); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("sort numerically", func(t *testing.T) {
+		got := search(t, &domain.SearchRequest{Sorts: []domain.Sort{{PropertyName: "amount", Direction: "DESCENDING"}}})
+		if fmt.Sprint(got) != "[large medium small]" {
+			t.Errorf("got %v, want [large medium small]", got)
+		}
+	})
+
+	t.Run("invalid typed value", func(t *testing.T) {
+		_, err := ss.Search(ctx, "deals", filter(domain.Filter{PropertyName: "closedate", Operator: "GT", Value: "last week"}))
+		var ve *store.ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("got %v, want a ValidationError", err)
+		}
+	})
+
+	t.Run("cleared values", func(t *testing.T) {
+		obj, err := os.Create(ctx, "deals", map[string]string{"dealname": "cleared", "amount": "5", "closedate": "2024-01-02"})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		ids[obj.ID] = "cleared"
+		if _, err := os.Update(ctx, "deals", obj.ID, map[string]string{"amount": "", "closedate": ""}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		for _, tt := range []struct {
+			name   string
+			filter domain.Filter
+			want   string
+		}{
+			{"number LT", domain.Filter{PropertyName: "amount", Operator: "LT", Value: "10"}, "[]"},
+			{"number EQ zero", domain.Filter{PropertyName: "amount", Operator: "EQ", Value: "0"}, "[]"},
+			{"number NEQ", domain.Filter{PropertyName: "amount", Operator: "NEQ", Value: "50"}, "[medium large cleared]"},
+			{"date BETWEEN", domain.Filter{PropertyName: "closedate", Operator: "BETWEEN", Value: "0", HighValue: "2024-01-10"}, "[]"},
+			{"HAS_PROPERTY", domain.Filter{PropertyName: "amount", Operator: "HAS_PROPERTY"}, "[small medium large]"},
+			{"NOT_HAS_PROPERTY", domain.Filter{PropertyName: "amount", Operator: "NOT_HAS_PROPERTY"}, "[cleared]"},
+		} {
+			if got := fmt.Sprint(search(t, filter(tt.filter))); got != tt.want {
+				t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+			}
+		}
+	})
+}
+
+func TestSearchMultiKeySort(t *testing.T) {
+	ss, os := setupSearchStore(t)
+	ctx := context.Background()
+
+	names := map[string]string{}
+	for _, props := range []map[string]string{
+		{"dealname": "b", "amount": "100"},
+		{"dealname": "none"},
+		{"dealname": "c", "amount": "100"},
+		{"dealname": "a", "amount": "20"},
+	} {
+		obj, err := os.Create(ctx, "deals", props)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		names[obj.ID] = props["dealname"]
+	}
+	search := func(t *testing.T, sorts ...domain.Sort) ([]string, error) {
+		t.Helper()
+		result, err := ss.Search(ctx, "deals", &domain.SearchRequest{Sorts: sorts})
+		if err != nil {
+			return nil, err
+		}
+		got := make([]string, len(result.Results))
+		for i, obj := range result.Results {
+			got[i] = names[obj.ID]
+		}
+		return got, nil
+	}
+
+	tests := []struct {
+		name  string
+		sorts []domain.Sort
+		want  string
+	}{
+		{"default is creation order", nil, "[b none c a]"},
+		{"missing values last ascending", []domain.Sort{{PropertyName: "amount", Direction: "ASCENDING"}}, "[a b c none]"},
+		{"missing values last descending", []domain.Sort{{PropertyName: "amount", Direction: "DESCENDING"}}, "[b c a none]"},
+		{"tiebreak on second key", []domain.Sort{
+			{PropertyName: "amount", Direction: "DESCENDING"},
+			{PropertyName: "dealname", Direction: "DESCENDING"},
+		}, "[c b a none]"},
+		{"typed system property", []domain.Sort{{PropertyName: "hs_object_id", Direction: "DESCENDING"}}, "[a c none b]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := search(t, tt.sorts...)
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("got %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, sort := range []domain.Sort{
+		{PropertyName: "no_such_property", Direction: "ASCENDING"},
+		{PropertyName: "amount", Direction: "SIDEWAYS"},
+	} {
+		_, err := search(t, sort)
+		var ve *store.ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("sort %+v: got %v, want a ValidationError", sort, err)
+		}
+	}
+}
+
+func TestSearchAssociationFilters(t *testing.T) {
+	ss, os := setupSearchStore(t)
+	ctx := context.Background()
+
+	var companies []string
+	for _, name := range []string{"Acme", "Globex"} {
+		c, err := os.Create(ctx, "companies", map[string]string{"name": name})
+		if err != nil {
+			t.Fatalf("create company: %v", err)
+		}
+		companies = append(companies, c.ID)
+	}
+	names := map[string]string{}
+	for _, d := range []struct {
+		name    string
+		company string
+	}{
+		{"acme-1", companies[0]},
+		{"acme-2", companies[0]},
+		{"globex", companies[1]},
+		{"orphan", ""},
+	} {
+		input := domain.CreateInput{Properties: map[string]string{"dealname": d.name}}
+		if d.company != "" {
+			input.Associations = []domain.ObjectAssociationInput{{
+				To:    domain.AssociationTarget{ID: d.company},
+				Types: []domain.AssociationTypeInput{{AssociationCategory: "HUBSPOT_DEFINED", AssociationTypeID: 6}},
+			}}
+		}
+		deal, err := os.CreateWithAssociations(ctx, "deals", input)
+		if err != nil {
+			t.Fatalf("create deal: %v", err)
+		}
+		names[deal.ID] = d.name
+	}
+
+	tests := []struct {
+		filter domain.Filter
+		want   string
+	}{
+		{domain.Filter{PropertyName: "associations.company", Operator: "EQ", Value: companies[0]}, "[acme-1 acme-2]"},
+		{domain.Filter{PropertyName: "associations.companies", Operator: "NEQ", Value: companies[0]}, "[globex orphan]"},
+		{domain.Filter{PropertyName: "associations.0-2", Operator: "IN", Values: companies}, "[acme-1 acme-2 globex]"},
+		{domain.Filter{PropertyName: "associations.company", Operator: "HAS_PROPERTY"}, "[acme-1 acme-2 globex]"},
+		{domain.Filter{PropertyName: "associations.company", Operator: "NOT_HAS_PROPERTY"}, "[orphan]"},
+		{domain.Filter{PropertyName: "associations.contact", Operator: "HAS_PROPERTY"}, "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter.PropertyName+" "+tt.filter.Operator, func(t *testing.T) {
+			result, err := ss.Search(ctx, "deals", &domain.SearchRequest{
+				FilterGroups: []domain.FilterGroup{{Filters: []domain.Filter{tt.filter}}},
+			})
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			got := make([]string, len(result.Results))
+			for i, obj := range result.Results {
+				got[i] = names[obj.ID]
+			}
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("got %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, f := range []domain.Filter{
+		{PropertyName: "associations.spaceships", Operator: "HAS_PROPERTY"},
+		{PropertyName: "associations.company", Operator: "GT", Value: "1"},
+	} {
+		_, err := ss.Search(ctx, "deals", &domain.SearchRequest{
+			FilterGroups: []domain.FilterGroup{{Filters: []domain.Filter{f}}},
+		})
+		var ve *store.ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("filter %+v: got %v, want a ValidationError", f, err)
+		}
+	}
+}
+
+func TestSearchFullText(t *testing.T) {
+	ss, os := setupSearchStore(t)
+	ctx := context.Background()
+
+	names := map[string]string{}
+	for _, props := range []map[string]string{
+		{"firstname": "Alice", "lastname": "Martin", "email": "alice.martin@example.com"},
+		{"firstname": "Zoë", "lastname": "Keller", "email": "zoe@keller-works.io"},
+		{"firstname": "Bob", "lastname": "Alison", "email": "bob@other.org"},
+	} {
+		obj, err := os.Create(ctx, "contacts", props)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		names[obj.ID] = props["firstname"]
+	}
+	search := func(t *testing.T, req *domain.SearchRequest) string {
+		t.Helper()
+		result, err := ss.Search(ctx, "contacts", req)
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		got := make([]string, len(result.Results))
+		for i, obj := range result.Results {
+			got[i] = names[obj.ID]
+		}
+		return fmt.Sprint(got)
+	}
+	token := func(op, prop, value string) *domain.SearchRequest {
+		return &domain.SearchRequest{FilterGroups: []domain.FilterGroup{{Filters: []domain.Filter{
+			{PropertyName: prop, Operator: op, Value: value},
+		}}}}
+	}
+
+	tests := []struct {
+		name string
+		req  *domain.SearchRequest
+		want string
+	}{
+		{"email local part", token("CONTAINS_TOKEN", "email", "martin"), "[Alice]"},
+		{"email domain", token("CONTAINS_TOKEN", "email", "*@example.com"), "[Alice]"},
+		{"hyphenated domain", token("CONTAINS_TOKEN", "email", "keller-works.io"), "[Zoë]"},
+		{"case and diacritics", token("CONTAINS_TOKEN", "firstname", "ZOE"), "[Zoë]"},
+		{"wildcard", token("CONTAINS_TOKEN", "lastname", "Ali*"), "[Bob]"},
+		{"whole token", token("CONTAINS_TOKEN", "lastname", "ali"), "[]"},
+		{"word boundary", token("CONTAINS_TOKEN", "email", "ample"), "[]"},
+		{"negated", token("NOT_CONTAINS_TOKEN", "email", "example.com"), "[Zoë Bob]"},
+		{"query across properties", &domain.SearchRequest{Query: "alice martin"}, "[Alice]"},
+		{"query prefix", &domain.SearchRequest{Query: "Ali"}, "[Alice Bob]"},
+		{"query punctuation only", &domain.SearchRequest{Query: "@@"}, "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := search(t, tt.req); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("index follows updates", func(t *testing.T) {
+		for id, name := range names {
+			if name != "Bob" {
+				continue
+			}
+			if _, err := os.Update(ctx, "contacts", id, map[string]string{"lastname": ""}); err != nil {
+				t.Fatalf("update: %v", err)
+			}
+		}
+		if got := search(t, token("CONTAINS_TOKEN", "lastname", "alison")); got != "[]" {
+			t.Errorf("cleared value still matches: %s", got)
+		}
+	})
+}
+
+func TestSearchQuerySearchableProperties(t *testing.T) {
+	db := testhelpers.NewTestDB(t)
+	ctx := context.Background()
+	if err := database.Migrate(ctx, db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := seed.Seed(ctx, db); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	ss, os, schemas := store.NewSQLiteSearchStore(db), store.NewSQLiteObjectStore(db), store.NewSQLiteSchemaStore(db)
+
+	if _, err := schemas.Create(ctx, &domain.ObjectSchema{
+		Name:                 "cars",
+		Labels:               domain.SchemaLabels{Singular: "Car", Plural: "Cars"},
+		SearchableProperties: []string{"model"},
+		Properties: []domain.Property{
+			{Name: "model", Label: "Model", Type: "string", FieldType: "text"},
+			{Name: "color", Label: "Color", Type: "string", FieldType: "text"},
+		},
+	}); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	for _, c := range []struct {
+		objectType string
+		props      map[string]string
+	}{
+		{"deals", map[string]string{"dealname": "Acme renewal"}},
+		{"tickets", map[string]string{"subject": "Office hardware", "content": "The printer is jammed"}},
+		{"cars", map[string]string{"model": "Roadster", "color": "Red"}},
+	} {
+		if _, err := os.Create(ctx, c.objectType, c.props); err != nil {
+			t.Fatalf("create %s: %v", c.objectType, err)
+		}
+	}
+	count := func(t *testing.T, objectType, query string) int {
+		t.Helper()
+		result, err := ss.Search(ctx, objectType, &domain.SearchRequest{Query: query})
+		if err != nil {
+			t.Fatalf("search %s: %v", objectType, err)
+		}
+		return result.Total
+	}
+
+	tests := []struct {
+		objectType, query string
+		want              int
+	}{
+		{"deals", "acme", 1},
+		{"tickets", "hardware", 1},
+		{"tickets", "printer", 1},
+		{"contacts", "acme", 0},
+		{"cars", "roadster", 1},
+		{"cars", "red", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.objectType+" "+tt.query, func(t *testing.T) {
+			if got := count(t, tt.objectType, tt.query); got != tt.want {
+				t.Errorf("total = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := schemas.Update(ctx, "cars", &domain.ObjectSchema{SearchableProperties: []string{"color"}}); err != nil {
+		t.Fatalf("update schema: %v", err)
+	}
+	if got := count(t, "cars", "red"); got != 1 {
+		t.Errorf("after update: total for red = %d, want 1", got)
+	}
+	if got := count(t, "cars", "roadster"); got != 0 {
+		t.Errorf("after update: total for roadster = %d, want 0", got)
+	}
+}
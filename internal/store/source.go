@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+
+	"github.com/johnwards/hubspot/internal/domain"
+)
+
+type changeSourceKey struct{}
+
+// WithChangeSource returns a context that attributes property writes made
+// through it to src.
+func WithChangeSource(ctx context.Context, src domain.ChangeSource) context.Context {
+	return context.WithValue(ctx, changeSourceKey{}, src)
+}
+
+// ChangeSourceFrom returns the change source stored in ctx, defaulting to a
+// plain API write.
+func ChangeSourceFrom(ctx context.Context) domain.ChangeSource {
+	if src, ok := ctx.Value(changeSourceKey{}).(domain.ChangeSource); ok {
+		return src
+	}
+	return domain.ChangeSource{Type: domain.SourceAPI}
+}
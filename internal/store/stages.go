@@ -0,0 +1,307 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pipelineProperty names the pipeline and stage properties of an object type
+// that has pipelines.
+type pipelineProperty struct {
+	pipeline, stage string
+}
+
+// pipelineProperties holds the object types whose pipeline and stage values
+// are validated against their pipelines.
+var pipelineProperties = map[string]pipelineProperty{
+	"0-3": {pipeline: "pipeline", stage: "dealstage"},
+	"0-5": {pipeline: "hs_pipeline", stage: "hs_pipeline_stage"},
+}
+
+// StageError is returned when a write sets a pipeline or stage that does not
+// exist, is archived, or a stage that belongs to a different pipeline.
+type StageError struct {
+	Property string   // the pipeline or stage property
+	Value    string   // the rejected value
+	Allowed  []string // the pipeline or stage IDs that are valid
+}
+
+// Error returns HubSpot's message for a value that is not an allowed option.
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s was not one of the allowed options: [%s]", e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// applyPipeline validates the pipeline and stage values in props for an
+// object of typeID. objectID is the object being updated, whose stored values
+// fill in whichever of the two props leaves out; it is empty for new objects,
+// which get the default pipeline and its first stage when none is given.
+func applyPipeline(ctx context.Context, ex execer, typeID, objectID string, props map[string]string) error {
+	pp, ok := pipelineProperties[typeID]
+	if !ok {
+		return nil
+	}
+	pipeline, stage := props[pp.pipeline], props[pp.stage]
+
+	if objectID != "" {
+		if pipeline == "" && stage == "" {
+			return nil
+		}
+		current, err := getAllProperties(ctx, ex, objectID)
+		if err != nil {
+			return err
+		}
+		if _, set := props[pp.pipeline]; !set {
+			pipeline = current[pp.pipeline]
+		}
+		if _, set := props[pp.stage]; !set {
+			stage = current[pp.stage]
+		}
+	}
+
+	if pipeline == "" {
+		var err error
+		if stage != "" {
+			pipeline, err = stagePipeline(ctx, ex, typeID, stage)
+		} else {
+			pipeline, err = defaultPipeline(ctx, ex, typeID)
+		}
+		if err != nil {
+			return err
+		}
+		if pipeline == "" {
+			if stage == "" {
+				// The type has no pipelines to apply.
+				return nil
+			}
+			allowed, err := pipelineStageIDs(ctx, ex, typeID)
+			if err != nil {
+				return err
+			}
+			return &StageError{Property: pp.stage, Value: stage, Allowed: allowed}
+		}
+		props[pp.pipeline] = pipeline
+	}
+
+	pipelines, err := queryIDs(ctx, ex,
+		`SELECT id FROM pipelines WHERE object_type_id = ? AND archived = FALSE ORDER BY display_order, id`, typeID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(pipelines, pipeline) {
+		return &StageError{Property: pp.pipeline, Value: pipeline, Allowed: pipelines}
+	}
+
+	stages, err := queryIDs(ctx, ex,
+		`SELECT id FROM pipeline_stages WHERE pipeline_id = ? AND archived = FALSE ORDER BY display_order, id`, pipeline)
+	if err != nil {
+		return err
+	}
+	if stage == "" {
+		if len(stages) == 0 {
+			return nil
+		}
+		stage = stages[0]
+		props[pp.stage] = stage
+	}
+	if !slices.Contains(stages, stage) {
+		return &StageError{Property: pp.stage, Value: stage, Allowed: stages}
+	}
+	return nil
+}
+
+// defaultPipeline returns the ID of the first pipeline of typeID, or "" if
+// the type has none.
+func defaultPipeline(ctx context.Context, ex execer, typeID string) (string, error) {
+	var id string
+	err := ex.QueryRowContext(ctx,
+		`SELECT id FROM pipelines WHERE object_type_id = ? AND archived = FALSE ORDER BY display_order, id LIMIT 1`, typeID,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get default pipeline: %w", err)
+	}
+	return id, nil
+}
+
+// stagePipeline returns the ID of the pipeline of typeID that stage belongs
+// to, or "" if it belongs to none.
+func stagePipeline(ctx context.Context, ex execer, typeID, stage string) (string, error) {
+	var id string
+	err := ex.QueryRowContext(ctx,
+		`SELECT p.id FROM pipeline_stages s JOIN pipelines p ON p.id = s.pipeline_id
+		 WHERE s.id = ? AND p.object_type_id = ? AND p.archived = FALSE`, stage, typeID,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get stage pipeline: %w", err)
+	}
+	return id, nil
+}
+
+// pipelineStageIDs returns the IDs of the active stages of every pipeline of
+// typeID.
+func pipelineStageIDs(ctx context.Context, ex execer, typeID string) ([]string, error) {
+	return queryIDs(ctx, ex,
+		`SELECT s.id FROM pipeline_stages s JOIN pipelines p ON p.id = s.pipeline_id
+		 WHERE p.object_type_id = ? AND p.archived = FALSE AND s.archived = FALSE
+		 ORDER BY p.display_order, p.id, s.display_order, s.id`, typeID)
+}
+
+// queryIDs returns the single ID column of query's rows as strings.
+func queryIDs(ctx context.Context, ex execer, query string, args ...any) ([]string, error) {
+	rows, err := ex.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query ids: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// stageMetadata returns the metadata of a pipeline stage, or nil if the stage
+// does not exist.
+func stageMetadata(ctx context.Context, ex execer, stage string) (map[string]string, error) {
+	var raw string
+	err := ex.QueryRowContext(ctx, `SELECT COALESCE(metadata, '{}') FROM pipeline_stages WHERE id = ?`, stage).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get stage metadata: %w", err)
+	}
+	var meta map[string]string
+	if err := json.Unmarshal([]byte(raw), &meta); err != nil {
+		return nil, fmt.Errorf("decode stage metadata: %w", err)
+	}
+	return meta, nil
+}
+
+// applyStageProperties adds the properties HubSpot derives from an object's
+// stage to props when the write moves it to another stage. Entering a stage
+// records hs_date_entered_{stageId}, and leaving one records
+// hs_date_exited_{stageId} and hs_time_in_{stageId} in milliseconds. Deals
+// also get hs_is_closed, hs_is_closed_won, hs_deal_stage_probability and a
+// closedate when they close, and hs_forecast_amount follows both the stage
+// probability and the amount. Tickets get closed_date and time_to_close when
+// they enter a CLOSED stage, and lose them when reopened. objectID is empty
+// for new objects.
+func applyStageProperties(ctx context.Context, ex execer, typeID, objectID string, props map[string]string, ts string) error {
+	pp, ok := pipelineProperties[typeID]
+	if !ok {
+		return nil
+	}
+	current := map[string]string{}
+	if objectID != "" {
+		var err error
+		if current, err = getAllProperties(ctx, ex, objectID); err != nil {
+			return err
+		}
+	}
+
+	stage, stageSet := props[pp.stage]
+	moved := stageSet && stage != current[pp.stage]
+	amount, amountSet := props["amount"]
+	if !moved && !(typeID == "0-3" && amountSet) {
+		return nil
+	}
+	if !stageSet {
+		stage = current[pp.stage]
+	}
+	if !amountSet {
+		amount = current["amount"]
+	}
+	var meta map[string]string
+	if stage != "" {
+		var err error
+		if meta, err = stageMetadata(ctx, ex, stage); err != nil {
+			return err
+		}
+	}
+
+	if moved {
+		if prev := current[pp.stage]; prev != "" {
+			props["hs_date_exited_"+prev] = ts
+			if ms, ok := millisBetween(current["hs_date_entered_"+prev], ts); ok {
+				props["hs_time_in_"+prev] = strconv.FormatInt(ms, 10)
+			}
+		}
+		if stage != "" {
+			props["hs_date_entered_"+stage] = ts
+		}
+	}
+
+	closed := meta["isClosed"] == "true" || meta["ticketState"] == "CLOSED"
+	switch typeID {
+	case "0-3":
+		probability, err := strconv.ParseFloat(meta["probability"], 64)
+		hasProbability := err == nil
+		if moved {
+			props["hs_is_closed"] = strconv.FormatBool(closed)
+			props["hs_is_closed_won"] = strconv.FormatBool(closed && probability == 1)
+			props["hs_deal_stage_probability"] = meta["probability"]
+			if _, set := props["closedate"]; closed && !set {
+				props["closedate"] = ts[:len(time.DateOnly)]
+			}
+		}
+		forecast := ""
+		if a, err := strconv.ParseFloat(amount, 64); err == nil && hasProbability {
+			forecast = strconv.FormatFloat(a*probability, 'f', -1, 64)
+		}
+		if forecast != current["hs_forecast_amount"] {
+			props["hs_forecast_amount"] = forecast
+		}
+
+	case "0-5":
+		if !moved {
+			return nil
+		}
+		wasClosed := current["closed_date"] != ""
+		switch {
+		case closed && !wasClosed:
+			props["closed_date"] = ts
+			created := props["createdate"]
+			if created == "" {
+				created = current["createdate"]
+			}
+			if ms, ok := millisBetween(created, ts); ok {
+				props["time_to_close"] = strconv.FormatInt(ms, 10)
+			}
+		case !closed && wasClosed:
+			props["closed_date"] = ""
+			props["time_to_close"] = ""
+		}
+	}
+	return nil
+}
+
+// millisBetween returns the milliseconds from the timestamp from to the
+// timestamp to, and false if from is not a timestamp.
+func millisBetween(from, to string) (int64, bool) {
+	start, err := time.Parse(time.RFC3339Nano, from)
+	if err != nil {
+		return 0, false
+	}
+	end, err := time.Parse(time.RFC3339Nano, to)
+	if err != nil {
+		return 0, false
+	}
+	return end.Sub(start).Milliseconds(), true
+}
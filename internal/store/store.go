@@ -11,6 +11,16 @@ type Store struct {
 	Exports ExportStore
 	Owners  OwnerStore
 	Lists   ListStore
+
+	Properties PropertyStore
+
+	Associations AssociationStore
+
+	RequestLog RequestLogStore
+
+	// AllowUnknownProperties accepts writes to properties that have no
+	// definition instead of rejecting them with PROPERTY_DOESNT_EXIST.
+	AllowUnknownProperties bool
 }
 
 // New creates a Store with all sub-stores initialized.
@@ -23,5 +33,11 @@ func New(db *sql.DB) *Store {
 		Exports: NewSQLiteExportStore(db),
 		Owners:  NewSQLiteOwnerStore(db),
 		Lists:   NewSQLiteListStore(db),
+
+		Properties: NewSQLitePropertyStore(db),
+
+		Associations: NewSQLiteAssociationStore(db),
+
+		RequestLog: NewSQLiteRequestLogStore(db),
 	}
 }
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// execer runs statements against either a *sql.DB or a *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// withTx runs fn in a transaction on db. The transaction commits when fn
+// returns nil and rolls back when it returns an error or panics, or when ctx
+// is cancelled first, so a failed mutation never leaves partial writes.
+//
+// The database allows a single open connection, so fn must issue every
+// statement through tx; using db inside fn blocks forever.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// withSavepoint runs fn inside a savepoint of tx. When fn fails, only its
+// writes are undone and the transaction stays usable, which lets a batch
+// skip one bad input and still commit the rest.
+func withSavepoint(ctx context.Context, tx *sql.Tx, fn func() error) error {
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT batch_input`); err != nil {
+		return fmt.Errorf("savepoint: %w", err)
+	}
+	if err := fn(); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO batch_input`); rbErr != nil {
+			return fmt.Errorf("rollback to savepoint: %w", rbErr)
+		}
+		_, _ = tx.ExecContext(ctx, `RELEASE batch_input`)
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `RELEASE batch_input`); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/johnwards/hubspot/internal/database"
+	"github.com/johnwards/hubspot/internal/domain"
+	"github.com/johnwards/hubspot/internal/seed"
+	"github.com/johnwards/hubspot/internal/store"
+	"github.com/johnwards/hubspot/internal/testhelpers"
+)
+
+func setupTxDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db := testhelpers.NewTestDB(t)
+	ctx := context.Background()
+	if err := database.Migrate(ctx, db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := seed.Seed(ctx, db); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db
+}
+
+func countRows(t *testing.T, db *sql.DB, query string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRowContext(context.Background(), query, args...).Scan(&n); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	return n
+}
+
+// badAssociation points at an object that does not exist, which fails the
+// create after the object row and its properties have been written.
+var badAssociation = []domain.ObjectAssociationInput{{
+	To:    domain.AssociationTarget{ID: "999999"},
+	Types: []domain.AssociationTypeInput{{AssociationCategory: "HUBSPOT_DEFINED", AssociationTypeID: 1}},
+}}
+
+func TestCreateRollsBackOnFailure(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	ctx := context.Background()
+
+	_, err := s.CreateWithAssociations(ctx, "contacts", domain.CreateInput{
+		Properties:   map[string]string{"email": "orphan@example.com"},
+		Associations: badAssociation,
+	})
+	if !errors.Is(err, store.ErrInvalidAssociation) {
+		t.Fatalf("expected ErrInvalidAssociation, got %v", err)
+	}
+
+	if n := countRows(t, db, `SELECT COUNT(*) FROM objects`); n != 0 {
+		t.Errorf("expected no objects, got %d", n)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM property_values`); n != 0 {
+		t.Errorf("expected no property values, got %d", n)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM property_value_history`); n != 0 {
+		t.Errorf("expected no history, got %d", n)
+	}
+}
+
+func TestBatchCreateRollsBackWholeBatchOnFailure(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	ctx := context.Background()
+
+	_, err := s.BatchCreate(ctx, "contacts", []domain.CreateInput{
+		{Properties: map[string]string{"email": "first@example.com"}},
+		{Properties: map[string]string{"email": "second@example.com"}, Associations: badAssociation},
+	})
+	if !errors.Is(err, store.ErrInvalidAssociation) {
+		t.Fatalf("expected ErrInvalidAssociation, got %v", err)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM objects`); n != 0 {
+		t.Errorf("expected the first input to be rolled back, got %d objects", n)
+	}
+}
+
+func TestBatchCreateSkipsConflictingInput(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+	ctx := context.Background()
+
+	result, err := s.BatchCreate(ctx, "companies", []domain.CreateInput{
+		{Properties: map[string]string{"domain": "one.com"}},
+		{Properties: map[string]string{"domain": "one.com"}},
+		{Properties: map[string]string{"domain": "two.com"}},
+	})
+	if err != nil {
+		t.Fatalf("batch create: %v", err)
+	}
+	if len(result.Results) != 2 || result.NumErrors != 1 {
+		t.Fatalf("expected 2 results and 1 error, got %d results, %d errors", len(result.Results), result.NumErrors)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM objects`); n != 2 {
+		t.Errorf("expected 2 objects, got %d", n)
+	}
+	if n := countRows(t, db,
+		`SELECT COUNT(*) FROM property_values WHERE object_id NOT IN (SELECT id FROM objects)`); n != 0 {
+		t.Errorf("expected no orphan property values, got %d", n)
+	}
+}
+
+func TestCreateWithCancelledContextWritesNothing(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteObjectStore(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Create(ctx, "contacts", map[string]string{"email": "late@example.com"}); err == nil {
+		t.Fatal("expected an error for a cancelled context")
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM property_values`); n != 0 {
+		t.Errorf("expected no property values, got %d", n)
+	}
+}
+
+func TestSchemaCreateRollsBackOnFailure(t *testing.T) {
+	db := setupTxDB(t)
+	s := store.NewSQLiteSchemaStore(db)
+	ctx := context.Background()
+
+	// The second property repeats the first name, which fails after the type
+	// and its default properties have been written.
+	_, err := s.Create(ctx, &domain.ObjectSchema{
+		Name:   "cars",
+		Labels: domain.SchemaLabels{Singular: "Car", Plural: "Cars"},
+		Properties: []domain.Property{
+			{Name: "vin", Label: "VIN", Type: "string", FieldType: "text"},
+			{Name: "vin", Label: "VIN", Type: "string", FieldType: "text"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected an error for duplicate schema properties")
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM object_types WHERE name = 'cars'`); n != 0 {
+		t.Errorf("expected the object type to be rolled back, got %d", n)
+	}
+	if n := countRows(t, db,
+		`SELECT COUNT(*) FROM property_definitions WHERE object_type_id NOT IN (SELECT id FROM object_types)`); n != 0 {
+		t.Errorf("expected no orphan property definitions, got %d", n)
+	}
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/johnwards/hubspot/internal/domain"
+)
+
+// UniqueValueError is returned when a write would give a hasUniqueValue
+// property the same value as another non-archived object of the same type.
+// It wraps ErrConflict.
+type UniqueValueError struct {
+	ObjectLabel string // singular object type label, e.g. "Contact"
+	Property    string
+	Value       string
+	ExistingID  string
+}
+
+// Error returns HubSpot's conflict message, which clients parse for the
+// existing object's ID.
+func (e *UniqueValueError) Error() string {
+	return fmt.Sprintf("%s already exists. Existing ID: %s", e.ObjectLabel, e.ExistingID)
+}
+
+// Unwrap lets errors.Is match ErrConflict.
+func (e *UniqueValueError) Unwrap() error { return ErrConflict }
+
+// Context returns the HubSpot error context for the conflict.
+func (e *UniqueValueError) Context() map[string][]string {
+	return map[string][]string{
+		"id":           {e.ExistingID},
+		"propertyName": {e.Property},
+	}
+}
+
+// checkUniqueValues returns a *UniqueValueError if any hasUniqueValue
+// property in props already holds the same value, ignoring case, on another
+// non-archived object of typeID. objectID is the object being written and is
+// excluded from the check; it is empty for new objects. Empty values are
+// never considered duplicates. Properties are checked in name order, so the
+// conflict reported for a write is always the same one.
+func checkUniqueValues(ctx context.Context, ex execer, typeID, objectID string, props map[string]string) error {
+	if len(props) == 0 {
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(props)) {
+		value := props[name]
+		if value == "" {
+			continue
+		}
+		var unique bool
+		err := ex.QueryRowContext(ctx,
+			`SELECT has_unique_value FROM property_definitions WHERE object_type_id = ? AND name = ?`,
+			typeID, name,
+		).Scan(&unique)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("check unique property %s: %w", name, err)
+		}
+		if !unique {
+			continue
+		}
+
+		var existingID, label string
+		err = ex.QueryRowContext(ctx,
+			`SELECT pv.object_id, ot.label_singular FROM property_values pv
+			 JOIN objects o ON o.id = pv.object_id
+			 JOIN object_types ot ON ot.id = o.object_type_id
+			 WHERE o.object_type_id = ? AND o.archived = FALSE AND pv.property_name = ?
+			   AND pv.value = ? COLLATE NOCASE AND pv.object_id != ?
+			 ORDER BY pv.object_id LIMIT 1`,
+			typeID, name, value, objectID,
+		).Scan(&existingID, &label)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("check unique property %s: %w", name, err)
+		}
+		return &UniqueValueError{ObjectLabel: label, Property: name, Value: value, ExistingID: existingID}
+	}
+	return nil
+}
+
+// addUniqueValueError records err against result if it is a
+// *UniqueValueError and reports whether it did, so batch operations can skip
+// the offending input and carry on.
+func addUniqueValueError(result *domain.BatchResult, err error) bool {
+	var uv *UniqueValueError
+	if !errors.As(err, &uv) {
+		return false
+	}
+	addBatchError(result, domain.BatchError{
+		Status:   "error",
+		Category: "CONFLICT",
+		Message:  uv.Error(),
+		Context:  uv.Context(),
+	})
+	return true
+}
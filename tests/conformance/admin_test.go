@@ -3,6 +3,7 @@ package conformance_test
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -55,18 +56,45 @@ func TestSeedEndpoint(t *testing.T) {
 func TestRequestLog(t *testing.T) {
 	resetServer(t)
 
-	// The request log endpoint should respond with 200 and a results array.
+	// Reset clears the log, and /_notspot/ traffic is excluded, so the log
+	// should contain exactly the CRM calls made below.
+	contact := createContact(t, map[string]string{"email": "requestlog@example.com"})
+	contactID := assertIsString(t, contact, "id")
+	getResp := doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+contactID+"?properties=email", nil)
+	mustStatus(t, getResp, http.StatusOK)
+	corrID := getResp.Header.Get("X-Correlation-Id")
+	_ = getResp.Body.Close()
+
 	resp := doRequest(t, http.MethodGet, "/_notspot/requests", nil)
 	mustStatus(t, resp, http.StatusOK)
 	body := readJSON(t, resp)
 
 	results := assertIsArray(t, body, "results")
+	if len(results) != 2 {
+		t.Fatalf("expected 2 logged requests, got %d: %v", len(results), results)
+	}
 
-	// If request logging middleware is not yet wired, the results array will
-	// be empty — that's OK, we just verify the endpoint responds correctly.
-	if len(results) == 0 {
-		t.Log("request log is empty (logging middleware may not be wired); skipping entry assertions")
-		return
+	// Newest first: the GET, then the create.
+	get := toObject(t, results[0])
+	assertStringField(t, get, "method", http.MethodGet)
+	assertStringField(t, get, "path", "/crm/v3/objects/contacts/"+contactID)
+	assertStringField(t, get, "query", "properties=email")
+	assertStringField(t, get, "correlationId", corrID)
+	if code, _ := get["statusCode"].(float64); code != http.StatusOK {
+		t.Errorf("expected statusCode 200, got %v", get["statusCode"])
+	}
+
+	create := toObject(t, results[1])
+	assertStringField(t, create, "method", http.MethodPost)
+	assertStringField(t, create, "path", "/crm/v3/objects/contacts")
+	if code, _ := create["statusCode"].(float64); code != http.StatusCreated {
+		t.Errorf("expected statusCode 201, got %v", create["statusCode"])
+	}
+	if reqBody, _ := create["requestBody"].(string); !strings.Contains(reqBody, "requestlog@example.com") {
+		t.Errorf("expected requestBody to contain the email, got %q", reqBody)
+	}
+	if respBody, _ := create["responseBody"].(string); !strings.Contains(respBody, contactID) {
+		t.Errorf("expected responseBody to contain the contact ID, got %q", respBody)
 	}
 
 	// Verify each entry has the expected fields.
@@ -115,3 +143,203 @@ func TestRequestLog(t *testing.T) {
 		}
 	})
 }
+
+func TestRequestLogFilters(t *testing.T) {
+	resetServer(t)
+
+	contact := createContact(t, map[string]string{"email": "filters@example.com"})
+	contactID := assertIsString(t, contact, "id")
+
+	missing := doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/999999999", nil)
+	mustStatus(t, missing, http.StatusNotFound)
+	missingCorrID := missing.Header.Get("X-Correlation-Id")
+	_ = missing.Body.Close()
+
+	get := doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+contactID+"?properties=email", nil)
+	mustStatus(t, get, http.StatusOK)
+	_ = get.Body.Close()
+
+	countFor := func(t *testing.T, query string) []any {
+		t.Helper()
+		resp := doRequest(t, http.MethodGet, "/_notspot/requests?"+query, nil)
+		mustStatus(t, resp, http.StatusOK)
+		return assertIsArray(t, readJSON(t, resp), "results")
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"method=POST", 1},
+		{"method=get,delete", 2},
+		{"status=4xx", 1},
+		{"status=200,201", 2},
+		{"path=/crm/v3/objects/contacts/", 2},
+		{"path=/crm/v3/objects/*/999999999", 1},
+		{"correlationId=" + missingCorrID, 1},
+		{"body=filters@example.com", 2},
+		{"since=2000-01-01T00:00:00Z&until=2999-01-01T00:00:00Z", 3},
+		{"until=946684800000", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := countFor(t, tt.query); len(got) != tt.want {
+				t.Errorf("expected %d entries, got %d", tt.want, len(got))
+			}
+		})
+	}
+
+	t.Run("invalid status", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/_notspot/requests?status=teapot", nil)
+		mustStatus(t, resp, http.StatusBadRequest)
+		assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+	})
+}
+
+func TestRequestLogEntry(t *testing.T) {
+	resetServer(t)
+	createContact(t, map[string]string{"email": "entry@example.com"})
+
+	resp := doRequest(t, http.MethodGet, "/_notspot/requests?limit=1", nil)
+	mustStatus(t, resp, http.StatusOK)
+	results := assertIsArray(t, readJSON(t, resp), "results")
+	if len(results) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(results))
+	}
+	id := fmt.Sprintf("%.0f", toObject(t, results[0])["id"].(float64))
+
+	resp = doRequest(t, http.MethodGet, "/_notspot/requests/"+id, nil)
+	mustStatus(t, resp, http.StatusOK)
+	entry := readJSON(t, resp)
+	assertStringField(t, entry, "method", http.MethodPost)
+	headers := assertIsObject(t, entry, "requestHeaders")
+	auth, _ := headers["Authorization"].([]any)
+	if len(auth) != 1 || auth[0] != "Bearer [REDACTED]" {
+		t.Errorf("expected redacted Authorization header, got %v", headers["Authorization"])
+	}
+
+	resp = doRequest(t, http.MethodGet, "/_notspot/requests/999999999", nil)
+	mustStatus(t, resp, http.StatusNotFound)
+	assertHubSpotError(t, readJSON(t, resp), "OBJECT_NOT_FOUND")
+}
+
+func TestRequestLogHAR(t *testing.T) {
+	resetServer(t)
+	createContact(t, map[string]string{"email": "har@example.com"})
+	resp := doRequest(t, http.MethodGet, "/crm/v3/objects/contacts?limit=5", nil)
+	mustStatus(t, resp, http.StatusOK)
+	_ = resp.Body.Close()
+
+	resp = doRequest(t, http.MethodGet, "/_notspot/requests.har", nil)
+	mustStatus(t, resp, http.StatusOK)
+	body := readJSON(t, resp)
+
+	harLog := assertIsObject(t, body, "log")
+	assertStringField(t, harLog, "version", "1.2")
+	assertIsObject(t, harLog, "creator")
+	entries := assertIsArray(t, harLog, "entries")
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 HAR entries, got %d", len(entries))
+	}
+
+	// Entries are chronological.
+	first := toObject(t, entries[0])
+	assertISOTimestamp(t, assertIsString(t, first, "startedDateTime"))
+	req := assertIsObject(t, first, "request")
+	assertStringField(t, req, "method", http.MethodPost)
+	postData := assertIsObject(t, req, "postData")
+	if text, _ := postData["text"].(string); !strings.Contains(text, "har@example.com") {
+		t.Errorf("expected postData text to contain the email, got %q", text)
+	}
+	res := assertIsObject(t, first, "response")
+	if status, _ := res["status"].(float64); status != http.StatusCreated {
+		t.Errorf("expected response status 201, got %v", res["status"])
+	}
+	assertIsObject(t, res, "content")
+
+	second := toObject(t, entries[1])
+	req2 := assertIsObject(t, second, "request")
+	if u, _ := req2["url"].(string); !strings.HasSuffix(u, "/crm/v3/objects/contacts?limit=5") {
+		t.Errorf("unexpected request url %q", u)
+	}
+	qs := assertIsArray(t, req2, "queryString")
+	if len(qs) != 1 || toObject(t, qs[0])["name"] != "limit" {
+		t.Errorf("unexpected queryString %v", qs)
+	}
+
+	t.Run("filtered", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/_notspot/requests.har?method=GET", nil)
+		mustStatus(t, resp, http.StatusOK)
+		harLog := assertIsObject(t, readJSON(t, resp), "log")
+		if entries := assertIsArray(t, harLog, "entries"); len(entries) != 1 {
+			t.Errorf("expected 1 filtered HAR entry, got %d", len(entries))
+		}
+	})
+
+	t.Run("limit truncates with a comment", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/_notspot/requests.har?limit=1", nil)
+		mustStatus(t, resp, http.StatusOK)
+		harLog := assertIsObject(t, readJSON(t, resp), "log")
+		entries := assertIsArray(t, harLog, "entries")
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 HAR entry with limit=1, got %d", len(entries))
+		}
+		req := assertIsObject(t, toObject(t, entries[0]), "request")
+		assertStringField(t, req, "method", http.MethodGet)
+		if comment, _ := harLog["comment"].(string); !strings.Contains(comment, "truncated") {
+			t.Errorf("expected a truncation comment, got %q", comment)
+		}
+	})
+
+	t.Run("complete export has no comment", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/_notspot/requests.har", nil)
+		mustStatus(t, resp, http.StatusOK)
+		harLog := assertIsObject(t, readJSON(t, resp), "log")
+		if _, ok := harLog["comment"]; ok {
+			t.Errorf("unexpected comment %v", harLog["comment"])
+		}
+	})
+
+	for _, limit := range []string{"0", "10001", "ten"} {
+		t.Run("limit "+limit+" returns 400", func(t *testing.T) {
+			resp := doRequest(t, http.MethodGet, "/_notspot/requests.har?limit="+limit, nil)
+			mustStatus(t, resp, http.StatusBadRequest)
+			assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+		})
+	}
+}
+
+func TestPurgeEndpoint(t *testing.T) {
+	resetServer(t)
+
+	archived := assertIsString(t, createContact(t, map[string]string{"email": "purge@example.com"}), "id")
+	active := assertIsString(t, createContact(t, map[string]string{"email": "stay@example.com"}), "id")
+
+	resp := doRequest(t, http.MethodDelete, "/crm/v3/objects/contacts/"+archived, nil)
+	mustStatus(t, resp, http.StatusNoContent)
+	_ = resp.Body.Close()
+
+	// The default retention keeps records archived moments ago.
+	resp = doRequest(t, http.MethodPost, "/_notspot/purge", nil)
+	mustStatus(t, resp, http.StatusOK)
+	if got := readJSON(t, resp)["purged"]; got != float64(0) {
+		t.Errorf("expected nothing purged with the default retention, got %v", got)
+	}
+
+	resp = doRequest(t, http.MethodPost, "/_notspot/purge?olderThan=0s", nil)
+	mustStatus(t, resp, http.StatusOK)
+	if got := readJSON(t, resp)["purged"]; got != float64(1) {
+		t.Errorf("expected 1 purged record, got %v", got)
+	}
+
+	resp = doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+archived, nil)
+	mustStatus(t, resp, http.StatusNotFound)
+	_ = resp.Body.Close()
+	resp = doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+active, nil)
+	mustStatus(t, resp, http.StatusOK)
+	_ = resp.Body.Close()
+
+	resp = doRequest(t, http.MethodPost, "/_notspot/purge?olderThan=soon", nil)
+	mustStatus(t, resp, http.StatusBadRequest)
+	assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+}
@@ -0,0 +1,130 @@
+package conformance_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+// assertBatchNotFound checks that a 207 batch body reports exactly one
+// OBJECT_NOT_FOUND error for the given IDs.
+func assertBatchNotFound(t *testing.T, body map[string]any, ids ...string) {
+	t.Helper()
+	assertStringField(t, body, "status", "COMPLETE")
+	if n, _ := body["numErrors"].(float64); int(n) != 1 {
+		t.Errorf("expected numErrors=1, got %v", body["numErrors"])
+	}
+	errs := assertIsArray(t, body, "errors")
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(errs))
+	}
+	e := toObject(t, errs[0])
+	assertStringField(t, e, "status", "error")
+	assertStringField(t, e, "category", "OBJECT_NOT_FOUND")
+	assertFieldPresent(t, e, "message")
+	got := assertIsArray(t, assertIsObject(t, e, "context"), "ids")
+	if len(got) != len(ids) {
+		t.Fatalf("expected context.ids %v, got %v", ids, got)
+	}
+	for i, id := range ids {
+		if got[i] != id {
+			t.Errorf("context.ids[%d]: expected %s, got %v", i, id, got[i])
+		}
+	}
+}
+
+func TestBatchPartialFailure(t *testing.T) {
+	resetServer(t)
+
+	contact := createContact(t, map[string]string{"email": "partial@example.com"})
+	contactID := assertIsString(t, contact, "id")
+	company := createCompany(t, map[string]string{"name": "Partial Co"})
+	companyID := assertIsString(t, company, "id")
+
+	t.Run("object batch read", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/batch/read", map[string]any{
+			"inputs": []map[string]string{{"id": contactID}, {"id": "999999"}},
+		})
+		mustStatus(t, resp, http.StatusMultiStatus)
+		body := readJSON(t, resp)
+		if results := assertIsArray(t, body, "results"); len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		assertBatchNotFound(t, body, "999999")
+	})
+
+	t.Run("object batch update", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/batch/update", map[string]any{
+			"inputs": []map[string]any{
+				{"id": contactID, "properties": map[string]string{"firstname": "Updated"}},
+				{"id": "999998", "properties": map[string]string{"firstname": "Ghost"}},
+				{"id": "999999", "properties": map[string]string{"firstname": "Ghost"}},
+			},
+		})
+		mustStatus(t, resp, http.StatusMultiStatus)
+		body := readJSON(t, resp)
+		results := assertIsArray(t, body, "results")
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		assertStringField(t, assertIsObject(t, toObject(t, results[0]), "properties"), "firstname", "Updated")
+		assertBatchNotFound(t, body, "999998", "999999")
+	})
+
+	t.Run("object batch update without errors", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/batch/update", map[string]any{
+			"inputs": []map[string]any{{"id": contactID, "properties": map[string]string{"lastname": "Ok"}}},
+		})
+		mustStatus(t, resp, http.StatusOK)
+		body := readJSON(t, resp)
+		if _, ok := body["errors"]; ok {
+			t.Error("expected no errors field on a fully successful batch")
+		}
+	})
+
+	t.Run("association batch create", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v4/associations/contacts/companies/batch/associate/default", map[string]any{
+			"inputs": []map[string]any{
+				{"from": map[string]string{"id": contactID}, "to": map[string]string{"id": companyID}},
+				{"from": map[string]string{"id": contactID}, "to": map[string]string{"id": "999999"}},
+			},
+		})
+		mustStatus(t, resp, http.StatusMultiStatus)
+		body := readJSON(t, resp)
+		if results := assertIsArray(t, body, "results"); len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		assertBatchNotFound(t, body, "999999")
+	})
+
+	t.Run("association batch read", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v4/associations/contacts/companies/batch/read", map[string]any{
+			"inputs": []map[string]string{{"id": contactID}, {"id": "999999"}},
+		})
+		mustStatus(t, resp, http.StatusMultiStatus)
+		assertBatchNotFound(t, readJSON(t, resp), "999999")
+	})
+
+	t.Run("property batch read", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/properties/contacts/batch/read", map[string]any{
+			"inputs": []map[string]string{{"name": "email"}, {"name": "no_such_property"}},
+		})
+		mustStatus(t, resp, http.StatusMultiStatus)
+		body := readJSON(t, resp)
+		if results := assertIsArray(t, body, "results"); len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		assertBatchNotFound(t, body, "no_such_property")
+	})
+
+	t.Run("object batch archive", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/batch/archive", map[string]any{
+			"inputs": []map[string]string{{"id": contactID}, {"id": "999999"}},
+		})
+		mustStatus(t, resp, http.StatusMultiStatus)
+		assertBatchNotFound(t, readJSON(t, resp), "999999")
+
+		resp = doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+contactID, nil)
+		mustStatus(t, resp, http.StatusOK)
+		assertBoolField(t, readJSON(t, resp), "archived", true)
+	})
+}
@@ -0,0 +1,158 @@
+package conformance_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+// createExpectation registers an expectation and returns the response body.
+func createExpectation(t *testing.T, exp map[string]any) map[string]any {
+	t.Helper()
+	resp := doRequest(t, http.MethodPost, "/_notspot/expectations", exp)
+	mustStatus(t, resp, http.StatusCreated)
+	return readJSON(t, resp)
+}
+
+// verifyExpectations calls the verify endpoint and returns the response body.
+func verifyExpectations(t *testing.T) map[string]any {
+	t.Helper()
+	resp := doRequest(t, http.MethodGet, "/_notspot/expectations/verify", nil)
+	mustStatus(t, resp, http.StatusOK)
+	return readJSON(t, resp)
+}
+
+func TestExpectationsMet(t *testing.T) {
+	resetServer(t)
+
+	createExpectation(t, map[string]any{
+		"method": "POST",
+		"path":   "/crm/v3/objects/contacts",
+		"body": []map[string]any{
+			{"path": "$.properties.email", "equals": "expect@example.com"},
+			{"path": "$.properties.firstname", "exists": true},
+		},
+		"times": map[string]any{"exactly": 1},
+	})
+	createExpectation(t, map[string]any{
+		"method": "GET",
+		"path":   "/crm/v3/objects/contacts/*",
+	})
+	createExpectation(t, map[string]any{
+		"method": "DELETE",
+		"times":  map[string]any{"never": true},
+	})
+
+	contact := createContact(t, map[string]string{"email": "expect@example.com", "firstname": "Ex"})
+	resp := doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+assertIsString(t, contact, "id"), nil)
+	mustStatus(t, resp, http.StatusOK)
+	_ = resp.Body.Close()
+
+	body := verifyExpectations(t)
+	assertBoolField(t, body, "met", true)
+	results := assertIsArray(t, body, "results")
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for _, r := range results {
+		res := toObject(t, r)
+		assertBoolField(t, res, "met", true)
+		if _, ok := res["diff"]; ok {
+			t.Errorf("expected no diff for a met expectation, got %v", res["diff"])
+		}
+	}
+}
+
+func TestExpectationsUnmetDiff(t *testing.T) {
+	resetServer(t)
+
+	createExpectation(t, map[string]any{
+		"method": "POST",
+		"path":   "/crm/v3/objects/contacts",
+		"body": []map[string]any{
+			{"path": "$.properties.email", "equals": "wanted@example.com"},
+		},
+	})
+	createContact(t, map[string]string{"email": "other@example.com"})
+
+	body := verifyExpectations(t)
+	assertBoolField(t, body, "met", false)
+	res := toObject(t, assertIsArray(t, body, "results")[0])
+	assertBoolField(t, res, "met", false)
+
+	diff := assertIsObject(t, res, "diff")
+	assertStringField(t, diff, "expected", "at least 1 matching request(s)")
+	assertStringField(t, diff, "actual", "0 matching request(s)")
+	misses := assertIsArray(t, diff, "nearMisses")
+	if len(misses) != 1 {
+		t.Fatalf("expected 1 near miss, got %d", len(misses))
+	}
+	mismatches := assertIsArray(t, toObject(t, misses[0]), "mismatches")
+	if len(mismatches) != 1 {
+		t.Fatalf("expected 1 mismatch, got %d", len(mismatches))
+	}
+	mm := toObject(t, mismatches[0])
+	assertStringField(t, mm, "field", "body $.properties.email")
+	assertStringField(t, mm, "expected", "wanted@example.com")
+	assertStringField(t, mm, "actual", "other@example.com")
+}
+
+func TestExpectationsTooMany(t *testing.T) {
+	resetServer(t)
+
+	createExpectation(t, map[string]any{
+		"method": "POST",
+		"path":   "/crm/v3/objects/contacts",
+		"times":  map[string]any{"exactly": 1},
+	})
+	createContact(t, map[string]string{"email": "one@example.com"})
+	createContact(t, map[string]string{"email": "two@example.com"})
+
+	res := toObject(t, assertIsArray(t, verifyExpectations(t), "results")[0])
+	assertBoolField(t, res, "met", false)
+	diff := assertIsObject(t, res, "diff")
+	if matched := assertIsArray(t, diff, "matched"); len(matched) != 2 {
+		t.Errorf("expected 2 matched requests in diff, got %d", len(matched))
+	}
+}
+
+func TestExpectationsValidation(t *testing.T) {
+	resetServer(t)
+
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{"bad json path", map[string]any{"body": []map[string]any{{"path": "properties.email"}}}},
+		{"conflicting times", map[string]any{"times": map[string]any{"exactly": 1, "never": true}}},
+		{"relative path", map[string]any{"path": "crm/v3/objects/contacts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodPost, "/_notspot/expectations", tt.body)
+			mustStatus(t, resp, http.StatusBadRequest)
+			assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+		})
+	}
+}
+
+func TestExpectationsClearedByReset(t *testing.T) {
+	resetServer(t)
+	createExpectation(t, map[string]any{"method": "GET"})
+
+	resp := doRequest(t, http.MethodGet, "/_notspot/expectations", nil)
+	mustStatus(t, resp, http.StatusOK)
+	if got := assertIsArray(t, readJSON(t, resp), "results"); len(got) != 1 {
+		t.Fatalf("expected 1 expectation, got %d", len(got))
+	}
+
+	resetServer(t)
+
+	resp = doRequest(t, http.MethodGet, "/_notspot/expectations", nil)
+	mustStatus(t, resp, http.StatusOK)
+	if got := assertIsArray(t, readJSON(t, resp), "results"); len(got) != 0 {
+		t.Errorf("expected reset to clear expectations, got %d", len(got))
+	}
+
+	body := verifyExpectations(t)
+	assertBoolField(t, body, "met", true)
+}
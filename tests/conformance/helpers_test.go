@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"testing"
 	"time"
@@ -268,3 +269,55 @@ func mapKeys(m map[string]any) []string {
 	}
 	return keys
 }
+
+// startImport posts a CSV import with one column mapping per header and
+// returns the decoded import response.
+func startImport(t *testing.T, name, objectTypeID, csvData string, propertyNames []string) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	filePart, err := writer.CreateFormFile("files", "import.csv")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	if _, err := filePart.Write([]byte(csvData)); err != nil {
+		t.Fatalf("write CSV data: %v", err)
+	}
+
+	mappings := make([]map[string]any, len(propertyNames))
+	for i, p := range propertyNames {
+		mappings[i] = map[string]any{"columnObjectTypeId": objectTypeID, "columnName": p, "propertyName": p}
+	}
+	importRequest, err := json.Marshal(map[string]any{
+		"name": name,
+		"files": []map[string]any{{
+			"fileName":       "import.csv",
+			"fileFormat":     "CSV",
+			"fileImportPage": map[string]any{"hasHeader": true, "columnMappings": mappings},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("marshal import request: %v", err)
+	}
+	if err := writer.WriteField("importRequest", string(importRequest)); err != nil {
+		t.Fatalf("write import request: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, serverURL+"/crm/v3/imports/", &buf)
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /crm/v3/imports/: %v", err)
+	}
+	mustStatus(t, resp, http.StatusOK)
+	return readJSON(t, resp)
+}
@@ -234,6 +234,94 @@ func TestBatchCreate(t *testing.T) {
 	}
 }
 
+func TestCreateWithAssociations(t *testing.T) {
+	resetServer(t)
+
+	contactID := assertIsString(t, createContact(t, map[string]string{"email": "engaged@example.com"}), "id")
+	companyID := assertIsString(t, createCompany(t, map[string]string{"name": "Engaged Corp"}), "id")
+
+	noteToContact := map[string]any{
+		"to":    map[string]string{"id": contactID},
+		"types": []map[string]any{{"associationCategory": "HUBSPOT_DEFINED", "associationTypeId": 202}},
+	}
+
+	t.Run("create", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/notes", map[string]any{
+			"properties":   map[string]string{"hs_note_body": "Single note"},
+			"associations": []any{noteToContact},
+		})
+		mustStatus(t, resp, http.StatusCreated)
+		noteID := assertIsString(t, readJSON(t, resp), "id")
+
+		resp = doRequest(t, http.MethodGet, "/crm/v4/objects/notes/"+noteID+"/associations/contacts", nil)
+		mustStatus(t, resp, http.StatusOK)
+		results := assertIsArray(t, readJSON(t, resp), "results")
+		if len(results) != 1 {
+			t.Fatalf("expected 1 association, got %d", len(results))
+		}
+		assertStringField(t, toObject(t, results[0]), "toObjectId", contactID)
+	})
+
+	t.Run("batch create", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/notes/batch/create", map[string]any{
+			"inputs": []map[string]any{
+				{"properties": map[string]string{"hs_note_body": "Batch note"}, "associations": []any{noteToContact}},
+				{"properties": map[string]string{"hs_note_body": "Company note"}, "associations": []any{map[string]any{
+					"to":    map[string]string{"id": companyID},
+					"types": []map[string]any{{"associationCategory": "HUBSPOT_DEFINED", "associationTypeId": 204}},
+				}}},
+			},
+		})
+		mustStatus(t, resp, http.StatusCreated)
+		results := assertIsArray(t, readJSON(t, resp), "results")
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(results))
+		}
+
+		resp = doRequest(t, http.MethodGet, "/crm/v4/objects/companies/"+companyID+"/associations/notes", nil)
+		mustStatus(t, resp, http.StatusOK)
+		if assocs := assertIsArray(t, readJSON(t, resp), "results"); len(assocs) != 1 {
+			t.Errorf("expected company to have 1 note, got %d", len(assocs))
+		}
+	})
+
+	t.Run("invalid target rejects the whole batch", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/crm/v3/objects/notes", nil)
+		mustStatus(t, resp, http.StatusOK)
+		before := len(assertIsArray(t, readJSON(t, resp), "results"))
+
+		resp = doRequest(t, http.MethodPost, "/crm/v3/objects/notes/batch/create", map[string]any{
+			"inputs": []map[string]any{
+				{"properties": map[string]string{"hs_note_body": "Valid"}, "associations": []any{noteToContact}},
+				{"properties": map[string]string{"hs_note_body": "Invalid"}, "associations": []any{map[string]any{
+					"to":    map[string]string{"id": "999999"},
+					"types": []map[string]any{{"associationCategory": "HUBSPOT_DEFINED", "associationTypeId": 202}},
+				}}},
+			},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+
+		resp = doRequest(t, http.MethodGet, "/crm/v3/objects/notes", nil)
+		mustStatus(t, resp, http.StatusOK)
+		if after := len(assertIsArray(t, readJSON(t, resp), "results")); after != before {
+			t.Errorf("expected %d notes after rejected batch, got %d", before, after)
+		}
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/notes", map[string]any{
+			"properties": map[string]string{"hs_note_body": "Wrong type"},
+			"associations": []any{map[string]any{
+				"to":    map[string]string{"id": contactID},
+				"types": []map[string]any{{"associationCategory": "HUBSPOT_DEFINED", "associationTypeId": 5}},
+			}},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+	})
+}
+
 func TestBatchRead(t *testing.T) {
 	resetServer(t)
 
@@ -268,6 +356,195 @@ func TestBatchRead(t *testing.T) {
 	}
 }
 
+func TestPropertiesWithHistory(t *testing.T) {
+	resetServer(t)
+
+	contact := createContact(t, map[string]string{"email": "pwh@example.com", "firstname": "One"})
+	id := assertIsString(t, contact, "id")
+
+	resp := doRequest(t, http.MethodPatch, "/crm/v3/objects/contacts/"+id, map[string]any{
+		"properties": map[string]string{"firstname": "Two"},
+	})
+	mustStatus(t, resp, http.StatusOK)
+	_ = resp.Body.Close()
+
+	assertHistory := func(t *testing.T, obj map[string]any) {
+		t.Helper()
+		pwh := assertIsObject(t, obj, "propertiesWithHistory")
+		versions := assertIsArray(t, pwh, "firstname")
+		if len(versions) != 2 {
+			t.Fatalf("expected 2 firstname versions, got %d", len(versions))
+		}
+		latest := toObject(t, versions[0])
+		assertStringField(t, latest, "value", "Two")
+		assertStringField(t, latest, "sourceType", "API")
+		assertISOTimestamp(t, assertIsString(t, latest, "timestamp"))
+		assertStringField(t, toObject(t, versions[1]), "value", "One")
+	}
+
+	t.Run("get", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+id+"?propertiesWithHistory=firstname", nil)
+		mustStatus(t, resp, http.StatusOK)
+		assertHistory(t, readJSON(t, resp))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/crm/v3/objects/contacts?propertiesWithHistory=firstname", nil)
+		mustStatus(t, resp, http.StatusOK)
+		results := assertIsArray(t, readJSON(t, resp), "results")
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		assertHistory(t, toObject(t, results[0]))
+	})
+
+	t.Run("batch read", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/batch/read", map[string]any{
+			"inputs":                []map[string]string{{"id": id}},
+			"propertiesWithHistory": []string{"firstname"},
+		})
+		mustStatus(t, resp, http.StatusOK)
+		results := assertIsArray(t, readJSON(t, resp), "results")
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		assertHistory(t, toObject(t, results[0]))
+	})
+
+	t.Run("omitted when not requested", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+id, nil)
+		mustStatus(t, resp, http.StatusOK)
+		if _, ok := readJSON(t, resp)["propertiesWithHistory"]; ok {
+			t.Error("expected no propertiesWithHistory when not requested")
+		}
+	})
+}
+
+func TestInlineAssociations(t *testing.T) {
+	resetServer(t)
+
+	contactID := assertIsString(t, createContact(t, map[string]string{"email": "inline@example.com"}), "id")
+	activeID := assertIsString(t, createCompany(t, map[string]string{"name": "Active Corp"}), "id")
+	archivedID := assertIsString(t, createCompany(t, map[string]string{"name": "Archived Corp"}), "id")
+
+	resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals", map[string]any{
+		"properties": map[string]string{"dealname": "Inline Deal"},
+	})
+	mustStatus(t, resp, http.StatusCreated)
+	dealID := assertIsString(t, readJSON(t, resp), "id")
+
+	for _, target := range []string{"contacts/" + contactID, "companies/" + activeID, "companies/" + archivedID} {
+		resp := doRequest(t, http.MethodPut, "/crm/v4/objects/deals/"+dealID+"/associations/default/"+target, nil)
+		mustStatus(t, resp, http.StatusOK)
+		_ = resp.Body.Close()
+	}
+
+	resp = doRequest(t, http.MethodDelete, "/crm/v3/objects/companies/"+archivedID, nil)
+	mustStatus(t, resp, http.StatusNoContent)
+	_ = resp.Body.Close()
+
+	assertAssociations := func(t *testing.T, obj map[string]any, wantCompanies int) {
+		t.Helper()
+		assocs := assertIsObject(t, obj, "associations")
+
+		contacts := assertIsArray(t, assertIsObject(t, assocs, "contacts"), "results")
+		if len(contacts) != 1 {
+			t.Fatalf("expected 1 associated contact, got %d", len(contacts))
+		}
+		first := toObject(t, contacts[0])
+		assertStringField(t, first, "id", contactID)
+		assertStringField(t, first, "type", "deal_to_contact")
+
+		companies := assertIsArray(t, assertIsObject(t, assocs, "companies"), "results")
+		if len(companies) != wantCompanies {
+			t.Fatalf("expected %d associated companies, got %d", wantCompanies, len(companies))
+		}
+	}
+
+	t.Run("get", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/crm/v3/objects/deals/"+dealID+"?associations=contacts,companies", nil)
+		mustStatus(t, resp, http.StatusOK)
+		assertAssociations(t, readJSON(t, resp), 1)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/crm/v3/objects/deals?associations=contacts,companies", nil)
+		mustStatus(t, resp, http.StatusOK)
+		results := assertIsArray(t, readJSON(t, resp), "results")
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		assertAssociations(t, toObject(t, results[0]), 1)
+	})
+
+	t.Run("batch read", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals/batch/read", map[string]any{
+			"inputs":       []map[string]string{{"id": dealID}},
+			"associations": []string{"contacts", "companies"},
+		})
+		mustStatus(t, resp, http.StatusOK)
+		results := assertIsArray(t, readJSON(t, resp), "results")
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		assertAssociations(t, toObject(t, results[0]), 1)
+	})
+
+	t.Run("omitted when not requested", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/crm/v3/objects/deals/"+dealID, nil)
+		mustStatus(t, resp, http.StatusOK)
+		if _, ok := readJSON(t, resp)["associations"]; ok {
+			t.Error("expected no associations when not requested")
+		}
+	})
+}
+
+func TestInlineAssociationsPaging(t *testing.T) {
+	resetServer(t)
+
+	resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals", map[string]any{
+		"properties": map[string]string{"dealname": "Many Companies"},
+	})
+	mustStatus(t, resp, http.StatusCreated)
+	dealID := assertIsString(t, readJSON(t, resp), "id")
+
+	// One more company than an object read returns inline.
+	const companies = 501
+	for start := 0; start < companies; start += 100 {
+		var creates []map[string]any
+		for i := start; i < min(start+100, companies); i++ {
+			creates = append(creates, map[string]any{"properties": map[string]string{"name": fmt.Sprintf("Company %03d", i)}})
+		}
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/companies/batch/create", map[string]any{"inputs": creates})
+		mustStatus(t, resp, http.StatusCreated)
+		var links []map[string]any
+		for _, r := range assertIsArray(t, readJSON(t, resp), "results") {
+			links = append(links, map[string]any{
+				"from": map[string]string{"id": dealID},
+				"to":   map[string]string{"id": assertIsString(t, toObject(t, r), "id")},
+			})
+		}
+		resp = doRequest(t, http.MethodPost, "/crm/v4/associations/deals/companies/batch/associate/default", map[string]any{"inputs": links})
+		mustStatus(t, resp, http.StatusOK)
+		_ = resp.Body.Close()
+	}
+
+	resp = doRequest(t, http.MethodGet, "/crm/v3/objects/deals/"+dealID+"?associations=0-2", nil)
+	mustStatus(t, resp, http.StatusOK)
+	block := assertIsObject(t, assertIsObject(t, readJSON(t, resp), "associations"), "companies")
+	if results := assertIsArray(t, block, "results"); len(results) != 500 {
+		t.Fatalf("expected 500 inline companies, got %d", len(results))
+	}
+	next := assertIsObject(t, assertIsObject(t, block, "paging"), "next")
+	assertStringField(t, next, "after", "500")
+
+	resp = doRequest(t, http.MethodGet, assertIsString(t, next, "link"), nil)
+	mustStatus(t, resp, http.StatusOK)
+	if rest := assertIsArray(t, readJSON(t, resp), "results"); len(rest) != 1 {
+		t.Errorf("expected 1 company after the inline page, got %d", len(rest))
+	}
+}
+
 func TestBatchUpdate(t *testing.T) {
 	resetServer(t)
 
@@ -345,7 +622,10 @@ func TestBatchUpsert(t *testing.T) {
 		if assertIsString(t, obj, "id") == existingID {
 			props := assertIsObject(t, obj, "properties")
 			assertStringField(t, props, "firstname", "Updated")
+			assertBoolField(t, obj, "new", false)
 			found = true
+		} else {
+			assertBoolField(t, obj, "new", true)
 		}
 	}
 	if !found {
@@ -353,6 +633,82 @@ func TestBatchUpsert(t *testing.T) {
 	}
 }
 
+func TestBatchUpsertCustomIDProperty(t *testing.T) {
+	resetServer(t)
+
+	resp := doRequest(t, http.MethodPost, "/crm/v3/properties/companies", map[string]any{
+		"name": "erp_id", "label": "ERP ID", "type": "string", "fieldType": "text",
+		"groupName": "companyinformation", "hasUniqueValue": true,
+	})
+	mustStatus(t, resp, http.StatusCreated)
+	_ = resp.Body.Close()
+
+	upsert := func(t *testing.T, body map[string]any) []any {
+		t.Helper()
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/companies/batch/upsert", body)
+		mustStatus(t, resp, http.StatusOK)
+		return assertIsArray(t, readJSON(t, resp), "results")
+	}
+
+	// The request-level idProperty applies to every input, and the id is
+	// stored on created records.
+	results := upsert(t, map[string]any{
+		"idProperty": "erp_id",
+		"inputs": []map[string]any{
+			{"id": "ERP-1", "properties": map[string]string{"name": "First"}},
+		},
+	})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	first := toObject(t, results[0])
+	firstID := assertIsString(t, first, "id")
+	assertBoolField(t, first, "new", true)
+	assertStringField(t, assertIsObject(t, first, "properties"), "erp_id", "ERP-1")
+
+	results = upsert(t, map[string]any{
+		"inputs": []map[string]any{
+			{"id": "ERP-1", "idProperty": "erp_id", "properties": map[string]string{"name": "Renamed"}},
+		},
+	})
+	second := toObject(t, results[0])
+	assertStringField(t, second, "id", firstID)
+	assertBoolField(t, second, "new", false)
+	assertStringField(t, assertIsObject(t, second, "properties"), "name", "Renamed")
+
+	t.Run("custom object unique property", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/schemas", map[string]any{
+			"name":                   "parts",
+			"labels":                 map[string]any{"singular": "Part", "plural": "Parts"},
+			"primaryDisplayProperty": "sku",
+			"properties": []map[string]any{
+				{"name": "sku", "label": "SKU", "type": "string", "fieldType": "text", "hasUniqueValue": true},
+			},
+		})
+		mustStatus(t, resp, http.StatusCreated)
+		_ = resp.Body.Close()
+
+		for _, wantNew := range []bool{true, false} {
+			resp := doRequest(t, http.MethodPost, "/crm/v3/objects/parts/batch/upsert", map[string]any{
+				"inputs": []map[string]any{{"id": "SKU-9", "idProperty": "sku", "properties": map[string]string{}}},
+			})
+			mustStatus(t, resp, http.StatusOK)
+			results := assertIsArray(t, readJSON(t, resp), "results")
+			assertBoolField(t, toObject(t, results[0]), "new", wantNew)
+		}
+	})
+
+	for _, prop := range []string{"name", "no_such_property"} {
+		t.Run("rejects "+prop, func(t *testing.T) {
+			resp := doRequest(t, http.MethodPost, "/crm/v3/objects/companies/batch/upsert", map[string]any{
+				"inputs": []map[string]any{{"id": "x", "idProperty": prop, "properties": map[string]string{}}},
+			})
+			mustStatus(t, resp, http.StatusBadRequest)
+			assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+		})
+	}
+}
+
 func TestBatchArchive(t *testing.T) {
 	resetServer(t)
 
@@ -407,11 +763,78 @@ func TestMergeObjects(t *testing.T) {
 	assertSimplePublicObject(t, result)
 	assertStringField(t, result, "id", primaryID)
 
-	// The merged object should be archived.
+	props := assertIsObject(t, result, "properties")
+	assertStringField(t, props, "hs_merged_object_ids", mergeID)
+	assertStringField(t, props, "hs_canonical_object_id", primaryID)
+
+	// The merged ID now resolves to the primary record.
 	resp2 := doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+mergeID, nil)
 	mustStatus(t, resp2, http.StatusOK)
 	mergedObj := readJSON(t, resp2)
-	assertBoolField(t, mergedObj, "archived", true)
+	assertStringField(t, mergedObj, "id", primaryID)
+	assertBoolField(t, mergedObj, "archived", false)
+
+	resp3 := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/batch/read", map[string]any{
+		"inputs": []map[string]string{{"id": mergeID}},
+	})
+	mustStatus(t, resp3, http.StatusOK)
+	results := assertIsArray(t, readJSON(t, resp3), "results")
+	if len(results) != 1 {
+		t.Fatalf("expected 1 batch read result, got %d", len(results))
+	}
+	assertStringField(t, toObject(t, results[0]), "id", primaryID)
+}
+
+func TestMergeMovesAssociationsAndMemberships(t *testing.T) {
+	resetServer(t)
+
+	primaryID := assertIsString(t, createContact(t, map[string]string{"email": "keep@example.com"}), "id")
+	mergeID := assertIsString(t, createContact(t, map[string]string{"email": "gone@example.com"}), "id")
+	companyID := assertIsString(t, createCompany(t, map[string]string{"name": "Moved Corp"}), "id")
+
+	resp := doRequest(t, http.MethodPut,
+		"/crm/v4/objects/contacts/"+mergeID+"/associations/default/companies/"+companyID, nil)
+	mustStatus(t, resp, http.StatusOK)
+	_ = resp.Body.Close()
+
+	listID := assertIsString(t, createList(t, "Merge members"), "listId")
+	resp = doRequest(t, http.MethodPut, "/crm/v3/lists/"+listID+"/memberships/add", []string{mergeID})
+	mustStatus(t, resp, http.StatusOK)
+	_ = resp.Body.Close()
+
+	resp = doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/merge", map[string]string{
+		"primaryObjectId": primaryID,
+		"objectIdToMerge": mergeID,
+	})
+	mustStatus(t, resp, http.StatusOK)
+	_ = resp.Body.Close()
+
+	resp = doRequest(t, http.MethodGet, "/crm/v4/objects/contacts/"+primaryID+"/associations/companies", nil)
+	mustStatus(t, resp, http.StatusOK)
+	assocs := assertIsArray(t, readJSON(t, resp), "results")
+	if len(assocs) != 1 {
+		t.Fatalf("expected the company association to move to the primary, got %d", len(assocs))
+	}
+
+	resp = doRequest(t, http.MethodGet, "/crm/v3/lists/"+listID+"/memberships", nil)
+	mustStatus(t, resp, http.StatusOK)
+	members := assertIsArray(t, readJSON(t, resp), "results")
+	if len(members) != 1 {
+		t.Fatalf("expected 1 list member, got %d", len(members))
+	}
+	assertStringField(t, toObject(t, members[0]), "recordId", primaryID)
+}
+
+func TestMergeWithItselfRejected(t *testing.T) {
+	resetServer(t)
+
+	id := assertIsString(t, createContact(t, map[string]string{"email": "self@example.com"}), "id")
+	resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/merge", map[string]string{
+		"primaryObjectId": id,
+		"objectIdToMerge": id,
+	})
+	mustStatus(t, resp, http.StatusBadRequest)
+	assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
 }
 
 func TestCreateObjectAllTypes(t *testing.T) {
@@ -501,3 +924,59 @@ func TestBatchLimitExceeded(t *testing.T) {
 	result := readJSON(t, resp)
 	assertHubSpotError(t, result, "VALIDATION_ERROR")
 }
+
+func TestGDPRDelete(t *testing.T) {
+	resetServer(t)
+
+	byID := assertIsString(t, createContact(t, map[string]string{"email": "byid@example.com"}), "id")
+	byEmail := assertIsString(t, createContact(t, map[string]string{"email": "byemail@example.com"}), "id")
+
+	resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/gdpr-delete", map[string]string{"objectId": byID})
+	mustStatus(t, resp, http.StatusNoContent)
+	_ = resp.Body.Close()
+
+	resp = doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/gdpr-delete", map[string]string{
+		"objectId":   "byemail@example.com",
+		"idProperty": "email",
+	})
+	mustStatus(t, resp, http.StatusNoContent)
+	_ = resp.Body.Close()
+
+	for _, id := range []string{byID, byEmail} {
+		resp = doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+id+"?archived=true", nil)
+		mustStatus(t, resp, http.StatusNotFound)
+		_ = resp.Body.Close()
+	}
+
+	t.Run("unknown contact", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/gdpr-delete", map[string]string{"objectId": byID})
+		mustStatus(t, resp, http.StatusNotFound)
+		assertHubSpotError(t, readJSON(t, resp), "OBJECT_NOT_FOUND")
+	})
+
+	t.Run("blocks re-creation without legal basis", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts", map[string]any{
+			"properties": map[string]string{"email": "byemail@example.com"},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+
+		resp = doRequest(t, http.MethodPost, "/crm/v3/objects/contacts", map[string]any{
+			"properties": map[string]string{
+				"email":          "byemail@example.com",
+				"hs_legal_basis": "Freely given consent from contact",
+			},
+		})
+		mustStatus(t, resp, http.StatusCreated)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("rejects other id properties", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/gdpr-delete", map[string]string{
+			"objectId":   "x",
+			"idProperty": "firstname",
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+	})
+}
@@ -3,6 +3,7 @@ package conformance_test
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -430,3 +431,106 @@ func getDefaultDealsPipelineID(t *testing.T) string {
 	t.Fatal("seeded Sales Pipeline not found")
 	return ""
 }
+
+// TestDealStageValidation verifies that deal writes are checked against the
+// deal pipelines and default to the first pipeline and stage.
+func TestDealStageValidation(t *testing.T) {
+	resetServer(t)
+	pipelineID := getDefaultDealsPipelineID(t)
+
+	resp := doRequest(t, http.MethodGet, "/crm/v3/pipelines/deals/"+pipelineID+"/stages", nil)
+	mustStatus(t, resp, http.StatusOK)
+	stages := assertIsArray(t, readJSON(t, resp), "results")
+	firstStage := assertIsString(t, toObject(t, stages[0]), "id")
+
+	t.Run("applies the default pipeline and stage", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals", map[string]any{
+			"properties": map[string]string{"dealname": "Defaulted"},
+		})
+		mustStatus(t, resp, http.StatusCreated)
+		props := assertIsObject(t, readJSON(t, resp), "properties")
+		assertStringField(t, props, "pipeline", pipelineID)
+		assertStringField(t, props, "dealstage", firstStage)
+	})
+
+	t.Run("rejects an unknown stage", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals", map[string]any{
+			"properties": map[string]string{"dealname": "Bad", "pipeline": pipelineID, "dealstage": "nope"},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		body := readJSON(t, resp)
+		assertHubSpotError(t, body, "VALIDATION_ERROR")
+		if msg := assertIsString(t, body, "message"); !strings.Contains(msg, "nope was not one of the allowed options: [") {
+			t.Errorf("unexpected message %q", msg)
+		}
+		errs := assertIsArray(t, body, "errors")
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 error, got %d", len(errs))
+		}
+		assertStringField(t, toObject(t, errs[0]), "code", "INVALID_OPTION")
+	})
+
+	t.Run("rejects an unknown pipeline on update", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals", map[string]any{
+			"properties": map[string]string{"dealname": "Moving"},
+		})
+		mustStatus(t, resp, http.StatusCreated)
+		id := assertIsString(t, readJSON(t, resp), "id")
+
+		resp = doRequest(t, http.MethodPatch, "/crm/v3/objects/deals/"+id, map[string]any{
+			"properties": map[string]string{"pipeline": "nope"},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		errs := assertIsArray(t, readJSON(t, resp), "errors")
+		assertStringField(t, toObject(t, errs[0]), "code", "INVALID_OPTION")
+	})
+}
+
+// TestDealStageDerivedProperties verifies that moving a deal between stages
+// sets the properties HubSpot derives from the stage metadata.
+func TestDealStageDerivedProperties(t *testing.T) {
+	resetServer(t)
+	pipelineID := getDefaultDealsPipelineID(t)
+
+	resp := doRequest(t, http.MethodGet, "/crm/v3/pipelines/deals/"+pipelineID+"/stages", nil)
+	mustStatus(t, resp, http.StatusOK)
+	var closedWon string
+	for _, s := range assertIsArray(t, readJSON(t, resp), "results") {
+		stage := toObject(t, s)
+		if assertIsString(t, stage, "label") == "Closed Won" {
+			closedWon = assertIsString(t, stage, "id")
+		}
+	}
+
+	resp = doRequest(t, http.MethodPost, "/crm/v3/objects/deals", map[string]any{
+		"properties": map[string]string{"dealname": "Forecast", "amount": "500"},
+	})
+	mustStatus(t, resp, http.StatusCreated)
+	id := assertIsString(t, readJSON(t, resp), "id")
+
+	resp = doRequest(t, http.MethodPatch, "/crm/v3/objects/deals/"+id, map[string]any{
+		"properties": map[string]string{"dealstage": closedWon},
+	})
+	mustStatus(t, resp, http.StatusOK)
+	_ = resp.Body.Close()
+
+	resp = doRequest(t, http.MethodGet, "/crm/v3/objects/deals/"+id+
+		"?properties=hs_is_closed,hs_is_closed_won,hs_deal_stage_probability,hs_forecast_amount,closedate,hs_date_entered_"+closedWon, nil)
+	mustStatus(t, resp, http.StatusOK)
+	props := assertIsObject(t, readJSON(t, resp), "properties")
+	assertStringField(t, props, "hs_is_closed", "true")
+	assertStringField(t, props, "hs_is_closed_won", "true")
+	assertStringField(t, props, "hs_deal_stage_probability", "1.0")
+	assertStringField(t, props, "hs_forecast_amount", "500")
+	entered := assertIsString(t, props, "hs_date_entered_"+closedWon)
+	assertStringField(t, props, "closedate", entered[:10])
+
+	t.Run("derived properties are read-only", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPatch, "/crm/v3/objects/deals/"+id, map[string]any{
+			"properties": map[string]string{"hs_is_closed_won": "false"},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		errs := assertIsArray(t, readJSON(t, resp), "errors")
+		assertStringField(t, toObject(t, errs[0]), "code", "READ_ONLY_VALUE")
+	})
+}
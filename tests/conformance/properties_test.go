@@ -363,3 +363,81 @@ func TestArchivePropertyGroup(t *testing.T) {
 	archivedGroup := readJSON(t, getResp)
 	assertBoolField(t, archivedGroup, "archived", true)
 }
+
+// TestCalculatedProperty verifies that a property with a calculation formula
+// is computed on read and cannot be written.
+func TestCalculatedProperty(t *testing.T) {
+	resetServer(t)
+
+	resp := doRequest(t, http.MethodPost, "/crm/v3/properties/deals", map[string]any{
+		"name":               "amount_with_tax",
+		"label":              "Amount with tax",
+		"type":               "number",
+		"fieldType":          "calculation_equation",
+		"groupName":          "dealinformation",
+		"calculationFormula": "round(amount * 1.2, 2)",
+	})
+	mustStatus(t, resp, http.StatusCreated)
+	prop := readJSON(t, resp)
+	assertBoolField(t, prop, "calculated", true)
+	assertStringField(t, prop, "calculationFormula", "round(amount * 1.2, 2)")
+
+	resp = doRequest(t, http.MethodPost, "/crm/v3/objects/deals", map[string]any{
+		"properties": map[string]string{"dealname": "Taxed", "amount": "100"},
+	})
+	mustStatus(t, resp, http.StatusCreated)
+	id := readJSON(t, resp)["id"].(string)
+
+	t.Run("get", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/crm/v3/objects/deals/"+id+"?properties=amount_with_tax", nil)
+		mustStatus(t, resp, http.StatusOK)
+		props := assertIsObject(t, readJSON(t, resp), "properties")
+		assertStringField(t, props, "amount_with_tax", "120")
+	})
+
+	t.Run("list", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/crm/v3/objects/deals?properties=amount_with_tax", nil)
+		mustStatus(t, resp, http.StatusOK)
+		results := assertIsArray(t, readJSON(t, resp), "results")
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		assertStringField(t, assertIsObject(t, toObject(t, results[0]), "properties"), "amount_with_tax", "120")
+	})
+
+	t.Run("search", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals/search", map[string]any{
+			"properties": []string{"dealname", "amount_with_tax"},
+		})
+		mustStatus(t, resp, http.StatusOK)
+		results := assertIsArray(t, readJSON(t, resp), "results")
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(results))
+		}
+		assertStringField(t, assertIsObject(t, toObject(t, results[0]), "properties"), "amount_with_tax", "120")
+	})
+
+	t.Run("rejects writes", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPatch, "/crm/v3/objects/deals/"+id, map[string]any{
+			"properties": map[string]string{"amount_with_tax": "5"},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		body := readJSON(t, resp)
+		assertHubSpotError(t, body, "VALIDATION_ERROR")
+		errs := assertIsArray(t, body, "errors")
+		assertStringField(t, toObject(t, errs[0]), "code", "READ_ONLY_VALUE")
+	})
+
+	t.Run("rejects invalid formulas", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/properties/deals", map[string]any{
+			"name":               "broken",
+			"label":              "Broken",
+			"type":               "number",
+			"fieldType":          "calculation_equation",
+			"groupName":          "dealinformation",
+			"calculationFormula": "amount *",
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+	})
+}
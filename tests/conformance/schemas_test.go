@@ -226,6 +226,72 @@ func TestUpdateSchema(t *testing.T) {
 	})
 }
 
+func TestSchemaSearchableProperties(t *testing.T) {
+	resetServer(t)
+
+	input := map[string]any{
+		"name":                   "cars",
+		"labels":                 map[string]any{"singular": "Car", "plural": "Cars"},
+		"primaryDisplayProperty": "model",
+		"searchableProperties":   []string{"model"},
+		"properties": []map[string]any{
+			{"name": "model", "label": "Model", "type": "string", "fieldType": "text"},
+			{"name": "color", "label": "Color", "type": "string", "fieldType": "text"},
+		},
+	}
+	resp := doRequest(t, http.MethodPost, "/crm/v3/schemas", input)
+	mustStatus(t, resp, http.StatusCreated)
+	body := readJSON(t, resp)
+	if got := assertIsArray(t, body, "searchableProperties"); len(got) != 1 || got[0] != "model" {
+		t.Errorf("searchableProperties = %v, want [model]", got)
+	}
+
+	resp = doRequest(t, http.MethodPost, "/crm/v3/objects/cars", map[string]any{
+		"properties": map[string]string{"model": "Roadster", "color": "Red"},
+	})
+	mustStatus(t, resp, http.StatusCreated)
+	_ = resp.Body.Close()
+
+	searchTotal := func(t *testing.T, query string) float64 {
+		t.Helper()
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/cars/search", map[string]any{"query": query})
+		mustStatus(t, resp, http.StatusOK)
+		total, _ := readJSON(t, resp)["total"].(float64)
+		return total
+	}
+
+	t.Run("query matches searchable properties only", func(t *testing.T) {
+		if got := searchTotal(t, "roadster"); got != 1 {
+			t.Errorf("total for roadster = %v, want 1", got)
+		}
+		if got := searchTotal(t, "red"); got != 0 {
+			t.Errorf("total for red = %v, want 0", got)
+		}
+	})
+
+	t.Run("patch replaces searchable properties", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPatch, "/crm/v3/schemas/cars", map[string]any{
+			"searchableProperties": []string{"model", "color"},
+		})
+		mustStatus(t, resp, http.StatusOK)
+		body := readJSON(t, resp)
+		if got := assertIsArray(t, body, "searchableProperties"); len(got) != 2 {
+			t.Errorf("searchableProperties = %v, want [model color]", got)
+		}
+		if got := searchTotal(t, "red"); got != 1 {
+			t.Errorf("total for red = %v, want 1", got)
+		}
+	})
+
+	t.Run("unknown property returns 400", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPatch, "/crm/v3/schemas/cars", map[string]any{
+			"searchableProperties": []string{"wheels"},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+	})
+}
+
 func TestArchiveSchema(t *testing.T) {
 	resetServer(t)
 
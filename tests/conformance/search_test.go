@@ -90,7 +90,7 @@ func TestSearchNEQ(t *testing.T) {
 func TestSearchLT(t *testing.T) {
 	resetServer(t)
 
-	// Use alphabetically-ordered string values (SQLite compares as strings).
+	// String properties compare alphabetically.
 	createContact(t, map[string]string{"firstname": "B", "lastname": "Beta"})
 	c2 := createContact(t, map[string]string{"firstname": "A", "lastname": "Alpha"})
 	createContact(t, map[string]string{"firstname": "C", "lastname": "Charlie"})
@@ -169,6 +169,8 @@ func TestSearchBETWEEN(t *testing.T) {
 	createContact(t, map[string]string{"firstname": "B", "annualrevenue": "50"})
 	c3 := createContact(t, map[string]string{"firstname": "C", "annualrevenue": "200"})
 	createContact(t, map[string]string{"firstname": "D", "annualrevenue": "300"})
+	// Compared as text, "1000" would fall between "100" and "200".
+	createContact(t, map[string]string{"firstname": "E", "annualrevenue": "1000"})
 
 	body := map[string]any{
 		"filterGroups": []any{
@@ -302,7 +304,7 @@ func TestSearchCONTAINS_TOKEN(t *testing.T) {
 	createContact(t, map[string]string{"firstname": "Bob"})
 	c3 := createContact(t, map[string]string{"firstname": "Alexandra"})
 
-	ids := searchContactIDs(t, filterBody("firstname", "CONTAINS_TOKEN", "Alex"))
+	ids := searchContactIDs(t, filterBody("firstname", "CONTAINS_TOKEN", "Alex*"))
 
 	if len(ids) != 2 {
 		t.Fatalf("expected 2 results, got %d", len(ids))
@@ -322,7 +324,7 @@ func TestSearchNOT_CONTAINS_TOKEN(t *testing.T) {
 	c2 := createContact(t, map[string]string{"firstname": "Bob"})
 	createContact(t, map[string]string{"firstname": "Alexandra"})
 
-	ids := searchContactIDs(t, filterBody("firstname", "NOT_CONTAINS_TOKEN", "Alex"))
+	ids := searchContactIDs(t, filterBody("firstname", "NOT_CONTAINS_TOKEN", "Alex*"))
 
 	if !containsID(ids, assertIsString(t, c2, "id")) {
 		t.Errorf("expected Bob (id=%s) in results", assertIsString(t, c2, "id"))
@@ -665,3 +667,198 @@ func TestSearchQueryFullText(t *testing.T) {
 		t.Errorf("expected Charlie (id=%s) in results", assertIsString(t, c3, "id"))
 	}
 }
+
+// TestSearchRollupProperty verifies that association-count rollups are kept
+// up to date and can be filtered on.
+func TestSearchRollupProperty(t *testing.T) {
+	resetServer(t)
+
+	withDeal := assertIsString(t, createCompany(t, map[string]string{"name": "With deal"}), "id")
+	createCompany(t, map[string]string{"name": "Without deal"})
+
+	resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals", map[string]any{
+		"properties": map[string]string{"dealname": "Rollup deal", "amount": "10"},
+	})
+	mustStatus(t, resp, http.StatusCreated)
+	dealID := assertIsString(t, readJSON(t, resp), "id")
+
+	resp = doRequest(t, http.MethodPut,
+		fmt.Sprintf("/crm/v4/objects/deals/%s/associations/default/companies/%s", dealID, withDeal), nil)
+	mustStatus(t, resp, http.StatusOK)
+	readJSON(t, resp)
+
+	search := func() []any {
+		t.Helper()
+		body := filterBody("num_associated_deals", "GT", "0")
+		body["properties"] = []string{"num_associated_deals"}
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/companies/search", body)
+		mustStatus(t, resp, http.StatusOK)
+		return assertIsArray(t, readJSON(t, resp), "results")
+	}
+
+	results := search()
+	if len(results) != 1 {
+		t.Fatalf("expected 1 company with deals, got %d", len(results))
+	}
+	company := toObject(t, results[0])
+	assertStringField(t, company, "id", withDeal)
+	assertStringField(t, assertIsObject(t, company, "properties"), "num_associated_deals", "1")
+
+	// Archiving the deal drops the count.
+	resp = doRequest(t, http.MethodDelete, "/crm/v3/objects/deals/"+dealID, nil)
+	mustStatus(t, resp, http.StatusNoContent)
+	_ = resp.Body.Close()
+	if results := search(); len(results) != 0 {
+		t.Errorf("expected no companies with deals after archiving, got %d", len(results))
+	}
+
+	// Rollups are read-only.
+	resp = doRequest(t, http.MethodPatch, "/crm/v3/objects/companies/"+withDeal, map[string]any{
+		"properties": map[string]string{"num_associated_deals": "5"},
+	})
+	mustStatus(t, resp, http.StatusBadRequest)
+	errs := assertIsArray(t, readJSON(t, resp), "errors")
+	assertStringField(t, toObject(t, errs[0]), "code", "READ_ONLY_VALUE")
+}
+
+func TestSearchTypedRanges(t *testing.T) {
+	resetServer(t)
+
+	names := map[string]string{}
+	for _, d := range []map[string]string{
+		{"dealname": "Small", "amount": "95", "closedate": "2024-01-15"},
+		{"dealname": "Large", "amount": "1000", "closedate": "2024-03-10"},
+	} {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals", map[string]any{"properties": d})
+		mustStatus(t, resp, http.StatusCreated)
+		names[assertIsString(t, readJSON(t, resp), "id")] = d["dealname"]
+	}
+
+	searchDeals := func(t *testing.T, body map[string]any) []string {
+		t.Helper()
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals/search", body)
+		mustStatus(t, resp, http.StatusOK)
+		var got []string
+		for _, r := range assertIsArray(t, readJSON(t, resp), "results") {
+			got = append(got, names[assertIsString(t, toObject(t, r), "id")])
+		}
+		return got
+	}
+
+	// As text, "95" > "900" and "1000" < "900".
+	if got := searchDeals(t, filterBody("amount", "GT", "900")); fmt.Sprint(got) != "[Large]" {
+		t.Errorf("amount GT 900: got %v, want [Large]", got)
+	}
+	// 2024-02-01T00:00:00Z as epoch milliseconds.
+	if got := searchDeals(t, filterBody("closedate", "LT", "1706745600000")); fmt.Sprint(got) != "[Small]" {
+		t.Errorf("closedate LT epoch ms: got %v, want [Small]", got)
+	}
+
+	body := map[string]any{"sorts": []any{map[string]any{"propertyName": "amount", "direction": "DESCENDING"}}}
+	if got := searchDeals(t, body); fmt.Sprint(got) != "[Large Small]" {
+		t.Errorf("sort by amount descending: got %v, want [Large Small]", got)
+	}
+
+	resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals/search", filterBody("closedate", "GT", "yesterday"))
+	mustStatus(t, resp, http.StatusBadRequest)
+	assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+}
+
+func TestSearchMultiKeySortPaging(t *testing.T) {
+	resetServer(t)
+
+	for _, c := range []map[string]string{
+		{"firstname": "Bea", "lastname": "Smith"},
+		{"firstname": "Al", "lastname": "Jones"},
+		{"firstname": "Cy", "lastname": "Smith"},
+		{"firstname": "Di"},
+		{"firstname": "Ed", "lastname": "Jones"},
+	} {
+		createContact(t, c)
+	}
+
+	body := map[string]any{
+		"sorts": []any{
+			map[string]any{"propertyName": "lastname", "direction": "DESCENDING"},
+			map[string]any{"propertyName": "firstname", "direction": "ASCENDING"},
+		},
+		"properties": []string{"firstname"},
+		"limit":      2,
+	}
+	var got []string
+	for {
+		result := searchContacts(t, body)
+		for _, r := range assertIsArray(t, result, "results") {
+			got = append(got, assertIsString(t, assertIsObject(t, toObject(t, r), "properties"), "firstname"))
+		}
+		paging, ok := result["paging"].(map[string]any)
+		if !ok {
+			break
+		}
+		body["after"] = assertIsString(t, assertIsObject(t, paging, "next"), "after")
+	}
+	// Contacts without a lastname sort last.
+	if want := "[Bea Cy Al Ed Di]"; fmt.Sprint(got) != want {
+		t.Errorf("got %v across pages, want %s", got, want)
+	}
+
+	resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/search", map[string]any{
+		"sorts": []any{map[string]any{"propertyName": "no_such_property", "direction": "ASCENDING"}},
+	})
+	mustStatus(t, resp, http.StatusBadRequest)
+	assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+}
+
+func TestSearchAssociationFilter(t *testing.T) {
+	resetServer(t)
+
+	acme := assertIsString(t, createCompany(t, map[string]string{"name": "Acme"}), "id")
+	names := map[string]string{}
+	for _, name := range []string{"Acme deal", "Unrelated deal"} {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals", map[string]any{
+			"properties": map[string]string{"dealname": name},
+		})
+		mustStatus(t, resp, http.StatusCreated)
+		names[assertIsString(t, readJSON(t, resp), "id")] = name
+	}
+	for id, name := range names {
+		if name != "Acme deal" {
+			continue
+		}
+		resp := doRequest(t, http.MethodPut,
+			fmt.Sprintf("/crm/v4/objects/deals/%s/associations/default/companies/%s", id, acme), nil)
+		mustStatus(t, resp, http.StatusOK)
+		readJSON(t, resp)
+	}
+
+	resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals/search", filterBody("associations.company", "EQ", acme))
+	mustStatus(t, resp, http.StatusOK)
+	results := assertIsArray(t, readJSON(t, resp), "results")
+	if len(results) != 1 {
+		t.Fatalf("expected 1 deal for the company, got %d", len(results))
+	}
+	if got := names[assertIsString(t, toObject(t, results[0]), "id")]; got != "Acme deal" {
+		t.Errorf("got %q, want Acme deal", got)
+	}
+
+	resp = doRequest(t, http.MethodPost, "/crm/v3/objects/deals/search", filterBody("associations.spaceship", "EQ", acme))
+	mustStatus(t, resp, http.StatusBadRequest)
+	assertHubSpotError(t, readJSON(t, resp), "VALIDATION_ERROR")
+}
+
+func TestSearchCONTAINS_TOKENEmailDomain(t *testing.T) {
+	resetServer(t)
+
+	c1 := createContact(t, map[string]string{"email": "ada@hubspot.com"})
+	createContact(t, map[string]string{"email": "grace@hubspotter.dev"})
+
+	ids := searchContactIDs(t, filterBody("email", "CONTAINS_TOKEN", "*@hubspot.com"))
+	if len(ids) != 1 || ids[0] != assertIsString(t, c1, "id") {
+		t.Errorf("expected only %s, got %v", assertIsString(t, c1, "id"), ids)
+	}
+
+	ids = searchContactIDs(t, map[string]any{"query": "HUBSPOT.COM"})
+	if len(ids) != 1 {
+		t.Errorf("query is case-insensitive and matches whole tokens: expected 1 result, got %d", len(ids))
+	}
+}
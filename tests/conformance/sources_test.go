@@ -0,0 +1,111 @@
+package conformance_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// latestHistory returns the newest propertiesWithHistory entry for prop.
+func latestHistory(t *testing.T, objectType, id, prop string) map[string]any {
+	t.Helper()
+	resp := doRequest(t, http.MethodGet, "/crm/v3/objects/"+objectType+"/"+id+"?propertiesWithHistory="+prop, nil)
+	mustStatus(t, resp, http.StatusOK)
+	pwh := assertIsObject(t, readJSON(t, resp), "propertiesWithHistory")
+	versions := assertIsArray(t, pwh, prop)
+	if len(versions) == 0 {
+		t.Fatalf("expected history for %s", prop)
+	}
+	return toObject(t, versions[0])
+}
+
+func TestChangeSourceAPI(t *testing.T) {
+	resetServer(t)
+
+	contact := createContact(t, map[string]string{"email": "src-api@example.com"})
+	id := assertIsString(t, contact, "id")
+	props := assertIsObject(t, contact, "properties")
+	assertStringField(t, props, "hs_object_source", "API")
+	sourceID := assertIsString(t, props, "hs_object_source_id")
+	if sourceID == "" {
+		t.Error("expected hs_object_source_id to identify the calling token")
+	}
+
+	h := latestHistory(t, "contacts", id, "email")
+	assertStringField(t, h, "sourceType", "API")
+	assertStringField(t, h, "sourceId", sourceID)
+}
+
+func TestChangeSourceCRMUI(t *testing.T) {
+	resetServer(t)
+
+	contact := createContact(t, map[string]string{"email": "src-ui@example.com"})
+	id := assertIsString(t, contact, "id")
+
+	b, _ := json.Marshal(map[string]any{"properties": map[string]string{"firstname": "Edited"}})
+	req, err := http.NewRequest(http.MethodPatch, serverURL+"/crm/v3/objects/contacts/"+id, bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Notspot-Source", "CRM_UI")
+	req.Header.Set("X-Notspot-User-Id", "1001")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PATCH: %v", err)
+	}
+	mustStatus(t, resp, http.StatusOK)
+	updated := readJSON(t, resp)
+	assertStringField(t, assertIsObject(t, updated, "properties"), "hs_updated_by_user_id", "1001")
+
+	h := latestHistory(t, "contacts", id, "firstname")
+	assertStringField(t, h, "sourceType", "CRM_UI")
+	if uid, _ := h["updatedByUserId"].(float64); uid != 1001 {
+		t.Errorf("expected updatedByUserId=1001, got %v", h["updatedByUserId"])
+	}
+
+	// The object's origin does not change on later edits.
+	resp = doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+id+"?properties=hs_object_source", nil)
+	mustStatus(t, resp, http.StatusOK)
+	assertStringField(t, assertIsObject(t, readJSON(t, resp), "properties"), "hs_object_source", "API")
+}
+
+func TestChangeSourceImport(t *testing.T) {
+	resetServer(t)
+
+	imp := startImport(t, "source-import", "0-1", "email,firstname\nsrc-import@example.com,Imp", []string{"email", "firstname"})
+	importID := assertIsString(t, imp, "id")
+
+	resp := doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/src-import@example.com?idProperty=email&properties=hs_object_source,hs_object_source_id", nil)
+	mustStatus(t, resp, http.StatusOK)
+	obj := readJSON(t, resp)
+	props := assertIsObject(t, obj, "properties")
+	assertStringField(t, props, "hs_object_source", "IMPORT")
+	assertStringField(t, props, "hs_object_source_id", importID)
+
+	h := latestHistory(t, "contacts", assertIsString(t, obj, "id"), "firstname")
+	assertStringField(t, h, "sourceType", "IMPORT")
+	assertStringField(t, h, "sourceId", importID)
+	assertStringField(t, h, "sourceLabel", "source-import")
+}
+
+func TestChangeSourceMerge(t *testing.T) {
+	resetServer(t)
+
+	primary := createContact(t, map[string]string{"email": "src-primary@example.com"})
+	secondary := createContact(t, map[string]string{"email": "src-secondary@example.com", "company": "Merged In"})
+	primaryID := assertIsString(t, primary, "id")
+	secondaryID := assertIsString(t, secondary, "id")
+
+	resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/merge", map[string]string{
+		"primaryObjectId": primaryID,
+		"objectIdToMerge": secondaryID,
+	})
+	mustStatus(t, resp, http.StatusOK)
+	_ = resp.Body.Close()
+
+	h := latestHistory(t, "contacts", primaryID, "company")
+	assertStringField(t, h, "sourceType", "MERGE")
+	assertStringField(t, h, "sourceId", secondaryID)
+}
@@ -0,0 +1,93 @@
+package conformance_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUniqueValues(t *testing.T) {
+	resetServer(t)
+
+	existing := createContact(t, map[string]string{"email": "taken@example.com"})
+	existingID := assertIsString(t, existing, "id")
+
+	assertConflict := func(t *testing.T, resp *http.Response) {
+		t.Helper()
+		mustStatus(t, resp, http.StatusConflict)
+		body := readJSON(t, resp)
+		assertHubSpotError(t, body, "CONFLICT")
+		assertStringField(t, body, "message", "Contact already exists. Existing ID: "+existingID)
+		ids := assertIsArray(t, assertIsObject(t, body, "context"), "id")
+		if len(ids) != 1 || ids[0] != existingID {
+			t.Errorf("expected context.id [%s], got %v", existingID, ids)
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts", map[string]any{
+			"properties": map[string]string{"email": "TAKEN@example.com"},
+		})
+		assertConflict(t, resp)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		other := createContact(t, map[string]string{"email": "other@example.com"})
+		resp := doRequest(t, http.MethodPatch, "/crm/v3/objects/contacts/"+assertIsString(t, other, "id"), map[string]any{
+			"properties": map[string]string{"email": "taken@example.com"},
+		})
+		assertConflict(t, resp)
+	})
+
+	t.Run("update keeping own value", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPatch, "/crm/v3/objects/contacts/"+existingID, map[string]any{
+			"properties": map[string]string{"email": "taken@example.com", "firstname": "Same"},
+		})
+		mustStatus(t, resp, http.StatusOK)
+		_ = resp.Body.Close()
+	})
+
+	t.Run("batch create reports per-row errors", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/batch/create", map[string]any{
+			"inputs": []map[string]any{
+				{"properties": map[string]string{"email": "fresh@example.com"}},
+				{"properties": map[string]string{"email": "taken@example.com"}},
+				{"properties": map[string]string{"email": "fresh@example.com"}},
+			},
+		})
+		body := readJSON(t, resp)
+		if results := assertIsArray(t, body, "results"); len(results) != 1 {
+			t.Fatalf("expected 1 created result, got %d", len(results))
+		}
+		errs := assertIsArray(t, body, "errors")
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %d", len(errs))
+		}
+		first := toObject(t, errs[0])
+		assertStringField(t, first, "category", "CONFLICT")
+		assertStringField(t, first, "message", "Contact already exists. Existing ID: "+existingID)
+	})
+
+	t.Run("archived objects do not conflict", func(t *testing.T) {
+		archived := createContact(t, map[string]string{"email": "recycled@example.com"})
+		resp := doRequest(t, http.MethodDelete, "/crm/v3/objects/contacts/"+assertIsString(t, archived, "id"), nil)
+		mustStatus(t, resp, http.StatusNoContent)
+		_ = resp.Body.Close()
+
+		createContact(t, map[string]string{"email": "recycled@example.com"})
+	})
+
+	t.Run("import reports per-row errors", func(t *testing.T) {
+		imp := startImport(t, "unique-import", "0-1", "email,firstname\nimported@example.com,New\ntaken@example.com,Dup", []string{"email", "firstname"})
+		importID := assertIsString(t, imp, "id")
+
+		resp := doRequest(t, http.MethodGet, "/crm/v3/imports/"+importID+"/errors", nil)
+		mustStatus(t, resp, http.StatusOK)
+		errs := assertIsArray(t, readJSON(t, resp), "results")
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 import error, got %d", len(errs))
+		}
+		e := toObject(t, errs[0])
+		assertStringField(t, e, "errorType", "DUPLICATE_UNIQUE_PROPERTY_VALUE")
+		assertStringField(t, e, "invalidValue", "taken@example.com")
+	})
+}
@@ -0,0 +1,158 @@
+package conformance_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPropertyValidation(t *testing.T) {
+	resetServer(t)
+
+	for _, prop := range []map[string]any{
+		{"name": "newsletter_opt_in", "label": "Newsletter", "type": "bool", "fieldType": "booleancheckbox", "groupName": "contactinformation"},
+		{"name": "interests", "label": "Interests", "type": "enumeration", "fieldType": "checkbox", "groupName": "contactinformation",
+			"options": []map[string]any{
+				{"label": "Golf", "value": "golf", "displayOrder": 0},
+				{"label": "Tennis", "value": "tennis", "displayOrder": 1},
+				{"label": "Sailing", "value": "sailing", "displayOrder": 2},
+			}},
+	} {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/properties/contacts", prop)
+		mustStatus(t, resp, http.StatusCreated)
+		_ = resp.Body.Close()
+	}
+
+	t.Run("normalizes on create", func(t *testing.T) {
+		c := createContact(t, map[string]string{
+			"email":             "checks@example.com",
+			"newsletter_opt_in": "TRUE",
+			"interests":         "golf; sailing",
+		})
+		props := assertIsObject(t, c, "properties")
+		assertStringField(t, props, "newsletter_opt_in", "true")
+		assertStringField(t, props, "interests", "golf;sailing")
+	})
+
+	t.Run("rejects invalid checkbox option", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts", map[string]any{
+			"properties": map[string]string{"interests": "golf;darts"},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		body := readJSON(t, resp)
+		assertHubSpotError(t, body, "VALIDATION_ERROR")
+		if msg := assertIsString(t, body, "message"); !strings.HasPrefix(msg, "Property values were not valid: ") {
+			t.Errorf("unexpected message %q", msg)
+		}
+		errs := assertIsArray(t, body, "errors")
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 error, got %d", len(errs))
+		}
+		assertStringField(t, toObject(t, errs[0]), "code", "INVALID_OPTION")
+	})
+
+	t.Run("rejects invalid bool", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts", map[string]any{
+			"properties": map[string]string{"newsletter_opt_in": "maybe"},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		errs := assertIsArray(t, readJSON(t, resp), "errors")
+		assertStringField(t, toObject(t, errs[0]), "code", "INVALID_BOOLEAN")
+	})
+
+	t.Run("validates update", func(t *testing.T) {
+		c := createContact(t, map[string]string{"email": "update-check@example.com"})
+		resp := doRequest(t, http.MethodPatch, "/crm/v3/objects/contacts/"+assertIsString(t, c, "id"), map[string]any{
+			"properties": map[string]string{"lifecyclestage": "not-a-stage"},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		errs := assertIsArray(t, readJSON(t, resp), "errors")
+		assertStringField(t, toObject(t, errs[0]), "code", "INVALID_OPTION")
+	})
+
+	t.Run("validates batch inputs", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/deals/batch/create", map[string]any{
+			"inputs": []map[string]any{
+				{"properties": map[string]string{"dealname": "Good", "closedate": "2024-06-30"}},
+				{"properties": map[string]string{"dealname": "Bad", "closedate": "2024-06-30T15:30:00Z"}},
+			},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		errs := assertIsArray(t, readJSON(t, resp), "errors")
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 error, got %d", len(errs))
+		}
+		assertStringField(t, toObject(t, errs[0]), "code", "INVALID_DATE")
+	})
+
+	t.Run("validates upsert", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/batch/upsert", map[string]any{
+			"inputs": []map[string]any{
+				{"id": "upsert-check@example.com", "properties": map[string]string{"email": "upsert-check@example.com", "newsletter_opt_in": "nope"}},
+			},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		errs := assertIsArray(t, readJSON(t, resp), "errors")
+		assertStringField(t, toObject(t, errs[0]), "code", "INVALID_BOOLEAN")
+	})
+
+	t.Run("rejects unknown properties", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts", map[string]any{
+			"properties": map[string]string{"email": "unknown@example.com", "favourite_colour": "blue"},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		body := readJSON(t, resp)
+		assertHubSpotError(t, body, "VALIDATION_ERROR")
+		errs := assertIsArray(t, body, "errors")
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 error, got %d", len(errs))
+		}
+		e := toObject(t, errs[0])
+		assertStringField(t, e, "code", "PROPERTY_DOESNT_EXIST")
+		names := assertIsArray(t, assertIsObject(t, e, "context"), "propertyName")
+		if len(names) != 1 || names[0] != "favourite_colour" {
+			t.Errorf("expected context.propertyName [favourite_colour], got %v", names)
+		}
+	})
+
+	t.Run("rejects unknown properties in batches", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts/batch/upsert", map[string]any{
+			"inputs": []map[string]any{
+				{"id": "unknown-upsert@example.com", "idProperty": "email", "properties": map[string]string{"shoe_size": "9"}},
+			},
+		})
+		mustStatus(t, resp, http.StatusBadRequest)
+		errs := assertIsArray(t, readJSON(t, resp), "errors")
+		assertStringField(t, toObject(t, errs[0]), "code", "PROPERTY_DOESNT_EXIST")
+	})
+
+	t.Run("import reports unknown columns per row", func(t *testing.T) {
+		imp := startImport(t, "unknown-import", "0-1", "email,shoe_size\nrow1@example.com,9\nrow2@example.com,10", []string{"email", "shoe_size"})
+		importID := assertIsString(t, imp, "id")
+
+		resp := doRequest(t, http.MethodGet, "/crm/v3/imports/"+importID+"/errors", nil)
+		mustStatus(t, resp, http.StatusOK)
+		errs := assertIsArray(t, readJSON(t, resp), "results")
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 import errors, got %d", len(errs))
+		}
+		assertStringField(t, toObject(t, errs[0]), "errorType", "PROPERTY_DOESNT_EXIST")
+	})
+
+	t.Run("accepts JSON-typed values", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/crm/v3/objects/contacts", map[string]any{
+			"properties": map[string]any{"email": "typed@example.com", "newsletter_opt_in": true, "annualrevenue": 25000},
+		})
+		mustStatus(t, resp, http.StatusCreated)
+		c := readJSON(t, resp)
+		props := assertIsObject(t, c, "properties")
+		assertStringField(t, props, "newsletter_opt_in", "true")
+		assertStringField(t, props, "annualrevenue", "25000")
+
+		resp = doRequest(t, http.MethodPatch, "/crm/v3/objects/contacts/"+assertIsString(t, c, "id"), map[string]any{
+			"properties": map[string]any{"annualrevenue": nil},
+		})
+		mustStatus(t, resp, http.StatusOK)
+		assertStringField(t, assertIsObject(t, readJSON(t, resp), "properties"), "annualrevenue", "")
+	})
+}
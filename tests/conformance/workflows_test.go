@@ -320,7 +320,7 @@ func TestWorkflow_BulkLoadPaginateSearch(t *testing.T) {
 	}
 
 	// Search with a filter to find a subset (contacts with firstname starting with "Bulk0").
-	searchResult := searchContacts(t, filterBody("firstname", "CONTAINS_TOKEN", "Bulk0"))
+	searchResult := searchContacts(t, filterBody("firstname", "CONTAINS_TOKEN", "Bulk0*"))
 	searchResults := assertIsArray(t, searchResult, "results")
 	if len(searchResults) < 1 {
 		t.Error("expected at least 1 result searching for 'Bulk0'")
@@ -427,11 +427,11 @@ func TestWorkflow_MergeVerification(t *testing.T) {
 	// The secondary's lastname should have been merged into the primary.
 	assertStringField(t, survivorProps, "lastname", "SecondaryLast")
 
-	// GET the merged contact — expect archived.
+	// GET the merged contact — expect the surviving record.
 	resp = doRequest(t, http.MethodGet, "/crm/v3/objects/contacts/"+secondaryID, nil)
 	mustStatus(t, resp, http.StatusOK)
 	mergedObj := readJSON(t, resp)
-	assertBoolField(t, mergedObj, "archived", true)
+	assertStringField(t, mergedObj, "id", primaryID)
 
 	// Search — verify merged contact doesn't appear in search results.
 	ids := searchContactIDs(t, filterBody("email", "EQ", "secondary@merge.com"))
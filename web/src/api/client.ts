@@ -26,6 +26,8 @@ export async function apiFetch<T>(path: string, opts?: RequestInit): Promise<T>
       ...opts,
       headers: {
         'Content-Type': 'application/json',
+        // Attribute property changes made from the UI to CRM_UI.
+        'X-Notspot-Source': 'CRM_UI',
         ...opts?.headers,
       },
     });